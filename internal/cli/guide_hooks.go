@@ -37,16 +37,16 @@ Guides are cached for future use. Use --refresh to regenerate.
 Use -i for interactive mode where AI asks about your context.
 Use --format html to generate HTML and open in browser.`,
 	Example: `  # Get usage guide for a hook (uses cache if available)
-  jd guide hooks PreToolUse-Bash-0
+  jd guide hooks PreToolUse-Bash-3f9a2c1d
 
   # Force regenerate the guide
-  jd guide hooks PreToolUse-Bash-0 --refresh
+  jd guide hooks PreToolUse-Bash-3f9a2c1d --refresh
 
   # Generate HTML and open in browser
-  jd guide hooks PreToolUse-Bash-0 --format html
+  jd guide hooks PreToolUse-Bash-3f9a2c1d --format html
 
   # Interactive mode (not cached)
-  jd guide hooks PreToolUse-Bash-0 -i`,
+  jd guide hooks PreToolUse-Bash-3f9a2c1d -i`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runGuideHooks,
 	ValidArgsFunction: hookNameCompletion,
@@ -84,6 +84,8 @@ func runGuideHooks(cmd *cobra.Command, args []string) error {
 		}
 		return fmt.Errorf("failed to get hook: %w", err)
 	}
+	// Cache guides under the stable name, however the hook was addressed
+	hookName = h.Name
 
 	content, err := json.MarshalIndent(h, "", "  ")
 	if err != nil {
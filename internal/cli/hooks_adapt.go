@@ -34,10 +34,10 @@ This command:
 Default scope is local if a .claude directory exists in the current working directory, otherwise global.
 Use --global or --local to override.`,
 	Example: `  # Adapt a global hook
-  jd hooks adapt PreToolUse-Bash-0
+  jd hooks adapt PreToolUse-Bash-3f9a2c1d
 
   # Adapt a local hook
-  jd hooks adapt PreToolUse-Bash-0 --local`,
+  jd hooks adapt PreToolUse-Bash-3f9a2c1d --local`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runHooksAdapt,
 	ValidArgsFunction: hookNameCompletion,
@@ -62,6 +62,12 @@ func runHooksAdapt(cmd *cobra.Command, args []string) error {
 	settingsPath := GetSettingsPathByScope(scope)
 	store := hook.NewStore(settingsPath)
 
+	// Move history to stable IDs before opening it, so the write below
+	// doesn't move it out from under the history manager
+	if err := store.MigrateIDs(); err != nil {
+		return fmt.Errorf("failed to migrate hook history: %w", err)
+	}
+
 	// Get hook to verify it exists
 	h, err := store.Get(hookName)
 	if err != nil {
@@ -79,7 +85,7 @@ func runHooksAdapt(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create history manager and backup current version
-	historyMgr := hook.NewHistoryManager(claudeDir, hookName)
+	historyMgr := hook.NewHistoryManager(claudeDir, h.HistoryKey())
 
 	version, err := historyMgr.SaveVersion(h)
 	if err != nil {
@@ -26,9 +26,9 @@ Default scope is local if a .claude directory exists in the current working dire
 Use --global or --local to override.
 
 Examples:
-  jd hooks delete PreToolUse-Bash-0
-  jd hooks delete PreToolUse-Bash-0 -f
-  jd hooks delete --local PreToolUse-Bash-0`,
+  jd hooks delete PreToolUse-Bash-3f9a2c1d
+  jd hooks delete PreToolUse-Bash-3f9a2c1d -f
+  jd hooks delete --local PreToolUse-Bash-3f9a2c1d`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runHooksDelete,
 	ValidArgsFunction: hookNameCompletion,
@@ -67,6 +67,10 @@ func runHooksDelete(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Event:   %s\n", h.EventType)
 		fmt.Printf("  Matcher: %s\n", h.Matcher)
 		fmt.Printf("  Commands: %s\n", strings.Join(h.Commands, ", "))
+		if owner := hookPackage(scope, h); owner != "" {
+			fmt.Printf("\nThis hook was registered by package %s and returns when it is updated.\n", owner)
+			fmt.Printf("Use 'jd pkg uninstall %s' to remove the package instead.\n", owner)
+		}
 		fmt.Print("\nAre you sure you want to delete this hook? (y/N): ")
 
 		reader := bufio.NewReader(os.Stdin)
@@ -85,3 +89,14 @@ func runHooksDelete(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✓ Deleted hook: %s\n", name)
 	return nil
 }
+
+// hookPackage returns the installed package that registered h in scope, or
+// an empty string if there is none or it cannot be told.
+func hookPackage(scope PathScope, h *hook.Hook) string {
+	manager, err := newPkgManager(scope)
+	if err != nil {
+		return ""
+	}
+	owner, _ := manager.HookOwner(h.Commands)
+	return owner
+}
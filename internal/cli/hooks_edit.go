@@ -13,6 +13,7 @@ import (
 var (
 	hooksEditMatcher string
 	hooksEditCommand string
+	hooksEditTimeout int
 	hooksEditGlobal  bool
 	hooksEditLocal   bool
 )
@@ -24,14 +25,18 @@ var hooksEditCmd = &cobra.Command{
 	Long: `Edit an existing hook in ~/.claude/settings.json (global) or .claude/settings.json (local).
 
 If no flags are provided, runs in interactive mode showing current values.
+A new command replaces all existing commands and keeps the timeout and
+other settings of the first one. --timeout sets the timeout in seconds of
+every command; 0 removes it.
 Default scope is local if a .claude directory exists in the current working directory, otherwise global.
 Use --global or --local to override.
 
 Examples:
-  jd hooks edit PreToolUse-Bash-0
-  jd hooks edit PreToolUse-Bash-0 -m "Bash|Write"
-  jd hooks edit PreToolUse-Bash-0 -c "new-command.sh"
-  jd hooks edit --local PreToolUse-Bash-0`,
+  jd hooks edit PreToolUse-Bash-3f9a2c1d
+  jd hooks edit PreToolUse-Bash-3f9a2c1d -m "Bash|Write"
+  jd hooks edit PreToolUse-Bash-3f9a2c1d -c "new-command.sh"
+  jd hooks edit PreToolUse-Bash-3f9a2c1d --timeout 30
+  jd hooks edit --local PreToolUse-Bash-3f9a2c1d`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runHooksEdit,
 	ValidArgsFunction: hookNameCompletion,
@@ -41,8 +46,10 @@ func init() {
 	hooksCmd.AddCommand(hooksEditCmd)
 	hooksEditCmd.Flags().StringVarP(&hooksEditMatcher, "matcher", "m", "", "New matcher pattern")
 	hooksEditCmd.Flags().StringVarP(&hooksEditCommand, "command", "c", "", "New command (replaces all existing commands)")
+	hooksEditCmd.Flags().IntVarP(&hooksEditTimeout, "timeout", "t", 0, "Command timeout in seconds (0 removes it)")
 	hooksEditCmd.Flags().BoolVarP(&hooksEditGlobal, "global", "g", false, "Edit from global ~/.claude/settings.json")
 	hooksEditCmd.Flags().BoolVarP(&hooksEditLocal, "local", "l", false, "Edit from local .claude/settings.json")
+	_ = hooksEditCmd.RegisterFlagCompletionFunc("matcher", hookMatcherCompletion)
 }
 
 func runHooksEdit(cmd *cobra.Command, args []string) error {
@@ -67,49 +74,81 @@ func runHooksEdit(cmd *cobra.Command, args []string) error {
 	reader := bufio.NewReader(os.Stdin)
 	newMatcher := hooksEditMatcher
 	newCommand := hooksEditCommand
+	newTimeout := -1
+	if cmd.Flags().Changed("timeout") {
+		if hooksEditTimeout < 0 {
+			return fmt.Errorf("timeout must not be negative")
+		}
+		newTimeout = hooksEditTimeout
+	}
 
 	// Interactive mode if no flags provided
-	if newMatcher == "" && newCommand == "" {
+	if newMatcher == "" && newCommand == "" && newTimeout < 0 {
 		fmt.Printf("Editing hook: %s\n\n", name)
 
-		// Matcher
-		fmt.Printf("Current matcher: %s\n", h.Matcher)
-		fmt.Print("New matcher (press Enter to keep current): ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-		if input != "" {
-			newMatcher = input
-		} else {
-			newMatcher = h.Matcher
+		// Matcher, unless the event ignores matchers
+		newMatcher = h.Matcher
+		if event, ok := hook.LookupEvent(h.EventType); !ok || event.Matcher != hook.MatcherNone {
+			fmt.Printf("Current matcher: %s\n", h.Matcher)
+			if ok {
+				fmt.Printf("Matches: %s\n", event.MatcherHint())
+			}
+			fmt.Print("New matcher (press Enter to keep current): ")
+			input, _ := reader.ReadString('\n')
+			if input = strings.TrimSpace(input); input != "" {
+				newMatcher = input
+			}
 		}
 
 		// Command
 		fmt.Printf("\nCurrent commands:\n")
-		for i, cmd := range h.Commands {
-			fmt.Printf("  %d. %s\n", i+1, cmd)
+		for i, entry := range h.Hooks {
+			fmt.Printf("  %d. %s\n", i+1, formatHookEntry(entry))
 		}
 		fmt.Print("New command (press Enter to keep current): ")
-		input, _ = reader.ReadString('\n')
+		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 		if input != "" {
 			newCommand = input
 		}
+
+		// Timeout
+		fmt.Print("Timeout in seconds (press Enter to keep current, 0 to remove): ")
+		input, _ = reader.ReadString('\n')
+		if input = strings.TrimSpace(input); input != "" {
+			if _, err := fmt.Sscanf(input, "%d", &newTimeout); err != nil || newTimeout < 0 {
+				return fmt.Errorf("invalid timeout: %s", input)
+			}
+		}
 	}
 
 	// Apply defaults if still empty
 	if newMatcher == "" {
 		newMatcher = h.Matcher
 	}
+	if err := hook.ValidateMatcher(h.EventType, newMatcher); err != nil {
+		return err
+	}
+	warnUnknownTools(h.EventType, newMatcher)
 
-	var commands []string
+	entries := append([]hook.HookCommand(nil), h.Hooks...)
 	if newCommand != "" {
-		commands = []string{newCommand}
-	} else {
-		commands = h.Commands
+		entry := hook.HookCommand{Type: "command", Command: newCommand}
+		if len(entries) > 0 && entries[0].Type == "command" {
+			// Keep the timeout and other settings of the replaced command
+			entry = entries[0]
+			entry.Command = newCommand
+		}
+		entries = []hook.HookCommand{entry}
+	}
+	if newTimeout >= 0 {
+		for i := range entries {
+			entries[i].Timeout = newTimeout
+		}
 	}
 
 	// Update the hook
-	updated, err := store.Update(name, newMatcher, commands)
+	updated, err := store.Update(name, newMatcher, entries)
 	if err != nil {
 		return fmt.Errorf("failed to update hook: %w", err)
 	}
@@ -117,6 +156,9 @@ func runHooksEdit(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\n✓ Updated hook: %s\n", updated.Name)
 	fmt.Printf("  Matcher: %s\n", updated.Matcher)
 	fmt.Printf("  Commands: %s\n", strings.Join(updated.Commands, ", "))
+	if timeout := hookTimeout(updated.Hooks); timeout != "" {
+		fmt.Printf("  Timeout: %s\n", timeout)
+	}
 
 	return nil
 }
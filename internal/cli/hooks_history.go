@@ -24,10 +24,10 @@ var hooksHistoryCmd = &cobra.Command{
 Each time a hook is adapted, a new version is saved.
 Use 'jd hooks revert' to restore a previous version.`,
 	Example: `  # Show history of a global hook
-  jd hooks history PreToolUse-Bash-0
+  jd hooks history PreToolUse-Bash-3f9a2c1d
 
   # Show history of a local hook
-  jd hooks history PreToolUse-Bash-0 --local`,
+  jd hooks history PreToolUse-Bash-3f9a2c1d --local`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runHooksHistory,
 	ValidArgsFunction: hookNameCompletion,
@@ -53,7 +53,7 @@ func runHooksHistory(cmd *cobra.Command, args []string) error {
 	store := hook.NewStore(settingsPath)
 
 	// Verify hook exists
-	_, err = store.Get(hookName)
+	h, err := store.Get(hookName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("hook not found in %s: %s", ScopeDescription(scope), hookName)
@@ -69,7 +69,7 @@ func runHooksHistory(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create history manager
-	historyMgr := hook.NewHistoryManager(claudeDir, hookName)
+	historyMgr := hook.NewHistoryManager(claudeDir, h.HistoryKey())
 
 	versions, err := historyMgr.ListVersions()
 	if err != nil {
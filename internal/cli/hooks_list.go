@@ -9,19 +9,31 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var hooksListJSON bool
+var (
+	hooksListJSON  bool
+	hooksListEvent string
+)
 
 var hooksListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"l", "ls"},
 	Short:   "List all hooks",
-	Long:    `List all hooks from ~/.claude/settings.json and .claude/settings.json.`,
-	RunE:    runHooksList,
+	Long: `List all hooks from ~/.claude/settings.json and .claude/settings.json.
+
+Use --event to list only the hooks of one event type (full name or alias).
+
+Examples:
+  jd hooks list
+  jd hooks list --event start
+  jd hooks list --json`,
+	RunE: runHooksList,
 }
 
 func init() {
 	hooksCmd.AddCommand(hooksListCmd)
 	hooksListCmd.Flags().BoolVar(&hooksListJSON, "json", false, "Output in JSON format")
+	hooksListCmd.Flags().StringVarP(&hooksListEvent, "event", "e", "", "Only list hooks of this event type")
+	_ = hooksListCmd.RegisterFlagCompletionFunc("event", hookEventCompletion)
 }
 
 // hooksListOutput represents JSON output for hooks list with scope
@@ -47,6 +59,15 @@ func runHooksList(cmd *cobra.Command, _ []string) error {
 		localHooks, _ = localStore.List()
 	}
 
+	if hooksListEvent != "" {
+		event, err := hook.ParseEventType(hooksListEvent)
+		if err != nil {
+			return err
+		}
+		globalHooks = filterHooksByEvent(globalHooks, event)
+		localHooks = filterHooksByEvent(localHooks, event)
+	}
+
 	if hooksListJSON {
 		output := hooksListOutput{
 			Global: globalHooks,
@@ -78,6 +99,17 @@ func runHooksList(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// filterHooksByEvent returns the hooks of one event type.
+func filterHooksByEvent(hooks []*hook.Hook, event hook.EventType) []*hook.Hook {
+	var out []*hook.Hook
+	for _, h := range hooks {
+		if h.EventType == event {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
 func printHooksJSON(hooks []*hook.Hook) error {
 	output, err := json.MarshalIndent(hooks, "", "  ")
 	if err != nil {
@@ -109,8 +141,8 @@ func printHooksTable(hooks []*hook.Hook) {
 	if nameWidth > 35 {
 		nameWidth = 35
 	}
-	if eventWidth > 15 {
-		eventWidth = 15
+	if eventWidth > 16 {
+		eventWidth = 16
 	}
 	if matcherWidth > 20 {
 		matcherWidth = 20
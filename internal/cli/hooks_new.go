@@ -15,6 +15,7 @@ var (
 	hooksNewEventType    string
 	hooksNewMatcher      string
 	hooksNewCommand      string
+	hooksNewTimeout      int
 	hooksNewCreateScript bool
 	hooksNewGlobal       bool
 	hooksNewLocal        bool
@@ -35,19 +36,32 @@ Event types (with aliases):
   - PreToolUse (pre): Runs before a tool is executed
   - PostToolUse (post): Runs after a tool is executed
   - Notification (notify): Runs on notifications
+  - UserPromptSubmit (prompt): Runs when the user submits a prompt
   - Stop: Runs when Claude stops
   - SubagentStop (sub): Runs when a subagent stops
+  - PreCompact (compact): Runs before the conversation is compacted
+  - SessionStart (start): Runs when a session starts or resumes
+  - SessionEnd (end): Runs when a session ends
 
-Matcher patterns:
-  - Single tool: "Bash", "Write", "Edit"
-  - Multiple tools: "Bash|Write|Edit" (regex OR)
-  - All tools: "*"
+Matcher patterns depend on the event:
+  - PreToolUse, PostToolUse: tool names
+      Single tool: "Bash", "Write", "Edit"
+      Multiple tools: "Bash|Write|Edit" (regex OR)
+      All tools: "*"
+  - SessionStart: startup, resume, clear, compact (e.g. "startup|resume")
+  - PreCompact: manual, auto
+  - Notification: permission_prompt, idle_prompt, auth_success, elicitation_dialog
+  - UserPromptSubmit, Stop, SubagentStop, SessionEnd: no matcher
+  Leave the matcher empty to match everything.
 
 Examples:
   jd hooks new
   jd hooks new -e pre -m "Bash" -c "echo 'Running bash'"
   jd hooks new -e post -m "Bash|Write" -c "~/.claude/hooks/log.sh"
   jd hooks new -e post -m "Bash" --script
+  jd hooks new -e pre -m "Bash" -c "~/.claude/hooks/guard.sh" --timeout 30
+  jd hooks new -e start -m "startup|resume" -c "~/.claude/hooks/context.sh"
+  jd hooks new -e prompt -c "~/.claude/hooks/check-prompt.sh"
   jd hooks new --local -e pre -m "Bash" -c "echo 'local hook'"`,
 	RunE:              runHooksNew,
 	ValidArgsFunction: hooksNewCompletion,
@@ -55,37 +69,44 @@ Examples:
 
 func init() {
 	hooksCmd.AddCommand(hooksNewCmd)
-	hooksNewCmd.Flags().StringVarP(&hooksNewEventType, "event", "e", "", "Event type: pre, post, notify, stop, sub")
-	hooksNewCmd.Flags().StringVarP(&hooksNewMatcher, "matcher", "m", "", "Tool matcher pattern (e.g., Bash, \"Bash|Write\", *)")
+	hooksNewCmd.Flags().StringVarP(&hooksNewEventType, "event", "e", "", "Event type: pre, post, notify, prompt, stop, sub, compact, start, end")
+	hooksNewCmd.Flags().StringVarP(&hooksNewMatcher, "matcher", "m", "", "Matcher pattern (e.g., Bash, \"Bash|Write\", *, startup)")
 	hooksNewCmd.Flags().StringVarP(&hooksNewCommand, "command", "c", "", "Command to execute")
+	hooksNewCmd.Flags().IntVarP(&hooksNewTimeout, "timeout", "t", 0, "Command timeout in seconds (default: Claude Code's)")
 	hooksNewCmd.Flags().BoolVar(&hooksNewCreateScript, "script", false, "Create a script file in ~/.claude/hooks/")
 	hooksNewCmd.Flags().BoolVarP(&hooksNewGlobal, "global", "g", false, "Create in global ~/.claude/settings.json")
 	hooksNewCmd.Flags().BoolVarP(&hooksNewLocal, "local", "l", false, "Create in local .claude/settings.json")
 
 	// Register completion for --event flag
-	_ = hooksNewCmd.RegisterFlagCompletionFunc("event", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-		return []string{
-			"PreToolUse\tRuns before a tool is executed",
-			"PostToolUse\tRuns after a tool is executed",
-			"Notification\tRuns on notifications",
-			"Stop\tRuns when Claude stops",
-			"SubagentStop\tRuns when a subagent stops",
-		}, cobra.ShellCompDirectiveNoFileComp
-	})
+	_ = hooksNewCmd.RegisterFlagCompletionFunc("event", hookEventCompletion)
 
 	// Register completion for --matcher flag
-	_ = hooksNewCmd.RegisterFlagCompletionFunc("matcher", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-		return []string{
-			"Bash\tExecute shell commands",
-			"Read\tRead files",
-			"Write\tWrite files",
-			"Edit\tEdit files",
-			"Glob\tFind files by pattern",
-			"Grep\tSearch file contents",
-			"Task\tRun subagent tasks",
-			"*\tAll tools",
-		}, cobra.ShellCompDirectiveNoFileComp
-	})
+	_ = hooksNewCmd.RegisterFlagCompletionFunc("matcher", hookMatcherCompletion)
+}
+
+// hookEventCompletion completes event type names.
+func hookEventCompletion(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return hook.EventCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// hookMatcherCompletion completes matchers for the event given with
+// --event or named by the hook argument, or tool names when there is neither.
+func hookMatcherCompletion(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	event, _ := cmd.Flags().GetString("event")
+	if event == "" && len(args) > 0 {
+		// Hook names start with their event, e.g. SessionStart-startup-3f9a2c1d
+		event, _, _ = strings.Cut(args[0], "-")
+	}
+
+	info, _ := hook.LookupEvent(hook.PreToolUse)
+	if event != "" {
+		et, err := hook.ParseEventType(event)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		info, _ = hook.LookupEvent(et)
+	}
+	return info.MatcherCompletions(), cobra.ShellCompDirectiveNoFileComp
 }
 
 func hooksNewCompletion(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -100,29 +121,32 @@ func runHooksNew(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	if hooksNewTimeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	// Get event type
 	eventTypeStr := hooksNewEventType
 	if eventTypeStr == "" {
 		fmt.Println("Select event type:")
-		eventTypes := hook.AllEventTypes()
-		aliases := []string{"pre", "post", "notify", "", "sub"}
-		for i, et := range eventTypes {
-			if aliases[i] != "" {
-				fmt.Printf("  %d. %s (%s)\n", i+1, et, aliases[i])
+		events := hook.AllEvents()
+		for i, e := range events {
+			if len(e.Aliases) > 0 {
+				fmt.Printf("  %d. %s (%s)\n", i+1, e.Type, e.Aliases[0])
 			} else {
-				fmt.Printf("  %d. %s\n", i+1, et)
+				fmt.Printf("  %d. %s\n", i+1, e.Type)
 			}
 		}
-		fmt.Print("Enter number (1-5) or alias: ")
+		fmt.Printf("Enter number (1-%d) or alias: ", len(events))
 		input, _ := reader.ReadString('\n')
 		eventTypeStr = strings.TrimSpace(input)
 
 		// Check if it's a number
 		var idx int
-		if _, err := fmt.Sscanf(eventTypeStr, "%d", &idx); err == nil && idx >= 1 && idx <= 5 {
-			eventTypeStr = string(eventTypes[idx-1])
+		if _, err := fmt.Sscanf(eventTypeStr, "%d", &idx); err == nil && idx >= 1 && idx <= len(events) {
+			eventTypeStr = string(events[idx-1].Type)
 		}
 	}
 
@@ -132,18 +156,31 @@ func runHooksNew(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	event, _ := hook.LookupEvent(validEventType)
+
 	// Get matcher
 	matcher := hooksNewMatcher
-	if matcher == "" {
+	switch {
+	case matcher != "":
+	case event.Matcher == hook.MatcherTool:
 		fmt.Println("\nEnter matcher pattern:")
 		fmt.Println("  Examples: Bash, \"Bash|Write\", * (all tools)")
 		fmt.Print("Matcher: ")
 		matcher, _ = reader.ReadString('\n')
 		matcher = strings.TrimSpace(matcher)
+		if matcher == "" {
+			return fmt.Errorf("matcher is required (use * for all tools)")
+		}
+	case event.Matcher == hook.MatcherValue && hooksNewCommand == "":
+		fmt.Printf("\nEnter matcher (%s):\n", event.MatcherHint())
+		fmt.Print("Matcher: ")
+		matcher, _ = reader.ReadString('\n')
+		matcher = strings.TrimSpace(matcher)
 	}
-	if matcher == "" {
-		return fmt.Errorf("matcher is required (use * for all tools)")
+	if err := hook.ValidateMatcher(validEventType, matcher); err != nil {
+		return err
 	}
+	warnUnknownTools(validEventType, matcher)
 
 	// Get command
 	command := hooksNewCommand
@@ -184,14 +221,9 @@ func runHooksNew(cmd *cobra.Command, _ []string) error {
 # Hook: %s
 # Matcher: %s
 # Created by jd hooks new
-
-# Available environment variables:
-# $TOOL_NAME - Name of the tool being called
-# $TOOL_INPUT - JSON input to the tool
-# $TOOL_OUTPUT - JSON output from the tool (PostToolUse only)
-
-echo "Hook triggered: %s for $TOOL_NAME"
-`, validEventType, matcher, validEventType)
+%s
+echo "Hook triggered: %s"
+`, validEventType, matcher, scriptInputComment(event), validEventType)
 
 		scriptPath, err := hook.CreateScript(scriptName, template)
 		if err != nil {
@@ -204,7 +236,8 @@ echo "Hook triggered: %s for $TOOL_NAME"
 
 	// Add hook to settings.json
 	store := hook.NewStore(GetSettingsPathByScope(scope))
-	newHook, err := store.Add(validEventType, matcher, []string{command})
+	entry := hook.HookCommand{Type: "command", Command: command, Timeout: hooksNewTimeout}
+	newHook, err := store.AddHooks(validEventType, matcher, []hook.HookCommand{entry})
 	if err != nil {
 		return fmt.Errorf("failed to add hook: %w", err)
 	}
@@ -213,13 +246,31 @@ echo "Hook triggered: %s for $TOOL_NAME"
 	fmt.Printf("  Event: %s\n", newHook.EventType)
 	fmt.Printf("  Matcher: %s\n", newHook.Matcher)
 	fmt.Printf("  Command: %s\n", strings.Join(newHook.Commands, ", "))
+	if hooksNewTimeout > 0 {
+		fmt.Printf("  Timeout: %ds\n", hooksNewTimeout)
+	}
 
 	return nil
 }
 
+// scriptInputComment documents the input available to a hook script.
+func scriptInputComment(event hook.EventInfo) string {
+	if event.Matcher == hook.MatcherTool {
+		return `
+# Available environment variables:
+# $TOOL_NAME - Name of the tool being called
+# $TOOL_INPUT - JSON input to the tool
+# $TOOL_OUTPUT - JSON output from the tool (PostToolUse only)
+`
+	}
+	return `
+# The event is passed as JSON on stdin (session_id, cwd, hook_event_name, ...)
+`
+}
+
 func sanitizeMatcherForFilename(matcher string) string {
 	result := matcher
-	if result == "*" {
+	if result == "*" || result == "" {
 		result = "all"
 	}
 	result = strings.ReplaceAll(result, "|", "-")
@@ -237,3 +288,11 @@ func sanitizeMatcherForFilename(matcher string) string {
 	}
 	return filepath.Clean(cleaned)
 }
+
+// warnUnknownTools warns about matcher names that are not known tools.
+// They are not rejected, as Claude Code may have tools jd doesn't know.
+func warnUnknownTools(et hook.EventType, matcher string) {
+	if unknown := hook.UnknownTools(et, matcher); len(unknown) > 0 {
+		fmt.Printf("⚠️  Unknown tool name(s) in matcher: %s\n", strings.Join(unknown, ", "))
+	}
+}
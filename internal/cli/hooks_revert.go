@@ -23,13 +23,13 @@ var hooksRevertCmd = &cobra.Command{
 If no version is specified, shows available versions.
 Version can be a number (e.g., 1, 2) or 'latest'.`,
 	Example: `  # Show available versions
-  jd hooks revert PreToolUse-Bash-0
+  jd hooks revert PreToolUse-Bash-3f9a2c1d
 
   # Revert to version 1
-  jd hooks revert PreToolUse-Bash-0 1
+  jd hooks revert PreToolUse-Bash-3f9a2c1d 1
 
   # Revert to the latest backed up version
-  jd hooks revert PreToolUse-Bash-0 latest`,
+  jd hooks revert PreToolUse-Bash-3f9a2c1d latest`,
 	Args:              cobra.RangeArgs(1, 2),
 	RunE:              runHooksRevert,
 	ValidArgsFunction: hookNameCompletion,
@@ -54,8 +54,14 @@ func runHooksRevert(cmd *cobra.Command, args []string) error {
 	settingsPath := GetSettingsPathByScope(scope)
 	store := hook.NewStore(settingsPath)
 
+	// Move history to stable IDs before opening it, so the write below
+	// doesn't move it out from under the history manager
+	if err := store.MigrateIDs(); err != nil {
+		return fmt.Errorf("failed to migrate hook history: %w", err)
+	}
+
 	// Verify hook exists
-	_, err = store.Get(hookName)
+	h, err := store.Get(hookName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("hook not found in %s: %s", ScopeDescription(scope), hookName)
@@ -71,7 +77,7 @@ func runHooksRevert(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create history manager
-	historyMgr := hook.NewHistoryManager(claudeDir, hookName)
+	historyMgr := hook.NewHistoryManager(claudeDir, h.HistoryKey())
 
 	// If no version specified, show available versions
 	if len(args) < 2 {
@@ -129,7 +135,7 @@ func runHooksRevert(cmd *cobra.Command, args []string) error {
 	}
 
 	// Update the hook with the reverted configuration
-	_, err = store.Update(hookName, snapshot.Matcher, snapshot.Commands)
+	_, err = store.Update(hookName, snapshot.Matcher, snapshot.Entries())
 	if err != nil {
 		return fmt.Errorf("failed to update hook: %w", err)
 	}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/itda-skills/jindo/internal/hook"
 	"github.com/spf13/cobra"
@@ -67,30 +69,62 @@ func runHooksShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Event:     %s\n", h.EventType)
 	fmt.Printf("Matcher:   %s\n", h.Matcher)
 	fmt.Printf("Commands:\n")
-	for i, cmd := range h.Commands {
-		fmt.Printf("  %d. %s\n", i+1, cmd)
+	for i, entry := range h.Hooks {
+		fmt.Printf("  %d. %s\n", i+1, formatHookEntry(entry))
 	}
 
 	// Show event type description
 	fmt.Printf("\nEvent Description:\n")
+	if event, ok := hook.LookupEvent(h.EventType); ok {
+		fmt.Printf("  %s.\n", event.Description)
+		if event.Matcher != hook.MatcherNone {
+			fmt.Printf("  Matches: %s\n", event.MatcherHint())
+		}
+	} else {
+		fmt.Println("  Event not known to this version of jd.")
+	}
 	switch h.EventType {
 	case hook.PreToolUse:
-		fmt.Println("  Runs before a tool is executed.")
 		fmt.Println("  Available vars: $TOOL_NAME, $TOOL_INPUT")
 	case hook.PostToolUse:
-		fmt.Println("  Runs after a tool is executed.")
 		fmt.Println("  Available vars: $TOOL_NAME, $TOOL_INPUT, $TOOL_OUTPUT")
-	case hook.Notification:
-		fmt.Println("  Runs on notifications.")
-	case hook.Stop:
-		fmt.Println("  Runs when Claude stops.")
-	case hook.SubagentStop:
-		fmt.Println("  Runs when a subagent stops.")
 	}
 
 	return nil
 }
 
+// formatHookEntry describes a hooks[] entry: its command, or its type for
+// other kinds of hooks, followed by its timeout and any other keys.
+func formatHookEntry(entry hook.HookCommand) string {
+	text := entry.Command
+	if entry.Type != "command" {
+		text = strings.TrimSpace(fmt.Sprintf("[%s] %s", entry.Type, entry.Command))
+	}
+	if entry.Timeout > 0 {
+		text += fmt.Sprintf(" (timeout %ds)", entry.Timeout)
+	}
+	if len(entry.Extra) > 0 {
+		extra, _ := json.Marshal(entry.Extra)
+		text += " " + string(extra)
+	}
+	return text
+}
+
+// hookTimeout summarizes the timeouts of hook entries, e.g. "30s", or ""
+// if none is set.
+func hookTimeout(entries []hook.HookCommand) string {
+	var timeouts []string
+	for _, e := range entries {
+		if e.Timeout > 0 {
+			t := fmt.Sprintf("%ds", e.Timeout)
+			if !slices.Contains(timeouts, t) {
+				timeouts = append(timeouts, t)
+			}
+		}
+	}
+	return strings.Join(timeouts, ", ")
+}
+
 // hookNameCompletion provides completion for hook names
 func hookNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) != 0 {
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/hook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hooksTestTool    string
+	hooksTestValue   string
+	hooksTestSet     []string
+	hooksTestPayload string
+	hooksTestTimeout int
+	hooksTestDryRun  bool
+	hooksTestJSON    bool
+	hooksTestGlobal  bool
+	hooksTestLocal   bool
+)
+
+var hooksTestCmd = &cobra.Command{
+	Use:     "test <name>",
+	Aliases: []string{"try"},
+	Short:   "Run a hook against a simulated event",
+	Long: `Run a hook locally against a simulated event payload.
+
+Builds the JSON that Claude Code sends on stdin for the hook's event
+(session id, transcript path, cwd, and for tool events the tool name and
+input), runs each command of the hook with its timeout, and reports the
+exit code, stdout and stderr, the decision the command printed as JSON,
+and whether the hook's matcher would have matched the event.
+
+Use --tool or --value to choose what the simulated event is for, --set to
+override payload fields (dotted keys address nested fields, values are
+parsed as JSON when possible), or --payload to start from a fixture file
+('-' reads stdin). --dry-run prints the payload without running anything.
+
+Exits with 1 if a command exits non-zero, times out, or cannot be started.
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+Examples:
+  jd hooks test PreToolUse-Bash-3f9a2c1d
+  jd hooks test PreToolUse-Bash-3f9a2c1d --set tool_input.command="rm -rf /"
+  jd hooks test PreToolUse-Edit-Write-5be1a7c0 --tool Write --json
+  jd hooks test SessionStart-all-9d04e2b6 --value resume
+  jd hooks test PreToolUse-Bash-3f9a2c1d --payload fixtures/rm.json --timeout 5`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runHooksTest,
+	ValidArgsFunction: hookNameCompletion,
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksTestCmd)
+	hooksTestCmd.Flags().StringVar(&hooksTestTool, "tool", "", "Tool name of a simulated tool event (default: first tool of the matcher, else Bash)")
+	hooksTestCmd.Flags().StringVar(&hooksTestValue, "value", "", "Matched value of a simulated event, e.g. SessionStart's source")
+	hooksTestCmd.Flags().StringArrayVarP(&hooksTestSet, "set", "s", nil, "Set a payload field (key=value, repeatable)")
+	hooksTestCmd.Flags().StringVarP(&hooksTestPayload, "payload", "p", "", "Read the payload from a JSON fixture file ('-' for stdin)")
+	hooksTestCmd.Flags().IntVarP(&hooksTestTimeout, "timeout", "t", 0, "Timeout in seconds for each command (default: the hook's timeout, else 60)")
+	hooksTestCmd.Flags().BoolVar(&hooksTestDryRun, "dry-run", false, "Print the payload without running the hook")
+	hooksTestCmd.Flags().BoolVar(&hooksTestJSON, "json", false, "Output in JSON format")
+	hooksTestCmd.Flags().BoolVarP(&hooksTestGlobal, "global", "g", false, "Test a hook from global ~/.claude/settings.json")
+	hooksTestCmd.Flags().BoolVarP(&hooksTestLocal, "local", "l", false, "Test a hook from local .claude/settings.json")
+	_ = hooksTestCmd.RegisterFlagCompletionFunc("tool", hookToolCompletion)
+}
+
+// hookTestJSON is the JSON output of jd hooks test.
+type hookTestJSON struct {
+	Hook       string                 `json:"hook"`
+	Event      hook.EventType         `json:"event"`
+	Matcher    string                 `json:"matcher"`
+	MatchField string                 `json:"match_field,omitempty"`
+	MatchValue string                 `json:"match_value,omitempty"`
+	Matched    bool                   `json:"matched"`
+	Payload    map[string]interface{} `json:"payload"`
+	Results    []hookTestResult       `json:"results,omitempty"`
+}
+
+// hookTestResult is the JSON output for one hook entry.
+type hookTestResult struct {
+	*hook.RunResult
+	Type       string `json:"type"`
+	TimeoutSec int    `json:"timeout_sec,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Blocking   bool   `json:"blocking"`
+	Skipped    string `json:"skipped,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func runHooksTest(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+
+	if hooksTestTimeout < 0 {
+		return fmt.Errorf("timeout must be positive: %d", hooksTestTimeout)
+	}
+
+	scope, err := ResolveScope(hooksTestGlobal, hooksTestLocal)
+	if err != nil {
+		return err
+	}
+
+	store := hook.NewStore(GetSettingsPathByScope(scope))
+	h, err := store.Get(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("hook not found in %s: %s", ScopeDescription(scope), name)
+		}
+		return fmt.Errorf("failed to get hook: %w", err)
+	}
+
+	payload, err := buildHookTestPayload(h)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	out := hookTestJSON{Hook: h.Name, Event: h.EventType, Matcher: h.Matcher, Matched: true, Payload: payload}
+	if event, ok := hook.LookupEvent(h.EventType); ok && event.Matcher != hook.MatcherNone {
+		out.MatchField = event.MatchField
+		out.MatchValue, _ = payload[event.MatchField].(string)
+		out.Matched = hook.Matches(h.Matcher, out.MatchValue)
+	}
+
+	if hooksTestDryRun {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if !hooksTestJSON {
+		printHookTestHeader(out, data)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	dir, _ := payload["cwd"].(string)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir, _ = os.Getwd()
+	}
+	timeout := time.Duration(hooksTestTimeout) * time.Second
+
+	failed := 0
+	for i, entry := range h.Hooks {
+		result := hookTestResult{Type: entry.Type}
+		if entry.Type != "command" {
+			result.RunResult = &hook.RunResult{Command: entry.Command}
+			result.Skipped = fmt.Sprintf("%s hooks are evaluated by Claude Code", entry.Type)
+		} else {
+			result.RunResult = hook.RunCommand(ctx, entry, data, dir, timeout)
+			result.TimeoutSec = int(result.Timeout / time.Second)
+			result.DurationMS = result.Duration.Milliseconds()
+			result.Blocking = result.RunResult.Blocking()
+			if result.Err != nil {
+				result.Error = result.Err.Error()
+			}
+			if result.ExitCode != 0 {
+				failed++
+			}
+		}
+		out.Results = append(out.Results, result)
+
+		if !hooksTestJSON {
+			printHookTestResult(i+1, len(h.Hooks), result)
+		}
+	}
+
+	if hooksTestJSON {
+		output, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+	}
+
+	if failed > 0 {
+		cmd.SilenceErrors = true
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+// buildHookTestPayload builds the payload for jd hooks test from the
+// simulated event, the fixture file and --set overrides, in that order.
+func buildHookTestPayload(h *hook.Hook) (map[string]interface{}, error) {
+	payload := hook.SamplePayload(h.EventType, h.Matcher, hook.PayloadOptions{Tool: hooksTestTool, Value: hooksTestValue})
+
+	if hooksTestPayload != "" {
+		var content []byte
+		var err error
+		if hooksTestPayload == "-" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(hooksTestPayload)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+		var fixture map[string]interface{}
+		if err := json.Unmarshal(content, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid payload %s: %w", hooksTestPayload, err)
+		}
+		for k, v := range fixture {
+			payload[k] = v
+		}
+	}
+
+	for _, kv := range hooksTestSet {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set %q: expected key=value", kv)
+		}
+		if err := hook.SetPayloadField(payload, key, value); err != nil {
+			return nil, fmt.Errorf("invalid --set %q: %w", kv, err)
+		}
+	}
+	return payload, nil
+}
+
+// printHookTestHeader prints the hook, whether it matches and the payload.
+func printHookTestHeader(out hookTestJSON, payload []byte) {
+	fmt.Printf("Hook:      %s\n", out.Hook)
+	fmt.Printf("Event:     %s\n", out.Event)
+	switch {
+	case out.MatchField == "":
+		fmt.Printf("Matcher:   %s (not used by this event)\n", out.Matcher)
+	case out.Matched:
+		fmt.Printf("Matcher:   %s (matches %s %q)\n", out.Matcher, out.MatchField, out.MatchValue)
+	default:
+		fmt.Printf("Matcher:   %s (does not match %s %q; Claude Code would not run this hook)\n",
+			out.Matcher, out.MatchField, out.MatchValue)
+	}
+	fmt.Printf("\nPayload:\n%s\n", indentLines(string(payload), "  "))
+}
+
+// printHookTestResult prints the outcome of one hook entry.
+func printHookTestResult(n, total int, r hookTestResult) {
+	fmt.Printf("\n[%d/%d] %s\n", n, total, r.Command)
+	if r.Skipped != "" {
+		fmt.Printf("  Skipped:   %s\n", r.Skipped)
+		return
+	}
+
+	switch {
+	case r.Err != nil:
+		fmt.Printf("  ❌ Failed to run: %v\n", r.Err)
+		return
+	case r.TimedOut:
+		fmt.Printf("  ❌ Timed out after %ds\n", r.TimeoutSec)
+	case r.ExitCode == 0:
+		fmt.Printf("  ✅ Exit code 0 (%dms)\n", r.DurationMS)
+	case r.ExitCode == hook.BlockingExitCode:
+		fmt.Printf("  ⛔ Exit code 2, blocking: stderr is fed back to Claude (%dms)\n", r.DurationMS)
+	default:
+		fmt.Printf("  ⚠️  Exit code %d, non-blocking: stderr is shown to the user (%dms)\n", r.ExitCode, r.DurationMS)
+	}
+
+	if r.Stdout != "" {
+		fmt.Printf("  Stdout:\n%s\n", indentLines(strings.TrimRight(r.Stdout, "\n"), "    "))
+	}
+	if r.Stderr != "" {
+		fmt.Printf("  Stderr:\n%s\n", indentLines(strings.TrimRight(r.Stderr, "\n"), "    "))
+	}
+
+	if o := r.Output; o != nil {
+		if o.Decision != "" {
+			fmt.Printf("  Decision:  %s\n", o.Decision)
+		}
+		if o.Reason != "" {
+			fmt.Printf("  Reason:    %s\n", o.Reason)
+		}
+		if o.Continue != nil {
+			fmt.Printf("  Continue:  %t\n", *o.Continue)
+		}
+		if o.StopReason != "" {
+			fmt.Printf("  Stop reason: %s\n", o.StopReason)
+		}
+		if o.SystemMessage != "" {
+			fmt.Printf("  System message: %s\n", o.SystemMessage)
+		}
+		if len(o.HookSpecificOutput) > 0 {
+			specific, _ := json.Marshal(o.HookSpecificOutput)
+			fmt.Printf("  Hook output: %s\n", specific)
+		}
+	}
+}
+
+// indentLines prefixes every line of s with indent.
+func indentLines(s, indent string) string {
+	return indent + strings.ReplaceAll(s, "\n", "\n"+indent)
+}
+
+// hookToolCompletion completes tool names for --tool.
+func hookToolCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var out []string
+	for _, t := range hook.Tools() {
+		out = append(out, t.Name+"\t"+t.Description)
+	}
+	return append(out, "mcp__\tMCP tools (mcp__<server>__<tool>)"), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/hook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hooksWhichEvent  string
+	hooksWhichTool   string
+	hooksWhichValue  string
+	hooksWhichJSON   bool
+	hooksWhichGlobal bool
+	hooksWhichLocal  bool
+)
+
+var hooksWhichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Show which hooks fire for an event",
+	Long: `List the hooks that would fire for an event, from both ~/.claude/settings.json
+and .claude/settings.json, in the order Claude Code starts them.
+
+For tool events (PreToolUse, PostToolUse) give the tool with --tool; MCP
+tools are named mcp__<server>__<tool>. For events with other matchers give
+the matched value with --value, e.g. SessionStart's source. Matchers are
+evaluated as Claude Code does: empty or * selects everything, names
+separated by | select those names, and anything else is a regex matched
+against the whole name.
+
+Commands that appear more than once run only once. Rules whose matcher is
+not a valid regex never fire and are reported separately.
+
+Examples:
+  jd hooks which --event pre --tool Edit
+  jd hooks which -e post --tool mcp__github__create_issue
+  jd hooks which -e start --value resume
+  jd hooks which -e stop --local --json`,
+	Args: cobra.NoArgs,
+	RunE: runHooksWhich,
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksWhichCmd)
+	hooksWhichCmd.Flags().StringVarP(&hooksWhichEvent, "event", "e", "", "Event type (full name or alias)")
+	hooksWhichCmd.Flags().StringVar(&hooksWhichTool, "tool", "", "Tool name for tool events, e.g. Edit or mcp__server__tool")
+	hooksWhichCmd.Flags().StringVar(&hooksWhichValue, "value", "", "Matched value for other events, e.g. resume for SessionStart")
+	hooksWhichCmd.Flags().BoolVar(&hooksWhichJSON, "json", false, "Output in JSON format")
+	hooksWhichCmd.Flags().BoolVarP(&hooksWhichGlobal, "global", "g", false, "Only consider global ~/.claude/settings.json")
+	hooksWhichCmd.Flags().BoolVarP(&hooksWhichLocal, "local", "l", false, "Only consider local .claude/settings.json")
+	_ = hooksWhichCmd.MarkFlagRequired("event")
+	_ = hooksWhichCmd.RegisterFlagCompletionFunc("event", hookEventCompletion)
+	_ = hooksWhichCmd.RegisterFlagCompletionFunc("tool", hookToolCompletion)
+	_ = hooksWhichCmd.RegisterFlagCompletionFunc("value", hookWhichValueCompletion)
+}
+
+// hookWhichOutput is the JSON output of jd hooks which.
+type hookWhichOutput struct {
+	Event   hook.EventType     `json:"event"`
+	Field   string             `json:"match_field,omitempty"`
+	Value   string             `json:"match_value,omitempty"`
+	Hooks   []hookWhichEntry   `json:"hooks"`
+	Invalid []hookWhichInvalid `json:"invalid,omitempty"`
+}
+
+// hookWhichEntry is one command that would run.
+type hookWhichEntry struct {
+	Order     int    `json:"order"`
+	Scope     string `json:"scope"`
+	Name      string `json:"name"`
+	Matcher   string `json:"matcher"`
+	Type      string `json:"type"`
+	Command   string `json:"command"`
+	Timeout   int    `json:"timeout,omitempty"`
+	Duplicate bool   `json:"duplicate,omitempty"` // same command as an earlier entry; runs once
+}
+
+// hookWhichInvalid is a rule skipped because its matcher does not parse.
+type hookWhichInvalid struct {
+	Scope   string `json:"scope"`
+	Name    string `json:"name"`
+	Matcher string `json:"matcher"`
+	Error   string `json:"error"`
+}
+
+func runHooksWhich(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	eventType, err := hook.ParseEventType(hooksWhichEvent)
+	if err != nil {
+		return err
+	}
+	event, _ := hook.LookupEvent(eventType)
+
+	out := hookWhichOutput{Event: eventType, Hooks: []hookWhichEntry{}}
+	switch event.Matcher {
+	case hook.MatcherTool:
+		if hooksWhichValue != "" {
+			return fmt.Errorf("%s matches tools: use --tool instead of --value", eventType)
+		}
+		if hooksWhichTool == "" {
+			return fmt.Errorf("--tool is required for %s", eventType)
+		}
+		out.Value = hooksWhichTool
+	case hook.MatcherValue:
+		if hooksWhichTool != "" {
+			return fmt.Errorf("%s does not match tools: use --value (%s)", eventType, strings.Join(event.MatcherValues, "|"))
+		}
+		if hooksWhichValue == "" {
+			return fmt.Errorf("--value is required for %s (%s)", eventType, strings.Join(event.MatcherValues, "|"))
+		}
+		out.Value = hooksWhichValue
+	default:
+		if hooksWhichTool != "" || hooksWhichValue != "" {
+			return fmt.Errorf("%s hooks do not use a matcher: drop --tool and --value", eventType)
+		}
+	}
+	if out.Value != "" {
+		out.Field = event.MatchField
+	}
+
+	// Global settings load before local ones
+	type source struct {
+		scope string
+		path  string
+	}
+	var sources []source
+	if !hooksWhichLocal {
+		sources = append(sources, source{"global", GetSettingsPathByScope(ScopeGlobal)})
+	}
+	if !hooksWhichGlobal {
+		if localPath := GetLocalSettingsPath(); localPath != "" {
+			sources = append(sources, source{"local", localPath})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		hooks, err := hook.NewStore(src.path).List()
+		if err != nil {
+			return fmt.Errorf("failed to read %s hooks: %w", src.scope, err)
+		}
+		for _, h := range filterHooksByEvent(hooks, eventType) {
+			if event.Matcher != hook.MatcherNone {
+				m, err := hook.ParseMatcher(h.Matcher)
+				if err != nil {
+					out.Invalid = append(out.Invalid, hookWhichInvalid{Scope: src.scope, Name: h.Name, Matcher: h.Matcher, Error: err.Error()})
+					continue
+				}
+				if !m.Match(out.Value) {
+					continue
+				}
+			}
+			for _, entry := range h.Hooks {
+				key := entry.Type + "\x00" + entry.Command
+				out.Hooks = append(out.Hooks, hookWhichEntry{
+					Order:     len(out.Hooks) + 1,
+					Scope:     src.scope,
+					Name:      h.Name,
+					Matcher:   h.Matcher,
+					Type:      entry.Type,
+					Command:   entry.Command,
+					Timeout:   entry.Timeout,
+					Duplicate: seen[key],
+				})
+				seen[key] = true
+			}
+		}
+	}
+
+	if hooksWhichJSON {
+		output, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if event.Matcher == hook.MatcherTool && !hook.IsKnownTool(out.Value) {
+		fmt.Printf("⚠️  %s is not a known tool name\n\n", out.Value)
+	}
+	printHooksWhich(out)
+	return nil
+}
+
+// printHooksWhich prints the commands that would run and the rules skipped
+// for invalid matchers.
+func printHooksWhich(out hookWhichOutput) {
+	subject := string(out.Event)
+	if out.Value != "" {
+		subject = fmt.Sprintf("%s (%s %s)", out.Event, out.Field, out.Value)
+	}
+
+	if len(out.Hooks) == 0 {
+		fmt.Printf("No hooks fire for %s.\n", subject)
+	} else {
+		fmt.Printf("Hooks that fire for %s:\n\n", subject)
+
+		nameWidth := len("NAME")
+		matcherWidth := len("MATCHER")
+		for _, e := range out.Hooks {
+			nameWidth = max(nameWidth, len(e.Name))
+			matcherWidth = max(matcherWidth, len(e.Matcher))
+		}
+		nameWidth = min(nameWidth, 35)
+		matcherWidth = min(matcherWidth, 20)
+
+		fmt.Printf("%-3s  %-6s  %-*s  %-*s  %s\n", "#", "SCOPE", nameWidth, "NAME", matcherWidth, "MATCHER", "COMMAND")
+		fmt.Printf("%s  %s  %s  %s  %s\n", strings.Repeat("-", 3), strings.Repeat("-", 6),
+			strings.Repeat("-", nameWidth), strings.Repeat("-", matcherWidth), strings.Repeat("-", 40))
+		for _, e := range out.Hooks {
+			name := e.Name
+			if len(name) > nameWidth {
+				name = name[:nameWidth-3] + "..."
+			}
+			matcher := e.Matcher
+			if len(matcher) > matcherWidth {
+				matcher = matcher[:matcherWidth-3] + "..."
+			}
+			command := formatHookEntry(hook.HookCommand{Type: e.Type, Command: e.Command, Timeout: e.Timeout})
+			if e.Duplicate {
+				command += " (duplicate, runs once)"
+			}
+			fmt.Printf("%-3d  %-6s  %-*s  %-*s  %s\n", e.Order, e.Scope, nameWidth, name, matcherWidth, matcher, command)
+		}
+	}
+
+	if len(out.Invalid) > 0 {
+		fmt.Printf("\nSkipped %d rule(s) with invalid matchers:\n", len(out.Invalid))
+		for _, r := range out.Invalid {
+			fmt.Printf("  %s (%s): %s\n", r.Name, r.Scope, r.Error)
+		}
+	}
+}
+
+// hookWhichValueCompletion completes --value with the values of the
+// --event event.
+func hookWhichValueCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	eventType, err := hook.ParseEventType(hooksWhichEvent)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	event, _ := hook.LookupEvent(eventType)
+	if event.Matcher != hook.MatcherValue {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return event.MatcherCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
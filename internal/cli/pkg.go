@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
+
+	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
 	"github.com/spf13/cobra"
 )
 
@@ -13,9 +17,55 @@ var pkgCmd = &cobra.Command{
 This command allows you to:
 - Register GitHub repositories containing Claude Code configurations
 - Browse and search available packages
-- Install, update, and uninstall packages with namespace isolation`,
+- Install, update, and uninstall packages with namespace isolation
+
+Packages are installed into ~/.claude (global) or .claude (local).
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Local installs are tracked in .claude/jd-packages.json so a project can carry its own package set.`,
 }
 
 func init() {
 	rootCmd.AddCommand(pkgCmd)
 }
+
+// newPkgManager returns a package manager that installs into the given
+// scope, with the trust policy from config.
+func newPkgManager(scope PathScope) (*pkgmgr.Manager, error) {
+	policy, err := pkgmgr.LoadTrustPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	if scope == ScopeLocal {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		manager := pkgmgr.NewProjectManager("~/.itda-skills", cwd)
+		manager.SetTrustPolicy(policy)
+		return manager, nil
+	}
+
+	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager.SetTrustPolicy(policy)
+	// Project commands shadow global ones of the same name
+	if cwd, err := os.Getwd(); err == nil {
+		if info, err := os.Stat(filepath.Join(cwd, localClaudeDir)); err == nil && info.IsDir() {
+			manager.AddPeerClaudeDir(filepath.Join(cwd, localClaudeDir))
+		}
+	}
+	return manager, nil
+}
+
+// resolvePkgManager resolves --global/--local flags and returns the package manager for that scope.
+func resolvePkgManager(globalFlag, localFlag bool) (*pkgmgr.Manager, PathScope, error) {
+	scope, err := ResolveScope(globalFlag, localFlag)
+	if err != nil {
+		return nil, "", err
+	}
+	manager, err := newPkgManager(scope)
+	if err != nil {
+		return nil, "", err
+	}
+	return manager, scope, nil
+}
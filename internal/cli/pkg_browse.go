@@ -12,8 +12,10 @@ import (
 )
 
 var (
-	pkgBrowseType string
-	pkgBrowseJSON bool
+	pkgBrowseType   string
+	pkgBrowseJSON   bool
+	pkgBrowseGlobal bool
+	pkgBrowseLocal  bool
 )
 
 var pkgBrowseCmd = &cobra.Command{
@@ -27,6 +29,12 @@ With a namespace argument, opens TUI filtered to that specific repository.
 
 Use --type to select the initial tab (TUI) or filter output (--json).
 Use --json for machine-readable output.
+Packages installed from the TUI go into the resolved scope: local if a .claude
+directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+Installed packages with updates in the local clones are marked with ↑; their
+preview shows the diff the update would apply (see 'jd pkg diff').
 
 Examples:
   jd pkg browse                     # Interactive TUI
@@ -42,6 +50,8 @@ func init() {
 	pkgCmd.AddCommand(pkgBrowseCmd)
 	pkgBrowseCmd.Flags().StringVarP(&pkgBrowseType, "type", "t", "", "Filter by type (skills, commands, agents, hooks)")
 	pkgBrowseCmd.Flags().BoolVar(&pkgBrowseJSON, "json", false, "Output in JSON format")
+	pkgBrowseCmd.Flags().BoolVarP(&pkgBrowseGlobal, "global", "g", false, "Install into global ~/.claude")
+	pkgBrowseCmd.Flags().BoolVarP(&pkgBrowseLocal, "local", "l", false, "Install into local .claude")
 }
 
 func runPkgBrowse(cmd *cobra.Command, args []string) error {
@@ -73,7 +83,12 @@ func runPkgBrowse(cmd *cobra.Command, args []string) error {
 	}
 
 	// Launch TUI (with optional namespace filter)
-	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager, _, err := resolvePkgManager(pkgBrowseGlobal, pkgBrowseLocal)
+	if err != nil {
+		return err
+	}
+	// Updates are checked against the local clones; 'jd pkg repo update' fetches
+	manager.SetFetchMaxAge(pkgmgr.NeverFetch)
 
 	// Validate namespace exists if provided
 	if namespace != "" {
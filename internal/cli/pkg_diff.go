@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkgDiffGlobal  bool
+	pkgDiffLocal   bool
+	pkgDiffOffline bool
+)
+
+var pkgDiffCmd = &cobra.Command{
+	Use:   "diff <name>",
+	Short: "Show upstream and local changes of an installed package",
+	Long: `Show what updating an installed package would change.
+
+Prints the unified diff of the package's source path in its repository
+between the installed commit and the latest one it would update to, and the
+diff of any installed files edited since install against the content they
+were installed with. Local edits are shown under installed/ and local/,
+relative to the .claude directory.
+
+The repository is fetched first unless --offline is given.
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+Examples:
+  jd pkg diff affa-ever--web-fetch
+  jd pkg diff affa-ever--web-fetch --offline`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPkgDiff,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgDiffCmd)
+	pkgDiffCmd.Flags().BoolVarP(&pkgDiffGlobal, "global", "g", false, "Diff a package in global ~/.claude")
+	pkgDiffCmd.Flags().BoolVarP(&pkgDiffLocal, "local", "l", false, "Diff a package in local .claude")
+	pkgDiffCmd.Flags().BoolVar(&pkgDiffOffline, "offline", false, "Diff against the local clone without fetching")
+}
+
+func runPkgDiff(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+
+	manager, scope, err := resolvePkgManager(pkgDiffGlobal, pkgDiffLocal)
+	if err != nil {
+		return err
+	}
+	if pkgDiffOffline {
+		manager.SetFetchMaxAge(pkgmgr.NeverFetch)
+	}
+
+	diff, err := manager.Diff(name)
+	if err != nil {
+		if errors.Is(err, pkgmgr.ErrPackageNotFound) {
+			return fmt.Errorf("package '%s' not found in %s. Use 'jd pkg list' to see installed packages", name, ScopeDescription(scope))
+		}
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	printPkgDiff(diff)
+	return nil
+}
+
+// printPkgDiff prints the upstream and local changes of a package.
+func printPkgDiff(diff *pkgmgr.PackageDiff) {
+	u := diff.Update
+	current, latest := updateVersions(u)
+
+	if u.HasUpdate {
+		fmt.Printf("%s: %s -> %s (%d files changed)\n", u.Package.Name, current, latest, len(u.ChangedFiles))
+	} else {
+		fmt.Printf("%s: up to date at %s\n", u.Package.Name, current)
+	}
+
+	if diff.Upstream != "" {
+		fmt.Println("\nUpstream changes:")
+		fmt.Print(ensureNewline(diff.Upstream))
+	}
+	if diff.Local != "" {
+		fmt.Println("\nLocal changes (an update overwrites these unless skipped or backed up):")
+		fmt.Print(ensureNewline(diff.Local))
+	}
+	if diff.Empty() {
+		fmt.Println("No changes.")
+	}
+}
+
+// ensureNewline terminates s with a newline.
+func ensureNewline(s string) string {
+	if !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
 	"github.com/spf13/cobra"
 )
 
-var pkgInfoJSON bool
+var (
+	pkgInfoJSON   bool
+	pkgInfoGlobal bool
+	pkgInfoLocal  bool
+)
 
 var pkgInfoCmd = &cobra.Command{
 	Use:     "info <name>",
@@ -28,18 +33,23 @@ Example:
 func init() {
 	pkgCmd.AddCommand(pkgInfoCmd)
 	pkgInfoCmd.Flags().BoolVar(&pkgInfoJSON, "json", false, "Output in JSON format")
+	pkgInfoCmd.Flags().BoolVarP(&pkgInfoGlobal, "global", "g", false, "Show from global ~/.claude")
+	pkgInfoCmd.Flags().BoolVarP(&pkgInfoLocal, "local", "l", false, "Show from local .claude")
 }
 
 func runPkgInfo(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 	name := args[0]
 
-	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager, scope, err := resolvePkgManager(pkgInfoGlobal, pkgInfoLocal)
+	if err != nil {
+		return err
+	}
 
 	pkg, err := manager.Get(name)
 	if err != nil {
 		if errors.Is(err, pkgmgr.ErrPackageNotFound) {
-			return fmt.Errorf("package '%s' not found. Use 'jd pkg list' to see installed packages", name)
+			return fmt.Errorf("package '%s' not found in %s. Use 'jd pkg list' to see installed packages", name, ScopeDescription(scope))
 		}
 		return fmt.Errorf("get package: %w", err)
 	}
@@ -55,12 +65,25 @@ func runPkgInfo(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Name:          %s\n", pkg.Name)
 	fmt.Printf("Original Name: %s\n", pkg.OriginalName)
+	if pkg.Alias {
+		fmt.Printf("Alias:         yes (installed with --as)\n")
+	}
 	fmt.Printf("Type:          %s\n", pkg.Type)
 	fmt.Printf("Namespace:     %s\n", pkg.Namespace)
 	fmt.Printf("Source Path:   %s\n", pkg.SourcePath)
 	fmt.Printf("Version Type:  %s\n", pkg.Version.Type)
 	fmt.Printf("Version SHA:   %s\n", pkg.Version.SHA)
 	fmt.Printf("Version Ref:   %s\n", pkg.Version.Ref)
+	fmt.Printf("Pinned:        %t\n", pkg.Version.Pinned)
+	if pkg.Dependency {
+		fmt.Printf("Installed As:  dependency\n")
+	}
+	if len(pkg.Requires) > 0 {
+		fmt.Printf("Requires:      %s\n", strings.Join(pkg.Requires, ", "))
+	}
+	if pkg.Provenance != nil {
+		fmt.Printf("Provenance:    %s\n", formatProvenance(pkg.Provenance))
+	}
 	fmt.Printf("Installed At:  %s\n", pkg.InstalledAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Updated At:    %s\n", pkg.UpdatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Files:         %d\n", len(pkg.Files))
@@ -75,5 +98,24 @@ func runPkgInfo(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if findings, err := manager.ScanInstalled(pkg.Name); err == nil && len(findings) > 0 {
+		fmt.Printf("Security Findings (%d):\n", len(findings))
+		printFindings(findings)
+	}
+
+	printPkgHooks(pkg.Hooks)
+
 	return nil
 }
+
+// formatProvenance describes the signature check of a package's commit.
+func formatProvenance(p *pkgmgr.Provenance) string {
+	switch {
+	case p.Status == pkgmgr.TrustVerified:
+		return fmt.Sprintf("%s (signed by %s)", p.Status, p.Signer)
+	case p.Reason != "":
+		return fmt.Sprintf("%s (%s)", p.Status, p.Reason)
+	default:
+		return string(p.Status)
+	}
+}
@@ -1,13 +1,26 @@
 package cli
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/itda-skills/jindo/internal/pkg/scan"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pkgInstallGlobal bool
+	pkgInstallLocal  bool
+	pkgInstallForce  bool
+	pkgInstallDryRun bool
+	pkgInstallAs     string
+	pkgInstallStrict bool
+)
+
 var pkgInstallCmd = &cobra.Command{
 	Use:     "install <namespace:path[@version]>",
 	Aliases: []string{"i"},
@@ -17,29 +30,95 @@ var pkgInstallCmd = &cobra.Command{
 The specification format is: namespace:path[@version]
 - namespace: The repository namespace (from 'jd pkg repo list')
 - path: The package path in the repository
-- version: Optional tag or commit SHA
+- version: Optional tag, branch or commit SHA
+
+Without a version, the package is installed from the repository's default
+branch and follows it on update. A tag pins the package: updates only move
+to newer semver tags. A commit SHA pins the package permanently. A branch
+name follows that branch.
+
+Packages may declare dependencies with a "requires:" list in their SKILL.md
+or agent/command frontmatter, or in a jd-package.yaml sidecar manifest.
+Entries are paths in the same repository (skills/web-fetch) or full specs
+(namespace:path[@version]). Dependencies are installed first.
+
+Hook packages may ship a sidecar manifest (hooks/<name>.jd-package.yaml) that
+registers the installed script in settings.json:
+  hooks:
+    - event: PreToolUse
+      matcher: Bash
+      command: "{{script}} --strict"
+Uninstall removes every rule that runs the installed script, so matchers and
+timeouts may be edited freely.
+
+Before installing, jd checks whether the package or its dependencies would
+overwrite files that no package installed (e.g. a hand-written command),
+files installed by another package, or shadow a command of the same name in
+the other scope (~/.claude vs .claude). You are asked before overwriting;
+--force overwrites without asking and takes ownership of the files.
+--dry-run shows the plan and any conflicts without installing.
+
+Hooks and agents are checked against the repository's trusted signing keys
+(see 'jd pkg repo trust'). Unverified ones are installed with a warning, or
+refused when pkg.require_signed is set.
+
+Packages are scanned before installing: hook and skill scripts, and the
+commands hook packages register in settings.json, that pipe downloads into a
+shell (curl | sh), delete recursively (rm -rf), upload data or reference
+secrets, skills and commands whose allowed-tools grant Bash
+without a restriction, and agents that request broad tool access. Findings
+are shown as warnings; --strict refuses packages with high-severity findings.
 
 Examples:
   jd pkg install affa-ever:skills/web-fetch
+  jd pkg install --dry-run affa-ever:skills/web-fetch
   jd pkg install affa-ever:commands/commit.md
   jd pkg install affa-ever:skills/web-fetch@v1.2.0
+  jd pkg install affa-ever:skills/web-fetch@3f2a9c1
+  jd pkg install affa-ever:.claude/commands/game/init.md
+  jd pkg install affa-ever:commands/commit.md --as commit
+  jd pkg install --strict affa-ever:hooks/format.sh
+
+Paths may start with skills/, commands/, agents/ or hooks/, at the repository
+root or under .claude/. Nested commands and agents keep their subdirectory:
+commands/game/init.md installs as commands/affa-ever--game/init.md and is
+invoked as /affa-ever--game:init.
 
-Installed packages are placed in ~/.itda-skills/ with namespace prefixes:
-  ~/.itda-skills/skills/affa-ever--web-fetch/
-  ~/.itda-skills/commands/affa-ever--commit.md`,
+Installed packages are placed in ~/.claude/ (global) or .claude/ (local)
+with namespace prefixes:
+  ~/.claude/skills/affa-ever--web-fetch/
+  ~/.claude/commands/affa-ever--commit.md
+
+--as installs the package under another name, e.g. --as commit installs
+commands/commit.md and is invoked as /commit. Update, uninstall and list use
+that name. Repositories added with 'jd pkg repo add --unprefixed' install
+their packages without the namespace prefix by default.
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override. Local installs are tracked in .claude/jd-packages.json.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPkgInstall,
 }
 
 func init() {
 	pkgCmd.AddCommand(pkgInstallCmd)
+	pkgInstallCmd.Flags().BoolVarP(&pkgInstallGlobal, "global", "g", false, "Install into global ~/.claude")
+	pkgInstallCmd.Flags().BoolVarP(&pkgInstallLocal, "local", "l", false, "Install into local .claude")
+	pkgInstallCmd.Flags().BoolVarP(&pkgInstallForce, "force", "f", false, "Overwrite conflicting files without asking")
+	pkgInstallCmd.Flags().BoolVar(&pkgInstallDryRun, "dry-run", false, "Show what would be installed and any conflicts")
+	pkgInstallCmd.Flags().StringVar(&pkgInstallAs, "as", "", "Install under this name instead of namespace--name")
+	pkgInstallCmd.Flags().BoolVar(&pkgInstallStrict, "strict", false, "Refuse packages with high-severity security findings")
 }
 
 func runPkgInstall(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 	spec := args[0]
 
-	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager, scope, err := resolvePkgManager(pkgInstallGlobal, pkgInstallLocal)
+	if err != nil {
+		return err
+	}
+	manager.SetStrictScan(pkgInstallStrict)
 
 	// Validate spec format
 	parsedSpec, err := pkgmgr.ParseSpec(spec)
@@ -53,12 +132,49 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("repository '%s' not found. Register with: jd pkg repo add gh:owner/repo", parsedSpec.Namespace)
 	}
 
-	fmt.Printf("Installing %s...\n", spec)
+	plan, err := manager.PlanInstall(spec, pkgInstallAs)
+	if err != nil {
+		if errors.Is(err, pkgmgr.ErrPackageAlreadyInstalled) {
+			return fmt.Errorf("package already installed in %s. Use 'jd pkg update' to update", ScopeDescription(scope))
+		}
+		return fmt.Errorf("install: %w", err)
+	}
+
+	if pkgInstallDryRun {
+		printInstallPlan(plan, scope)
+		return nil
+	}
+
+	printScanFindings(plan)
+	if pkgInstallStrict {
+		for _, p := range plan.Packages {
+			if high := scan.High(p.Findings); len(high) > 0 {
+				return fmt.Errorf("install refused: %s has %d high-severity finding(s) (--strict)", p.Name, len(high))
+			}
+		}
+	}
+
+	force := pkgInstallForce
+	if len(plan.Conflicts) > 0 && !force {
+		printInstallConflicts(plan.Conflicts)
+		if !confirmOverwrite() {
+			return fmt.Errorf("install cancelled: %d conflict(s); use --force to overwrite", len(plan.Conflicts))
+		}
+		force = true
+	}
+
+	fmt.Printf("Installing %s into %s...\n", spec, ScopeDescription(scope))
 
-	pkg, err := manager.Install(spec)
+	pkg, err := manager.InstallAs(spec, pkgInstallAs, force)
 	if err != nil {
 		if errors.Is(err, pkgmgr.ErrPackageAlreadyInstalled) {
-			return fmt.Errorf("package already installed. Use 'jd pkg update %s' to update", spec)
+			return fmt.Errorf("package already installed in %s. Use 'jd pkg update' to update", ScopeDescription(scope))
+		}
+		if errors.Is(err, pkgmgr.ErrScanBlocked) {
+			return fmt.Errorf("install refused (--strict): %w", err)
+		}
+		if errors.Is(err, pkgmgr.ErrUntrusted) {
+			return fmt.Errorf("install: %w\nTrust the signing key with 'jd pkg repo trust %s <key>' or add %s to %s", err, parsedSpec.Namespace, parsedSpec.Namespace, pkgmgr.TrustedNamespacesKey)
 		}
 		return fmt.Errorf("install: %w", err)
 	}
@@ -66,8 +182,11 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Installed successfully!\n")
 	fmt.Printf("  Name:      %s\n", pkg.Name)
 	fmt.Printf("  Type:      %s\n", pkg.Type)
-	fmt.Printf("  Version:   %s (%s)\n", pkg.Version.Ref, pkg.Version.SHA[:8])
+	fmt.Printf("  Version:   %s\n", formatPkgVersion(pkg.Version))
 	fmt.Printf("  Files:     %d\n", len(pkg.Files))
+	if len(pkg.Requires) > 0 {
+		fmt.Printf("  Requires:  %s\n", strings.Join(pkg.Requires, ", "))
+	}
 
 	if len(pkg.Files) > 0 {
 		fmt.Println("\nInstalled files:")
@@ -76,5 +195,127 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	printPkgHooks(pkg.Hooks)
+	printUnverifiedPackages(manager, plan)
+
 	return nil
 }
+
+// printUnverifiedPackages warns about installed hooks and agents whose
+// commit could not be verified against the repository's trusted keys.
+func printUnverifiedPackages(manager *pkgmgr.Manager, plan *pkgmgr.InstallPlan) {
+	for _, p := range plan.Packages {
+		pkg, err := manager.Get(p.Name)
+		if err != nil || pkg.Provenance == nil || pkg.Provenance.Status != pkgmgr.TrustUnverified {
+			continue
+		}
+		fmt.Printf("\nWarning: %s %s is from an unverified commit (%s): %s\n", pkg.Type, pkg.Name, shortSHA(pkg.Version.SHA), pkg.Provenance.Reason)
+	}
+}
+
+// printInstallPlan prints the packages an install would write and its conflicts.
+func printInstallPlan(plan *pkgmgr.InstallPlan, scope PathScope) {
+	fmt.Printf("Would install into %s:\n", ScopeDescription(scope))
+	for _, p := range plan.Packages {
+		label := ""
+		if p.Dependency {
+			label = " (dependency)"
+		}
+		fmt.Printf("  %s (%s) from %s%s\n", p.Name, p.Type, p.Spec, label)
+		for _, t := range p.Targets {
+			fmt.Printf("    %s\n", t)
+		}
+		for _, h := range p.Hooks {
+			matcher := h.Matcher
+			if matcher == "" {
+				matcher = "*"
+			}
+			fmt.Printf("    settings.json: %s [%s]: %s\n", h.Event, matcher, h.Command)
+		}
+	}
+
+	printScanFindings(plan)
+
+	if len(plan.Conflicts) == 0 {
+		fmt.Println("\nNo conflicts.")
+		return
+	}
+	printInstallConflicts(plan.Conflicts)
+}
+
+// printScanFindings prints the security findings of planned packages.
+func printScanFindings(plan *pkgmgr.InstallPlan) {
+	for _, p := range plan.Packages {
+		if len(p.Findings) == 0 {
+			continue
+		}
+		fmt.Printf("\nSecurity findings for %s (%d):\n", p.Name, len(p.Findings))
+		printFindings(p.Findings)
+	}
+}
+
+// printFindings prints security scan findings, one per line.
+func printFindings(findings []scan.Finding) {
+	for _, f := range findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Printf("  [%s] %s: %s (%s)\n", f.Severity, f.Rule, f.Message, location)
+	}
+}
+
+// printInstallConflicts prints install conflicts.
+func printInstallConflicts(conflicts []pkgmgr.Conflict) {
+	fmt.Printf("\nConflicts (%d):\n", len(conflicts))
+	for _, c := range conflicts {
+		fmt.Printf("  [%s] %s\n", c.Kind, c)
+	}
+}
+
+// confirmOverwrite asks whether to install over conflicting files.
+func confirmOverwrite() bool {
+	fmt.Print("\nOverwrite and continue? (y/N): ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+// printPkgHooks prints the settings.json hook rules registered by a package.
+func printPkgHooks(hooks []pkgmgr.InstalledHook) {
+	if len(hooks) == 0 {
+		return
+	}
+	fmt.Println("\nRegistered hooks (settings.json):")
+	for _, h := range hooks {
+		matcher := h.Matcher
+		if matcher == "" {
+			matcher = "*"
+		}
+		fmt.Printf("  %s [%s]: %s\n", h.Event, matcher, h.Command)
+	}
+}
+
+// shortSHA returns the abbreviated form of a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// formatPkgVersion formats version info for display.
+// Examples: "main (3f2a9c1e)", "v1.2.0 (3f2a9c1e)", "3f2a9c1e (pinned)"
+func formatPkgVersion(v pkgmgr.VersionInfo) string {
+	if v.Pinned && v.Type == pkgmgr.VersionTypeCommit {
+		return shortSHA(v.SHA) + " (pinned)"
+	}
+	return fmt.Sprintf("%s (%s)", v.Ref, shortSHA(v.SHA))
+}
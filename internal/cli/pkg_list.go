@@ -3,30 +3,80 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
 	"github.com/spf13/cobra"
 )
 
-var pkgListJSON bool
+var (
+	pkgListJSON    bool
+	pkgListGlobal  bool
+	pkgListLocal   bool
+	pkgListUpdates bool
+)
 
 var pkgListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"l", "ls"},
 	Short:   "List installed packages",
-	Long:    `List all installed packages from registered repositories.`,
-	RunE:    runPkgList,
+	Long: `List all installed packages from registered repositories.
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+With --updates, lists only packages that have updates, as 'jd pkg outdated'
+does: it exits with status 1 when there are any and 2 when the check fails.`,
+	RunE: runPkgList,
 }
 
 func init() {
 	pkgCmd.AddCommand(pkgListCmd)
 	pkgListCmd.Flags().BoolVar(&pkgListJSON, "json", false, "Output in JSON format")
+	pkgListCmd.Flags().BoolVarP(&pkgListGlobal, "global", "g", false, "List packages in global ~/.claude")
+	pkgListCmd.Flags().BoolVarP(&pkgListLocal, "local", "l", false, "List packages in local .claude")
+	pkgListCmd.Flags().BoolVar(&pkgListUpdates, "updates", false, "List only packages with available updates (see 'jd pkg outdated')")
+	pkgListCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		// Parsing stops at the bad flag, so --updates may come after it
+		if pkgListUpdates || hasFlag(os.Args[1:], "updates") {
+			return checkFailed(err)
+		}
+		return err
+	})
+}
+
+// hasFlag reports whether args set the long flag name.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--"+name || strings.HasPrefix(arg, "--"+name+"=") {
+			return true
+		}
+	}
+	return false
 }
 
 func runPkgList(cmd *cobra.Command, _ []string) error {
 	cmd.SilenceUsage = true
-	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager, scope, err := resolvePkgManager(pkgListGlobal, pkgListLocal)
+	if err != nil {
+		if pkgListUpdates {
+			return checkFailed(err)
+		}
+		return err
+	}
+
+	if pkgListUpdates {
+		maxAge, err := pkgFetchMaxAge(cmd, false, 0)
+		if err != nil {
+			return checkFailed(err)
+		}
+		manager.SetFetchMaxAge(maxAge)
+		return reportOutdated(cmd, manager, scope, nil, pkgListJSON)
+	}
 
 	packages, err := manager.List()
 	if err != nil {
@@ -34,7 +84,7 @@ func runPkgList(cmd *cobra.Command, _ []string) error {
 	}
 
 	if len(packages) == 0 {
-		fmt.Println("No packages installed.")
+		fmt.Printf("No packages installed in %s.\n", ScopeDescription(scope))
 		fmt.Println()
 		fmt.Println("Install a package with:")
 		fmt.Println("  jd pkg install <namespace>:<path>")
@@ -67,10 +117,7 @@ func runPkgList(cmd *cobra.Command, _ []string) error {
 		if len(pkg.Namespace) > nsWidth {
 			nsWidth = len(pkg.Namespace)
 		}
-		version := pkg.Version.SHA
-		if len(version) > 8 {
-			version = version[:8]
-		}
+		version := listVersion(pkg.Version)
 		if len(version) > versionWidth {
 			versionWidth = len(version)
 		}
@@ -86,8 +133,8 @@ func runPkgList(cmd *cobra.Command, _ []string) error {
 	if nsWidth > 15 {
 		nsWidth = 15
 	}
-	if versionWidth > 12 {
-		versionWidth = 12
+	if versionWidth > 20 {
+		versionWidth = 20
 	}
 
 	// Print header
@@ -119,10 +166,7 @@ func runPkgList(cmd *cobra.Command, _ []string) error {
 			ns = ns[:nsWidth-3] + "..."
 		}
 
-		version := pkg.Version.SHA
-		if len(version) > 8 {
-			version = version[:8]
-		}
+		version := listVersion(pkg.Version)
 
 		fmt.Printf("%-*s  %-*s  %-*s  %-*s\n",
 			nameWidth, name,
@@ -131,6 +175,15 @@ func runPkgList(cmd *cobra.Command, _ []string) error {
 			versionWidth, version)
 	}
 
-	fmt.Printf("\nTotal: %d packages\n", len(packages))
+	fmt.Printf("\nTotal: %d packages in %s\n", len(packages), ScopeDescription(scope))
 	return nil
 }
+
+// listVersion returns the version column for the package list.
+// Tag-pinned packages show the tag, others the abbreviated commit SHA.
+func listVersion(v pkgmgr.VersionInfo) string {
+	if v.Type == pkgmgr.VersionTypeTag {
+		return v.Ref
+	}
+	return shortSHA(v.SHA)
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/spf13/cobra"
+)
+
+var pkgLockLocal bool
+
+var pkgLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Write installed packages to the project lockfile",
+	Long: `Write the installed packages to .claude/jd-lock.json in the current directory.
+
+The lockfile records each package's repository, source path, exact commit
+and content hashes of the installed files. Commit it to your repository and
+run 'jd pkg sync' on other machines to install the identical package set.
+
+The lockfile describes the packages of a project, so they are read from the
+local .claude directory. Run it in a project that has one, or pass --local to
+lock into a new one.
+
+Example:
+  jd pkg lock`,
+	Args: cobra.NoArgs,
+	RunE: runPkgLock,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgLockCmd)
+	pkgLockCmd.Flags().BoolVarP(&pkgLockLocal, "local", "l", false, "Use local .claude even if it does not exist yet")
+}
+
+func runPkgLock(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	manager, lockPath, err := resolveLockManager(pkgLockLocal)
+	if err != nil {
+		return err
+	}
+
+	lock, err := manager.Lock()
+	if err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+
+	if err := pkgmgr.WriteLockFile(lockPath, lock); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s\n", lockPath)
+	fmt.Printf("  Repositories: %d\n", len(lock.Repos))
+	fmt.Printf("  Packages:     %d\n", len(lock.Packages))
+	return nil
+}
+
+// resolveLockManager returns the package manager of the project in the
+// current directory and the path of its lockfile. The lockfile lists
+// project packages, so global scope is refused rather than writing global
+// packages into, or syncing them against, a project lockfile.
+func resolveLockManager(localFlag bool) (*pkgmgr.Manager, string, error) {
+	manager, scope, err := resolvePkgManager(false, localFlag)
+	if err != nil {
+		return nil, "", err
+	}
+	if scope != ScopeLocal {
+		return nil, "", fmt.Errorf("no %s directory in the current directory: the lockfile is per project (use --local to create one)", localClaudeDir)
+	}
+	return manager, GetPathByScope(ScopeLocal, pkgmgr.LockFileName), nil
+}
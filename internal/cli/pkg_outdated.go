@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of jd pkg outdated.
+const (
+	exitUpdatesAvailable = 1
+	exitCheckFailed      = 2
+)
+
+var (
+	pkgOutdatedJSON    bool
+	pkgOutdatedGlobal  bool
+	pkgOutdatedLocal   bool
+	pkgOutdatedMaxAge  time.Duration
+	pkgOutdatedOffline bool
+	pkgOutdatedJobs    int
+	pkgOutdatedTimeout time.Duration
+)
+
+var pkgOutdatedCmd = &cobra.Command{
+	Use:     "outdated [name...]",
+	Aliases: []string{"od"},
+	Short:   "List packages with available updates",
+	Long: `List installed packages that have updates, without changing anything.
+
+For each package, shows the installed and latest versions, the files and
+commits that changed it upstream, and whether its installed files were
+edited locally. Use --json for machine-readable output.
+
+Repositories are fetched as for 'jd pkg update' (see --max-age, --offline,
+--jobs and --timeout). 'jd pkg list --updates' is the same report.
+
+Exit codes:
+  0  all packages are up to date
+  1  updates are available
+  2  the check failed, e.g. some repositories or packages could not be checked
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+Examples:
+  jd pkg outdated
+  jd pkg outdated --json
+  jd pkg outdated affa-ever--web-fetch --offline`,
+	RunE: runPkgOutdated,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgOutdatedCmd)
+	pkgOutdatedCmd.Flags().BoolVar(&pkgOutdatedJSON, "json", false, "Output in JSON format")
+	pkgOutdatedCmd.Flags().BoolVarP(&pkgOutdatedGlobal, "global", "g", false, "Check packages in global ~/.claude")
+	pkgOutdatedCmd.Flags().BoolVarP(&pkgOutdatedLocal, "local", "l", false, "Check packages in local .claude")
+	pkgOutdatedCmd.Flags().DurationVar(&pkgOutdatedMaxAge, "max-age", 0, "Skip fetching repositories fetched within this duration")
+	pkgOutdatedCmd.Flags().BoolVar(&pkgOutdatedOffline, "offline", false, "Check against local clones without fetching")
+	pkgOutdatedCmd.Flags().IntVarP(&pkgOutdatedJobs, "jobs", "j", repo.DefaultFetchWorkers, "Number of repositories to fetch at once")
+	pkgOutdatedCmd.Flags().DurationVar(&pkgOutdatedTimeout, "timeout", repo.DefaultFetchTimeout, "Fetch timeout per repository")
+	pkgOutdatedCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return checkFailed(err)
+	})
+}
+
+func runPkgOutdated(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	manager, scope, err := resolvePkgManager(pkgOutdatedGlobal, pkgOutdatedLocal)
+	if err != nil {
+		return checkFailed(err)
+	}
+
+	maxAge, err := pkgFetchMaxAge(cmd, pkgOutdatedOffline, pkgOutdatedMaxAge)
+	if err != nil {
+		return checkFailed(err)
+	}
+	manager.SetFetchMaxAge(maxAge)
+	manager.SetFetchOptions(repo.FetchOptions{Workers: pkgOutdatedJobs, Timeout: pkgOutdatedTimeout})
+
+	return reportOutdated(cmd, manager, scope, args, pkgOutdatedJSON)
+}
+
+// outdatedJSON is the JSON output of jd pkg outdated.
+type outdatedJSON struct {
+	Updates  []pkgmgr.UpdateInfo `json:"updates"`
+	Failures []outdatedFailure   `json:"failures,omitempty"`
+}
+
+// outdatedFailure is a repository or package that could not be checked.
+type outdatedFailure struct {
+	Namespace string `json:"namespace,omitempty"` // empty when the whole check failed
+	Package   string `json:"package,omitempty"`
+	Error     string `json:"error"`
+}
+
+// checkFailed wraps an error of jd pkg outdated so that it exits with
+// exitCheckFailed; exitUpdatesAvailable only ever means updates.
+func checkFailed(err error) error {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	return &ExitError{Code: exitCheckFailed, Err: err}
+}
+
+// reportOutdated prints the packages of manager that have updates and
+// returns an ExitError when there are updates or failed checks.
+func reportOutdated(cmd *cobra.Command, manager *pkgmgr.Manager, scope PathScope, names []string, asJSON bool) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	if !asJSON {
+		fmt.Printf("Checking for updates in %s...\n", ScopeDescription(scope))
+	}
+	report, err := manager.Outdated(ctx, names...)
+	if err != nil {
+		err = fmt.Errorf("check updates: %w", err)
+		if asJSON {
+			// Consumers still get a document, with the error as a failure
+			out := outdatedJSON{Updates: []pkgmgr.UpdateInfo{}, Failures: []outdatedFailure{{Error: err.Error()}}}
+			if output, jsonErr := json.MarshalIndent(out, "", "  "); jsonErr == nil {
+				fmt.Println(string(output))
+			}
+		}
+		return checkFailed(err)
+	}
+
+	var checkErr error
+	if asJSON {
+		out := outdatedJSON{Updates: report.Updates}
+		if out.Updates == nil {
+			out.Updates = []pkgmgr.UpdateInfo{}
+		}
+		for _, f := range report.Failures {
+			out.Failures = append(out.Failures, outdatedFailure{Namespace: f.Namespace, Package: f.Package, Error: f.Err.Error()})
+		}
+		output, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return checkFailed(err)
+		}
+		fmt.Println(string(output))
+		if len(report.Failures) > 0 {
+			checkErr = fmt.Errorf("%d update checks failed", len(report.Failures))
+		}
+	} else {
+		checkErr = printCheckFailures(report.Failures)
+		if len(report.Updates) == 0 {
+			if checkErr == nil {
+				fmt.Println("All packages are up to date.")
+			}
+		} else {
+			printOutdated(report.Updates)
+		}
+	}
+
+	switch {
+	case checkErr != nil:
+		return &ExitError{Code: exitCheckFailed, Err: checkErr}
+	case len(report.Updates) > 0:
+		cmd.SilenceErrors = true
+		return &ExitError{Code: exitUpdatesAvailable}
+	}
+	return nil
+}
+
+// printOutdated prints a table of updates followed by the commits of each.
+func printOutdated(updates []pkgmgr.UpdateInfo) {
+	nameWidth := len("NAME")
+	currentWidth := len("CURRENT")
+	latestWidth := len("LATEST")
+	for _, u := range updates {
+		if len(u.Package.Name) > nameWidth {
+			nameWidth = len(u.Package.Name)
+		}
+		current, latest := updateVersions(u)
+		if len(current) > currentWidth {
+			currentWidth = len(current)
+		}
+		if len(latest) > latestWidth {
+			latestWidth = len(latest)
+		}
+	}
+	if nameWidth > 35 {
+		nameWidth = 35
+	}
+
+	fmt.Printf("\n%d package(s) have updates available:\n\n", len(updates))
+	fmt.Printf("%-*s  %-*s  %-*s  %-8s  %-8s  %s\n",
+		nameWidth, "NAME", currentWidth, "CURRENT", latestWidth, "LATEST", "FILES", "COMMITS", "LOCAL")
+	fmt.Printf("%s  %s  %s  %s  %s  %s\n",
+		strings.Repeat("-", nameWidth), strings.Repeat("-", currentWidth), strings.Repeat("-", latestWidth),
+		strings.Repeat("-", 8), strings.Repeat("-", 8), strings.Repeat("-", 8))
+
+	for _, u := range updates {
+		name := u.Package.Name
+		if len(name) > nameWidth {
+			name = name[:nameWidth-3] + "..."
+		}
+		current, latest := updateVersions(u)
+		local := "-"
+		if u.LocallyModified {
+			local = "modified"
+		}
+		fmt.Printf("%-*s  %-*s  %-*s  %-8d  %-8d  %s\n",
+			nameWidth, name, currentWidth, current, latestWidth, latest,
+			len(u.ChangedFiles), len(u.Commits), local)
+	}
+
+	for _, u := range updates {
+		if len(u.Commits) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", u.Package.Name)
+		for _, c := range u.Commits {
+			fmt.Printf("  %s %s\n", shortSHA(c.SHA), c.Subject)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Run 'jd pkg update --apply' to install updates, or 'jd pkg diff <name>' to see the changes.")
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/itda-skills/jindo/internal/agent"
+	"github.com/itda-skills/jindo/internal/command"
+	"github.com/itda-skills/jindo/internal/pkg/publish"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/internal/skill"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkgPublishTo      string
+	pkgPublishGlobal  bool
+	pkgPublishLocal   bool
+	pkgPublishCommit  bool
+	pkgPublishMessage string
+)
+
+var pkgPublishCmd = &cobra.Command{
+	Use:   "publish <type> <name> --to <dir>",
+	Short: "Copy a local skill, command, agent or hook into a package repository",
+	Long: `Copy a skill, command, agent or hook from ~/.claude (global) or .claude
+(local) into a package repository laid out for 'jd pkg repo add':
+
+  skills/<name>/       commands/<name>.md
+  agents/<name>.md     hooks/<script>
+
+The resource is validated first; validation errors stop the publish and
+warnings are printed. .history/ backup folders are left out. An earlier copy
+in the repository is replaced, and the package index in README.md (between
+<!-- jd-packages:start --> and <!-- jd-packages:end -->) is regenerated.
+The directory is created if it does not exist.
+
+With --commit, --to must be a git working tree and the published files and
+README are committed. Push the commit yourself.
+
+Type is one of: skill, command, agent, hook. Nested commands and agents are
+named dir:name. Hooks may be named with or without their extension.
+
+Examples:
+  jd pkg publish skill web-fetch --to ~/src/my-skills
+  jd pkg publish command game:init --to ~/src/my-skills --commit
+  jd pkg publish hook guard --to ~/src/my-skills --commit -m "Add guard hook"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPkgPublish,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgPublishCmd)
+	pkgPublishCmd.Flags().StringVar(&pkgPublishTo, "to", "", "Package repository directory (required)")
+	pkgPublishCmd.Flags().BoolVarP(&pkgPublishGlobal, "global", "g", false, "Publish from global ~/.claude")
+	pkgPublishCmd.Flags().BoolVarP(&pkgPublishLocal, "local", "l", false, "Publish from local .claude")
+	pkgPublishCmd.Flags().BoolVar(&pkgPublishCommit, "commit", false, "Commit the published files with git")
+	pkgPublishCmd.Flags().StringVarP(&pkgPublishMessage, "message", "m", "", "Commit message (with --commit)")
+	_ = pkgPublishCmd.MarkFlagRequired("to")
+}
+
+func runPkgPublish(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	pkgType, err := parsePkgType(args[0])
+	if err != nil {
+		return err
+	}
+	name := args[1]
+
+	scope, err := ResolveScope(pkgPublishGlobal, pkgPublishLocal)
+	if err != nil {
+		return err
+	}
+
+	source, err := publish.SourcePath(GetPathByScope(scope, ""), pkgType, name)
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, ScopeDescription(scope))
+	}
+
+	result := validatePublishSource(pkgType, name, source)
+	if len(result.Errors) > 0 || len(result.Warnings) > 0 {
+		printValidationResults(result)
+		fmt.Println()
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("not published: validation failed with %d error(s)", len(result.Errors))
+	}
+
+	published, err := publish.Publish(pkgType, name, source, pkgPublishTo, publish.Options{
+		Commit:  pkgPublishCommit,
+		Message: pkgPublishMessage,
+	})
+	if err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	action := "Published"
+	if published.Replaced {
+		action = "Republished"
+	}
+	fmt.Printf("%s %s %s to %s\n", action, pkgType, name, filepath.Join(pkgPublishTo, filepath.FromSlash(published.Target)))
+	for _, f := range published.Files {
+		fmt.Printf("  %s\n", f)
+	}
+	if published.Readme {
+		fmt.Println("Updated README.md package index")
+	}
+	if pkgPublishCommit {
+		if published.Committed {
+			fmt.Println("Committed. Push the repository to share it.")
+		} else {
+			fmt.Println("Nothing to commit: the repository already has this version.")
+		}
+	}
+
+	return nil
+}
+
+// parsePkgType parses a package type name, singular or plural.
+func parsePkgType(s string) (repo.PackageType, error) {
+	switch s {
+	case "skills", "skill":
+		return repo.TypeSkill, nil
+	case "commands", "command":
+		return repo.TypeCommand, nil
+	case "agents", "agent":
+		return repo.TypeAgent, nil
+	case "hooks", "hook":
+		return repo.TypeHook, nil
+	default:
+		return "", fmt.Errorf("invalid type: %s (use: skill, command, agent, hook)", s)
+	}
+}
+
+// validatePublishSource runs the validate checks on a single resource.
+func validatePublishSource(pkgType repo.PackageType, name, source string) *ValidationResult {
+	result := &ValidationResult{Checked: 1}
+	fail := func(path, message string) {
+		result.Errors = append(result.Errors, ValidationError{Type: string(pkgType), Name: name, Path: path, Message: message})
+	}
+
+	switch pkgType {
+	case repo.TypeSkill:
+		var skillFile string
+		for _, candidate := range []string{"SKILL.md", "skill.md"} {
+			if _, err := os.Stat(filepath.Join(source, candidate)); err == nil {
+				skillFile = filepath.Join(source, candidate)
+				break
+			}
+		}
+		if skillFile == "" {
+			fail(source, "missing SKILL.md")
+			break
+		}
+		s, err := skill.ParseSkillFile(skillFile)
+		if err != nil {
+			fail(skillFile, fmt.Sprintf("failed to parse: %v", err))
+			break
+		}
+		checkSkill(result, name, s)
+
+	case repo.TypeCommand:
+		c, err := command.ParseCommandFile(source)
+		if err != nil {
+			fail(source, fmt.Sprintf("failed to parse: %v", err))
+			break
+		}
+		c.Name = name
+		checkCommand(result, c)
+
+	case repo.TypeAgent:
+		a, err := agent.ParseAgentFile(source)
+		if err != nil {
+			fail(source, fmt.Sprintf("failed to parse: %v", err))
+			break
+		}
+		checkAgent(result, a)
+
+	case repo.TypeHook:
+		if info, err := os.Stat(source); err == nil && info.Mode().Perm()&0111 == 0 {
+			result.Warnings = append(result.Warnings, ValidationError{Type: "hook", Name: name, Path: source, Message: "script is not executable"})
+		}
+	}
+
+	// Sidecar manifests must parse, or installs of the package fail
+	manifest := filepath.FromSlash(repo.ManifestPath(pkgType, filepath.ToSlash(source)))
+	if data, err := os.ReadFile(manifest); err == nil {
+		if _, err := repo.ParseManifest(data); err != nil {
+			fail(manifest, fmt.Sprintf("invalid manifest: %v", err))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/registry"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/spf13/cobra"
+)
+
+var pkgRegistryCmd = &cobra.Command{
+	Use:     "registry",
+	Aliases: []string{"reg"},
+	Short:   "Manage subscribed package registries",
+	Long: `Manage registries: curated lists of package repositories.
+
+A registry is a JSON or TOML file, local or in a git repository, listing
+repositories with their namespaces, descriptions and recommended packages.
+Subscribing registers every listed repository; syncing adds newly listed
+repositories and removes the ones dropped from the list. Repositories
+registered by hand are never touched.
+
+Example registry file (jd-registry.toml):
+
+  name = "team"
+  description = "Packages for the platform team"
+
+  [[repos]]
+  namespace = "plat"
+  url = "gh:acme/platform-skills"
+  description = "Platform skills"
+  recommended = ["skills/deploy", "commands/release"]`,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgRegistryCmd)
+}
+
+// newRegistryStore returns the registry store backed by the repository store.
+func newRegistryStore() *registry.Store {
+	return registry.NewStore("~/.itda-skills", repo.NewStore("~/.itda-skills"))
+}
+
+// printSyncResult prints what a registry sync changed.
+func printSyncResult(result *registry.SyncResult) {
+	for _, ns := range result.Added {
+		fmt.Printf("  + %s\n", ns)
+	}
+	for _, ns := range result.Updated {
+		fmt.Printf("  ~ %s\n", ns)
+	}
+	for _, ns := range result.Removed {
+		fmt.Printf("  - %s\n", ns)
+	}
+	for _, ns := range result.Kept {
+		fmt.Printf("  = %s: no longer listed, kept as hand-registered\n", ns)
+	}
+	for _, s := range result.Skipped {
+		fmt.Printf("  ! %s: skipped, %s\n", s.Namespace, s.Reason)
+	}
+	for _, f := range result.Failed {
+		fmt.Printf("  x %s: %v\n", f.Namespace, f.Err)
+	}
+	fmt.Printf("%d added, %d updated, %d removed, %d kept, %d unchanged\n",
+		len(result.Added), len(result.Updated), len(result.Removed), len(result.Kept), len(result.Unchanged))
+}
+
+// printRecommended prints install hints for the recommended packages of
+// the given namespaces.
+func printRecommended(reg *registry.Registry, namespaces []string) {
+	want := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		want[ns] = true
+	}
+
+	var hints []string
+	for _, e := range reg.Entries {
+		if !want[e.Namespace] {
+			continue
+		}
+		for _, path := range e.Recommended {
+			hints = append(hints, fmt.Sprintf("jd pkg install %s:%s", e.Namespace, path))
+		}
+	}
+	if len(hints) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Recommended packages:")
+	for _, h := range hints {
+		fmt.Printf("  %s\n", h)
+	}
+}
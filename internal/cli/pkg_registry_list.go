@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var pkgRegistryListJSON bool
+
+var pkgRegistryListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l", "ls"},
+	Short:   "List subscribed registries",
+	Long: `List subscribed registries with the repositories and recommended
+packages they listed at their last sync.`,
+	RunE: runPkgRegistryList,
+}
+
+func init() {
+	pkgRegistryCmd.AddCommand(pkgRegistryListCmd)
+	pkgRegistryListCmd.Flags().BoolVar(&pkgRegistryListJSON, "json", false, "Output in JSON format")
+}
+
+func runPkgRegistryList(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	store := newRegistryStore()
+
+	regs, err := store.List()
+	if err != nil {
+		return fmt.Errorf("list registries: %w", err)
+	}
+
+	if pkgRegistryListJSON {
+		output, err := json.MarshalIndent(regs, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(regs) == 0 {
+		fmt.Println("No registries subscribed.")
+		fmt.Println()
+		fmt.Println("Subscribe to a registry with:")
+		fmt.Println("  jd pkg registry subscribe gh:owner/registry")
+		return nil
+	}
+
+	for i, r := range regs {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s)\n", r.Name, r.Source)
+		if r.Description != "" {
+			fmt.Printf("  %s\n", r.Description)
+		}
+		if !r.SyncedAt.IsZero() {
+			fmt.Printf("  Synced: %s\n", r.SyncedAt.Local().Format("2006-01-02 15:04"))
+		}
+		for _, e := range r.Entries {
+			line := fmt.Sprintf("  %-12s %s", e.Namespace, e.URL)
+			if e.Description != "" {
+				line += "  " + e.Description
+			}
+			fmt.Println(line)
+			if len(e.Recommended) > 0 {
+				fmt.Printf("  %-12s recommended: %s\n", "", strings.Join(e.Recommended, ", "))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkgRegistrySubscribeName string
+	pkgRegistrySubscribeFile string
+)
+
+var pkgRegistrySubscribeCmd = &cobra.Command{
+	Use:     "subscribe <source>",
+	Aliases: []string{"sub", "add"},
+	Short:   "Subscribe to a registry and register its repositories",
+	Long: `Subscribe to a registry and register every repository it lists.
+
+The source is a registry file (.toml or .json) or a git repository
+containing one, in any form accepted by 'jd pkg repo add'. In a repository,
+the file is looked up as jd-registry.toml, jd-registry.json, registry.toml
+or registry.json at the root, unless --file names it.
+
+The registry is named after its "name" field, or the source when it has
+none; --name overrides both. Listed namespaces already registered by hand
+or by another registry are skipped.
+
+Examples:
+  jd pkg registry subscribe ./team-registry.toml
+  jd pkg registry subscribe gh:acme/claude-registry
+  jd pkg registry subscribe gh:acme/tools --file claude/registry.json --name acme`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPkgRegistrySubscribe,
+}
+
+func init() {
+	pkgRegistryCmd.AddCommand(pkgRegistrySubscribeCmd)
+	pkgRegistrySubscribeCmd.Flags().StringVar(&pkgRegistrySubscribeName, "name", "", "Registry name (default: the name in the registry file)")
+	pkgRegistrySubscribeCmd.Flags().StringVar(&pkgRegistrySubscribeFile, "file", "", "Registry file path within a git source")
+}
+
+func runPkgRegistrySubscribe(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	store := newRegistryStore()
+
+	fmt.Printf("Subscribing to %s...\n", args[0])
+	reg, result, err := store.Subscribe(args[0], pkgRegistrySubscribeName, pkgRegistrySubscribeFile)
+	if err != nil {
+		if errors.Is(err, registry.ErrRegistryExists) {
+			return fmt.Errorf("%w; use 'jd pkg registry sync' to refresh it", err)
+		}
+		return fmt.Errorf("subscribe: %w", err)
+	}
+
+	fmt.Printf("Subscribed to registry '%s'", reg.Name)
+	if reg.Description != "" {
+		fmt.Printf(": %s", reg.Description)
+	}
+	fmt.Println()
+	printSyncResult(result)
+	printRecommended(reg, result.Added)
+
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d repositories could not be registered", len(result.Failed))
+	}
+	return nil
+}
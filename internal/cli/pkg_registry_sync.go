@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var pkgRegistrySyncKeepRepos bool
+
+var pkgRegistrySyncCmd = &cobra.Command{
+	Use:   "sync [name...]",
+	Short: "Sync repositories with subscribed registries",
+	Long: `Re-read subscribed registries, pulling git registries first, and sync the
+registered repositories: newly listed repositories are added, repositories
+dropped from a registry are removed, and changed URLs, descriptions and
+prefix settings are applied. Installed packages are never removed. With
+--keep-repos, dropped repositories stay registered as if added by hand.
+
+Without names, all subscribed registries are synced.
+
+Examples:
+  jd pkg registry sync
+  jd pkg registry sync team
+  jd pkg registry sync team --keep-repos`,
+	RunE: runPkgRegistrySync,
+}
+
+func init() {
+	pkgRegistryCmd.AddCommand(pkgRegistrySyncCmd)
+	pkgRegistrySyncCmd.Flags().BoolVar(&pkgRegistrySyncKeepRepos, "keep-repos", false, "Keep repositories dropped from a registry registered")
+}
+
+func runPkgRegistrySync(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	store := newRegistryStore()
+
+	names := args
+	if len(names) == 0 {
+		regs, err := store.List()
+		if err != nil {
+			return fmt.Errorf("list registries: %w", err)
+		}
+		for _, r := range regs {
+			names = append(names, r.Name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("No registries subscribed.")
+		return nil
+	}
+
+	var failed int
+	for i, name := range names {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Syncing registry '%s'...\n", name)
+		result, err := store.Sync(name, pkgRegistrySyncKeepRepos)
+		if err != nil {
+			if errors.Is(err, registry.ErrRegistryNotFound) {
+				err = fmt.Errorf("registry '%s' not found", name)
+			}
+			fmt.Printf("  x %v\n", err)
+			failed++
+			continue
+		}
+		printSyncResult(result)
+		if len(result.Added) > 0 {
+			if reg, err := store.Get(name); err == nil {
+				printRecommended(reg, result.Added)
+			}
+		}
+		if len(result.Failed) > 0 {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d registries did not sync cleanly", failed)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var pkgRegistryUnsubscribeKeepRepos bool
+
+var pkgRegistryUnsubscribeCmd = &cobra.Command{
+	Use:     "unsubscribe <name>",
+	Aliases: []string{"unsub", "remove", "rm"},
+	Short:   "Unsubscribe from a registry and remove its repositories",
+	Long: `Unsubscribe from a registry and remove the repositories it registered.
+
+Installed packages from those repositories remain installed. With
+--keep-repos, the repositories stay registered as if added by hand.
+
+Examples:
+  jd pkg registry unsubscribe team
+  jd pkg registry unsubscribe team --keep-repos`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPkgRegistryUnsubscribe,
+}
+
+func init() {
+	pkgRegistryCmd.AddCommand(pkgRegistryUnsubscribeCmd)
+	pkgRegistryUnsubscribeCmd.Flags().BoolVar(&pkgRegistryUnsubscribeKeepRepos, "keep-repos", false, "Keep the registry's repositories registered")
+}
+
+func runPkgRegistryUnsubscribe(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+	store := newRegistryStore()
+
+	namespaces, err := store.Unsubscribe(name, pkgRegistryUnsubscribeKeepRepos)
+	if err != nil {
+		if errors.Is(err, registry.ErrRegistryNotFound) {
+			return fmt.Errorf("registry '%s' not found", name)
+		}
+		return fmt.Errorf("unsubscribe: %w", err)
+	}
+
+	fmt.Printf("Unsubscribed from registry '%s'\n", name)
+	for _, ns := range namespaces {
+		if pkgRegistryUnsubscribeKeepRepos {
+			fmt.Printf("  kept %s\n", ns)
+		} else {
+			fmt.Printf("  - %s\n", ns)
+		}
+	}
+	return nil
+}
@@ -8,7 +8,7 @@ var pkgRepoCmd = &cobra.Command{
 	Use:     "repo",
 	Aliases: []string{"r"},
 	Short:   "Manage registered package repositories",
-	Long:  `Manage GitHub repositories that contain Claude Code packages (skills, commands, agents).`,
+	Long:    `Manage git repositories that contain Claude Code packages (skills, commands, agents).`,
 }
 
 func init() {
@@ -11,23 +11,48 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var pkgRepoAddNamespace string
+var (
+	pkgRepoAddNamespace   string
+	pkgRepoAddUnprefixed  bool
+	pkgRepoAddFullHistory bool
+	pkgRepoAddSparse      bool
+	pkgRepoAddSparsePaths []string
+)
 
 var pkgRepoAddCmd = &cobra.Command{
-	Use:     "add <gh:owner/repo>",
+	Use:     "add <url>",
 	Aliases: []string{"a"},
-	Short:   "Register a GitHub repository",
-	Long: `Register a GitHub repository containing Claude Code packages.
+	Short:   "Register a package repository",
+	Long: `Register a git repository containing Claude Code packages.
 
-The repository URL must be in the format: gh:owner/repo
+Supported repository locations:
+  gh:owner/repo                         GitHub shorthand
+  https://host/group/repo.git           Any HTTPS remote (GitLab, Gitea, ...)
+  ssh://git@host/group/repo.git         SSH remote
+  git@host:group/repo.git               SCP-like SSH remote
+  file:///path/to/repo, ./path/to/repo  Local repository
 
 A namespace will be automatically generated from the owner and repo names
 (first 4 characters of each, joined by a hyphen). You can override this
 with the --namespace flag.
 
+Packages are installed as namespace--name. With --unprefixed, packages from
+this repository are installed under their own names instead; installs still
+refuse to overwrite files they do not own.
+
+Repositories are cloned with only their latest commit; older commits are
+fetched when a package pins one. --full-history clones all history instead.
+--sparse checks out only the package directories (skills, commands, agents,
+hooks and .claude) and fetches other files only when needed, which suits
+large monorepos. --sparse-path checks out other directories as well.
+Use 'jd pkg repo gc' to prune clones and see their disk usage.
+
 Examples:
   jd pkg repo add gh:affaan-m/everything-claude-code
-  jd pkg repo add gh:user/claude-skills --namespace mysk`,
+  jd pkg repo add gh:user/claude-skills --namespace mysk
+  jd pkg repo add git@gitlab.example.com:team/claude-pkgs.git
+  jd pkg repo add ../my-skills --namespace mine --unprefixed
+  jd pkg repo add gh:bigco/monorepo --sparse --sparse-path tools/claude`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPkgRepoAdd,
 }
@@ -35,6 +60,10 @@ Examples:
 func init() {
 	pkgRepoCmd.AddCommand(pkgRepoAddCmd)
 	pkgRepoAddCmd.Flags().StringVarP(&pkgRepoAddNamespace, "namespace", "n", "", "Custom namespace for the repository")
+	pkgRepoAddCmd.Flags().BoolVar(&pkgRepoAddUnprefixed, "unprefixed", false, "Install packages without the namespace prefix")
+	pkgRepoAddCmd.Flags().BoolVar(&pkgRepoAddFullHistory, "full-history", false, "Clone all history instead of the latest commit")
+	pkgRepoAddCmd.Flags().BoolVar(&pkgRepoAddSparse, "sparse", false, "Check out only the package directories")
+	pkgRepoAddCmd.Flags().StringSliceVar(&pkgRepoAddSparsePaths, "sparse-path", nil, "Also check out this directory (implies --sparse; repeatable)")
 }
 
 func runPkgRepoAdd(cmd *cobra.Command, args []string) error {
@@ -42,16 +71,16 @@ func runPkgRepoAdd(cmd *cobra.Command, args []string) error {
 	url := args[0]
 
 	// Parse URL to generate namespace if not provided
-	owner, repoName, err := repo.ParseURL(url)
+	src, err := repo.ParseSource(url)
 	if err != nil {
-		return fmt.Errorf("invalid URL format. Use: gh:owner/repo")
+		return fmt.Errorf("invalid repository URL %q: use gh:owner/repo, a git URL or a local path", url)
 	}
 
 	store := repo.NewStore("~/.itda-skills")
 
 	namespace := pkgRepoAddNamespace
 	if namespace == "" {
-		namespace = repo.GenerateNamespace(owner, repoName)
+		namespace = repo.GenerateNamespace(src.Owner, src.Repo)
 	}
 
 	// Check if namespace exists
@@ -85,9 +114,14 @@ func runPkgRepoAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Printf("Registering %s...\n", url)
+	fmt.Printf("Registering %s...\n", src.WebURL)
+
+	opts := repo.CloneOptions{FullHistory: pkgRepoAddFullHistory}
+	if pkgRepoAddSparse || len(pkgRepoAddSparsePaths) > 0 {
+		opts.SparsePaths = append(append([]string{}, repo.PackageDirs...), pkgRepoAddSparsePaths...)
+	}
 
-	config, err := store.Add(url, namespace)
+	config, err := store.AddWithOptions(url, namespace, opts)
 	if err != nil {
 		if errors.Is(err, repo.ErrNamespaceExists) {
 			return fmt.Errorf("namespace '%s' already exists", namespace)
@@ -95,10 +129,22 @@ func runPkgRepoAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("add repository: %w", err)
 	}
 
+	if pkgRepoAddUnprefixed {
+		if err := store.SetUnprefixed(config.Namespace, true); err != nil {
+			return fmt.Errorf("set unprefixed: %w", err)
+		}
+	}
+
 	fmt.Printf("Repository registered successfully!\n")
 	fmt.Printf("  Namespace:      %s\n", config.Namespace)
 	fmt.Printf("  URL:            %s\n", config.URL)
 	fmt.Printf("  Default Branch: %s\n", config.DefaultBranch)
+	if pkgRepoAddUnprefixed {
+		fmt.Printf("  Unprefixed:     yes\n")
+	}
+	if len(config.SparsePaths) > 0 {
+		fmt.Printf("  Sparse:         %s\n", strings.Join(config.SparsePaths, ", "))
+	}
 	fmt.Println()
 	fmt.Printf("Browse packages: jd pkg browse %s\n", config.Namespace)
 
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/spf13/cobra"
+)
+
+var pkgRepoGCDryRun bool
+
+var pkgRepoGCCmd = &cobra.Command{
+	Use:   "gc [namespace...]",
+	Short: "Prune repository clones and report their disk usage",
+	Long: `Prune unreachable objects from the local clones of registered repositories
+and report the disk usage of each clone before and after.
+
+Commits that installed packages come from (global ~/.claude and the local
+.claude in the current directory) are kept, so pinned packages can still be
+diffed and updated. Commits of other projects' installs may be pruned; they
+are fetched again when needed.
+
+Without namespaces, all repositories are collected. With --dry-run, only
+disk usage is reported.
+
+Examples:
+  jd pkg repo gc
+  jd pkg repo gc affa-ever
+  jd pkg repo gc --dry-run`,
+	RunE: runPkgRepoGC,
+}
+
+func init() {
+	pkgRepoCmd.AddCommand(pkgRepoGCCmd)
+	pkgRepoGCCmd.Flags().BoolVar(&pkgRepoGCDryRun, "dry-run", false, "Only report disk usage")
+}
+
+func runPkgRepoGC(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	store := repo.NewStore("~/.itda-skills")
+
+	repos, err := store.List()
+	if err != nil {
+		return fmt.Errorf("list repositories: %w", err)
+	}
+	namespaces := args
+	if len(namespaces) == 0 {
+		for _, r := range repos {
+			namespaces = append(namespaces, r.Namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		fmt.Println("No repositories registered.")
+		return nil
+	}
+
+	keep, err := installedCommits()
+	if err != nil {
+		return err
+	}
+
+	nsWidth := len("NAMESPACE")
+	for _, ns := range namespaces {
+		if len(ns) > nsWidth {
+			nsWidth = len(ns)
+		}
+	}
+	fmt.Printf("%-*s  %-16s  %10s  %10s\n", nsWidth, "NAMESPACE", "CLONE", "BEFORE", "AFTER")
+	fmt.Printf("%s  %s  %s  %s\n", strings.Repeat("-", nsWidth), strings.Repeat("-", 16), strings.Repeat("-", 10), strings.Repeat("-", 10))
+
+	var totalBefore, totalAfter int64
+	var failed int
+	for _, ns := range namespaces {
+		config, err := store.Get(ns)
+		if err != nil {
+			if errors.Is(err, repo.ErrRepoNotFound) {
+				err = fmt.Errorf("repository '%s' not found", ns)
+			}
+			fmt.Printf("%-*s  %v\n", nsWidth, ns, err)
+			failed++
+			continue
+		}
+
+		before, err := store.DiskUsage(ns)
+		if err != nil {
+			fmt.Printf("%-*s  %v\n", nsWidth, ns, err)
+			failed++
+			continue
+		}
+		after := before
+		if !pkgRepoGCDryRun {
+			if err := store.GC(ns, keep[ns]); err != nil {
+				fmt.Printf("%-*s  gc: %v\n", nsWidth, ns, err)
+				failed++
+				continue
+			}
+			if after, err = store.DiskUsage(ns); err != nil {
+				after = before
+			}
+		}
+		totalBefore += before
+		totalAfter += after
+
+		fmt.Printf("%-*s  %-16s  %10s  %10s\n", nsWidth, ns, cloneMode(store, config), formatBytes(before), formatBytes(after))
+	}
+
+	fmt.Printf("\nTotal: %s", formatBytes(totalBefore))
+	if !pkgRepoGCDryRun {
+		fmt.Printf(" -> %s (freed %s)", formatBytes(totalAfter), formatBytes(totalBefore-totalAfter))
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d repositories could not be collected", failed)
+	}
+	return nil
+}
+
+// installedCommits returns the commits installed packages come from, by
+// namespace, for the global scope and the local scope if there is one.
+func installedCommits() (map[string][]string, error) {
+	scopes := []PathScope{ScopeGlobal}
+	if cwd, err := os.Getwd(); err == nil {
+		if info, err := os.Stat(filepath.Join(cwd, localClaudeDir)); err == nil && info.IsDir() {
+			scopes = append(scopes, ScopeLocal)
+		}
+	}
+
+	keep := make(map[string][]string)
+	for _, scope := range scopes {
+		manager, err := newPkgManager(scope)
+		if err != nil {
+			return nil, err
+		}
+		pkgs, err := manager.List()
+		if err != nil {
+			return nil, fmt.Errorf("list %s packages: %w", scope, err)
+		}
+		for _, pkg := range pkgs {
+			keep[pkg.Namespace] = append(keep[pkg.Namespace], pkg.Version.SHA)
+		}
+	}
+	return keep, nil
+}
+
+// cloneMode describes how a repository is cloned, e.g. "shallow, sparse".
+func cloneMode(store *repo.Store, config *repo.RepoConfig) string {
+	mode := "full"
+	if localPath, err := store.RepoLocalPath(config.Namespace); err == nil && git.IsShallow(localPath) {
+		mode = "shallow"
+	}
+	if len(config.SparsePaths) > 0 {
+		mode += ", sparse"
+	}
+	return mode
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -47,5 +47,8 @@ func runPkgRepoRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Removed repository: %s (%s)\n", namespace, config.URL)
+	if config.Registry != "" {
+		fmt.Printf("Note: registry '%s' lists it and adds it back on its next sync.\n", config.Registry)
+	}
 	return nil
 }
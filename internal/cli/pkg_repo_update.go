@@ -2,49 +2,89 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/itda-skills/jindo/internal/pkg/repo"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pkgRepoUpdateJobs    int
+	pkgRepoUpdateTimeout time.Duration
+)
+
 var pkgRepoUpdateCmd = &cobra.Command{
 	Use:     "update [namespace...]",
 	Aliases: []string{"u", "up"},
 	Short:   "Update registered repositories",
-	Long: `Pull the latest changes for registered repositories.
+	Long: `Pull the latest changes for registered repositories and rebuild
+the package index used by browse and search.
 
 Without arguments, updates all registered repositories.
 With arguments, updates only the specified repositories.
 
+Repositories are pulled in parallel (--jobs at a time), each bounded by
+--timeout. Repositories that fail are listed at the end and the command
+exits with an error. Press Ctrl+C to cancel the remaining pulls.
+
 Examples:
   jd pkg repo update              # Update all
-  jd pkg repo update affa-ever    # Update specific repo`,
+  jd pkg repo update affa-ever    # Update specific repo
+  jd pkg repo update -j 8 --timeout 30s`,
 	RunE: runPkgRepoUpdate,
 }
 
 func init() {
 	pkgRepoCmd.AddCommand(pkgRepoUpdateCmd)
+	pkgRepoUpdateCmd.Flags().IntVarP(&pkgRepoUpdateJobs, "jobs", "j", repo.DefaultFetchWorkers, "Number of repositories to pull at once")
+	pkgRepoUpdateCmd.Flags().DurationVar(&pkgRepoUpdateTimeout, "timeout", repo.DefaultFetchTimeout, "Timeout per repository")
 }
 
 func runPkgRepoUpdate(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 	store := repo.NewStore("~/.itda-skills")
 
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	opts := repo.FetchOptions{Workers: pkgRepoUpdateJobs, Timeout: pkgRepoUpdateTimeout}
+
+	var results []repo.RepoResult
+	var err error
 	if len(args) == 0 {
-		// Update all
 		fmt.Println("Updating all repositories...")
-		return store.UpdateAll()
+		results, err = store.UpdateAll(ctx, opts)
+	} else {
+		results, err = store.UpdateRepos(ctx, args, opts)
+	}
+	if err != nil {
+		return err
 	}
 
-	// Update specific repos
-	for _, namespace := range args {
-		fmt.Printf("Updating %s...\n", namespace)
-		if err := store.Update(namespace); err != nil {
-			fmt.Printf("  Error: %v\n", err)
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("  %-20s FAILED\n", r.Namespace)
 			continue
 		}
-		fmt.Println("  Done")
+		fmt.Printf("  %-20s updated (%s)\n", r.Namespace, r.Duration.Round(time.Millisecond))
 	}
 
-	return nil
+	return repoFailureSummary("update", results)
+}
+
+// repoFailureSummary prints the repositories that failed and returns an
+// error counting them, or nil if all succeeded.
+func repoFailureSummary(action string, results []repo.RepoResult) error {
+	failed := repo.FailedResults(results)
+	if len(failed) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nFailed to %s %d of %d repositories:\n", action, len(failed), len(results))
+	for _, r := range failed {
+		fmt.Printf("  %s: %v\n", r.Namespace, r.Err)
+	}
+	return fmt.Errorf("%d repositories failed to %s", len(failed), action)
 }
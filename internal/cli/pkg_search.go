@@ -16,9 +16,11 @@ var pkgSearchCmd = &cobra.Command{
 	Use:     "search <query>",
 	Aliases: []string{"se"},
 	Short:   "Search for packages across all registered repositories",
-	Long: `Search for packages by name across all registered repositories.
+	Long: `Search for packages across all registered repositories.
 
-The search is case-insensitive and matches package names containing the query.
+The search is case-insensitive and matches package names, descriptions and
+tags containing the query. It reads the local package index, so it works
+offline; run 'jd pkg repo update' to refresh it.
 
 Examples:
   jd pkg search web
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkgStatusJSON   bool
+	pkgStatusGlobal bool
+	pkgStatusLocal  bool
+)
+
+var pkgStatusCmd = &cobra.Command{
+	Use:     "status [name...]",
+	Aliases: []string{"st"},
+	Short:   "Show local changes to installed packages",
+	Long: `Compare installed package files with the content recorded at install time.
+
+Reports files that were modified, files that are missing, and files that were
+added to an installed skill directory. Packages with local edits are skipped
+by 'jd pkg update --apply' unless --backup or --force is used.
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+Examples:
+  jd pkg status
+  jd pkg status affa-ever--web-fetch
+  jd pkg status --json`,
+	RunE: runPkgStatus,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgStatusCmd)
+	pkgStatusCmd.Flags().BoolVar(&pkgStatusJSON, "json", false, "Output in JSON format")
+	pkgStatusCmd.Flags().BoolVarP(&pkgStatusGlobal, "global", "g", false, "Check packages in global ~/.claude")
+	pkgStatusCmd.Flags().BoolVarP(&pkgStatusLocal, "local", "l", false, "Check packages in local .claude")
+}
+
+func runPkgStatus(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	manager, scope, err := resolvePkgManager(pkgStatusGlobal, pkgStatusLocal)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := manager.Status(args...)
+	if err != nil {
+		return fmt.Errorf("status: %w", err)
+	}
+
+	if pkgStatusJSON {
+		output, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("No packages installed in %s.\n", ScopeDescription(scope))
+		return nil
+	}
+
+	printPkgStatuses(statuses)
+	return nil
+}
+
+// printPkgStatuses prints one line per package followed by changed files.
+func printPkgStatuses(statuses []pkgmgr.PackageStatus) {
+	for _, s := range statuses {
+		state := "clean"
+		switch {
+		case !s.IsClean():
+			state = "changed"
+		case s.Untracked:
+			state = "untracked (installed without hashes, reinstall to track)"
+		}
+		fmt.Printf("%s: %s\n", s.Package.Name, state)
+
+		for _, f := range s.Modified {
+			fmt.Printf("  modified: %s\n", f)
+		}
+		for _, f := range s.Missing {
+			fmt.Printf("  missing:  %s\n", f)
+		}
+		for _, f := range s.Extra {
+			fmt.Printf("  extra:    %s\n", f)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkgSyncFrozen bool
+	pkgSyncForce  bool
+	pkgSyncLocal  bool
+)
+
+var pkgSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install exactly the packages listed in the project lockfile",
+	Long: `Make installed packages match .claude/jd-lock.json in the current directory.
+
+Sync registers missing repositories, installs locked packages at their exact
+commits, reinstalls packages installed at a different commit or whose files
+were edited, and then removes packages that are not listed in the lockfile.
+Installed content is verified against the recorded hashes before it replaces
+anything. Sync refuses to overwrite files that no package owns, or that
+belong to a package the lockfile keeps; use --force to overwrite them.
+
+With --frozen, nothing is changed and the command fails if anything would
+change. Use it in CI to check that the lockfile is satisfied.
+
+Packages are synced into the local .claude directory, next to the lockfile.
+
+Examples:
+  jd pkg sync
+  jd pkg sync --frozen
+  jd pkg sync --force`,
+	Args: cobra.NoArgs,
+	RunE: runPkgSync,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgSyncCmd)
+	pkgSyncCmd.Flags().BoolVar(&pkgSyncFrozen, "frozen", false, "Fail instead of changing anything if packages differ from the lockfile")
+	pkgSyncCmd.Flags().BoolVarP(&pkgSyncForce, "force", "f", false, "Overwrite conflicting files")
+	pkgSyncCmd.Flags().BoolVarP(&pkgSyncLocal, "local", "l", false, "Sync packages into local .claude")
+}
+
+func runPkgSync(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	manager, lockPath, err := resolveLockManager(pkgSyncLocal)
+	if err != nil {
+		return err
+	}
+
+	lock, err := pkgmgr.ReadLockFile(lockPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("lockfile not found: %s. Create one with: jd pkg lock", lockPath)
+		}
+		return err
+	}
+
+	plan, err := manager.PlanSync(lock)
+	if err != nil {
+		return fmt.Errorf("plan sync: %w", err)
+	}
+
+	if plan.IsEmpty() {
+		fmt.Println("Packages are in sync with the lockfile.")
+		return nil
+	}
+
+	printSyncPlan(plan)
+
+	if pkgSyncFrozen {
+		return pkgmgr.ErrLockOutOfSync
+	}
+
+	fmt.Println()
+	fmt.Println("Syncing...")
+	if err := manager.ApplySync(plan, pkgSyncForce); err != nil {
+		var conflictErr *pkgmgr.ConflictError
+		if errors.As(err, &conflictErr) {
+			printInstallConflicts(conflictErr.Conflicts)
+			return fmt.Errorf("sync cancelled: %d conflict(s); use --force to overwrite", len(conflictErr.Conflicts))
+		}
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	fmt.Println("Packages are in sync with the lockfile.")
+	return nil
+}
+
+// printSyncPlan prints the changes a sync would make.
+func printSyncPlan(plan *pkgmgr.SyncPlan) {
+	for _, r := range plan.AddRepos {
+		fmt.Printf("  + repo    %s (%s)\n", r.Namespace, r.URL)
+	}
+	for _, p := range plan.Install {
+		fmt.Printf("  + install %s @ %s\n", p.Name, shortSHA(p.Version.SHA))
+	}
+	for _, p := range plan.Reinstall {
+		fmt.Printf("  ~ update  %s -> %s\n", p.Name, shortSHA(p.Version.SHA))
+	}
+	for _, p := range plan.Remove {
+		fmt.Printf("  - remove  %s\n", p.Name)
+	}
+}
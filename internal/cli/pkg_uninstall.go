@@ -1,13 +1,22 @@
 package cli
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pkgUninstallGlobal bool
+	pkgUninstallLocal  bool
+	pkgUninstallPrune  bool
+)
+
 var pkgUninstallCmd = &cobra.Command{
 	Use:     "uninstall <name>",
 	Aliases: []string{"un", "rm", "remove"},
@@ -15,36 +24,98 @@ var pkgUninstallCmd = &cobra.Command{
 	Long: `Uninstall a package by its installed name.
 
 Use 'jd pkg list' to see installed package names.
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
 
-Example:
-  jd pkg uninstall affa-ever--web-fetch`,
+Packages that were installed only as dependencies and are no longer required
+are offered for removal afterwards. Use --prune to remove them without asking.
+
+Examples:
+  jd pkg uninstall affa-ever--web-fetch
+  jd pkg uninstall --local affa-ever--web-fetch
+  jd pkg uninstall --prune affa-ever--web-fetch`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPkgUninstall,
 }
 
 func init() {
 	pkgCmd.AddCommand(pkgUninstallCmd)
+	pkgUninstallCmd.Flags().BoolVarP(&pkgUninstallGlobal, "global", "g", false, "Uninstall from global ~/.claude")
+	pkgUninstallCmd.Flags().BoolVarP(&pkgUninstallLocal, "local", "l", false, "Uninstall from local .claude")
+	pkgUninstallCmd.Flags().BoolVar(&pkgUninstallPrune, "prune", false, "Remove orphaned dependencies without asking")
 }
 
 func runPkgUninstall(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 	name := args[0]
 
-	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager, scope, err := resolvePkgManager(pkgUninstallGlobal, pkgUninstallLocal)
+	if err != nil {
+		return err
+	}
 
 	// Get package info first for display
 	pkg, err := manager.Get(name)
 	if err != nil {
 		if errors.Is(err, pkgmgr.ErrPackageNotFound) {
-			return fmt.Errorf("package '%s' not found. Use 'jd pkg list' to see installed packages", name)
+			return fmt.Errorf("package '%s' not found in %s. Use 'jd pkg list' to see installed packages", name, ScopeDescription(scope))
 		}
 		return fmt.Errorf("get package: %w", err)
 	}
 
+	dependents, err := manager.Dependents(name)
+	if err != nil {
+		return fmt.Errorf("check dependents: %w", err)
+	}
+	if len(dependents) > 0 {
+		fmt.Printf("Warning: %s is required by %s\n", name, strings.Join(dependents, ", "))
+	}
+
 	if err := manager.Uninstall(name); err != nil {
 		return fmt.Errorf("uninstall: %w", err)
 	}
 
 	fmt.Printf("Uninstalled: %s (%s)\n", pkg.Name, pkg.Type)
+
+	return pruneOrphans(manager)
+}
+
+// pruneOrphans offers to remove dependencies no installed package requires anymore.
+func pruneOrphans(manager *pkgmgr.Manager) error {
+	orphans, err := manager.Orphans()
+	if err != nil {
+		return fmt.Errorf("find orphaned dependencies: %w", err)
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nDependencies no longer required:")
+	for _, o := range orphans {
+		fmt.Printf("  %s (%s)\n", o.Name, o.Type)
+	}
+
+	if !pkgUninstallPrune {
+		fmt.Print("Remove them? (y/N): ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			return nil
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return nil
+		}
+	}
+
+	for _, o := range orphans {
+		if err := manager.Uninstall(o.Name); err != nil {
+			return fmt.Errorf("uninstall %s: %w", o.Name, err)
+		}
+		fmt.Printf("Uninstalled: %s (%s)\n", o.Name, o.Type)
+	}
 	return nil
 }
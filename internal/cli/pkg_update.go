@@ -1,14 +1,36 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/pkg/config"
 	"github.com/spf13/cobra"
 )
 
-var pkgUpdateApply bool
+// pkgFetchMaxAgeKey is the config key for the default update staleness window.
+const pkgFetchMaxAgeKey = "pkg.fetch_max_age"
+
+var (
+	pkgUpdateApply          bool
+	pkgUpdateReview         bool
+	pkgUpdateForce          bool
+	pkgUpdateForceConflicts bool
+	pkgUpdateBackup         bool
+	pkgUpdateGlobal         bool
+	pkgUpdateLocal          bool
+	pkgUpdateMaxAge         time.Duration
+	pkgUpdateOffline        bool
+	pkgUpdateJobs           int
+	pkgUpdateTimeout        time.Duration
+)
 
 var pkgUpdateCmd = &cobra.Command{
 	Use:     "update [name...]",
@@ -18,33 +40,112 @@ var pkgUpdateCmd = &cobra.Command{
 
 Without --apply, shows available updates.
 With --apply, downloads and installs updates.
+With --review, shows the diff of each update (see 'jd pkg diff') and asks
+whether to apply or skip it.
+
+Packages installed at a tag are only offered newer semver tags.
+Packages pinned to a commit SHA are never updated.
+
+Packages whose installed files were edited locally are skipped.
+Use --backup to save the edited files to .history/packages/ and update,
+or --force to overwrite them. See 'jd pkg status' for local changes.
+
+Updates that would overwrite files no package owns, or files of other
+packages, fail with a conflict. Use --force-conflicts to overwrite them.
+
+Each repository is fetched once, in parallel (--jobs at a time, each
+bounded by --timeout), before checking. Repositories or packages that
+cannot be checked are listed and the command exits with an error.
+
+Use --max-age to reuse refs fetched within a window (e.g. 1h), or
+--offline to never fetch. The default window comes from the
+pkg.fetch_max_age config key (ITDA_PKG_FETCH_MAX_AGE).
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
 
 Examples:
   jd pkg update                    # Check all packages
   jd pkg update affa-ever--web-fetch  # Check specific package
-  jd pkg update --apply            # Apply all updates`,
+  jd pkg update --apply            # Apply all updates
+  jd pkg update --apply --backup   # Back up local edits, then update
+  jd pkg update --review           # Review each diff, then apply or skip
+  jd pkg update --max-age 6h       # Skip repos fetched in the last 6 hours
+  jd pkg update --offline          # Check against local clones only`,
 	RunE: runPkgUpdate,
 }
 
 func init() {
 	pkgCmd.AddCommand(pkgUpdateCmd)
 	pkgUpdateCmd.Flags().BoolVar(&pkgUpdateApply, "apply", false, "Apply available updates")
+	pkgUpdateCmd.Flags().BoolVar(&pkgUpdateReview, "review", false, "Show each update's diff and ask before applying it")
+	pkgUpdateCmd.Flags().BoolVarP(&pkgUpdateForce, "force", "f", false, "Overwrite locally modified files")
+	pkgUpdateCmd.Flags().BoolVar(&pkgUpdateForceConflicts, "force-conflicts", false, "Overwrite files owned by other packages or by no package")
+	pkgUpdateCmd.Flags().BoolVar(&pkgUpdateBackup, "backup", false, "Back up locally modified files before updating")
+	pkgUpdateCmd.Flags().BoolVarP(&pkgUpdateGlobal, "global", "g", false, "Update packages in global ~/.claude")
+	pkgUpdateCmd.Flags().BoolVarP(&pkgUpdateLocal, "local", "l", false, "Update packages in local .claude")
+	pkgUpdateCmd.Flags().DurationVar(&pkgUpdateMaxAge, "max-age", 0, "Skip fetching repositories fetched within this duration")
+	pkgUpdateCmd.Flags().BoolVar(&pkgUpdateOffline, "offline", false, "Check against local clones without fetching")
+	pkgUpdateCmd.Flags().IntVarP(&pkgUpdateJobs, "jobs", "j", repo.DefaultFetchWorkers, "Number of repositories to fetch at once")
+	pkgUpdateCmd.Flags().DurationVar(&pkgUpdateTimeout, "timeout", repo.DefaultFetchTimeout, "Fetch timeout per repository")
+}
+
+// pkgFetchMaxAge returns the staleness window for update checks from the
+// flags, falling back to the pkg.fetch_max_age config key.
+func pkgFetchMaxAge(cmd *cobra.Command, offline bool, maxAge time.Duration) (time.Duration, error) {
+	if offline {
+		return pkgmgr.NeverFetch, nil
+	}
+	if cmd.Flags().Changed("max-age") {
+		return maxAge, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, fmt.Errorf("load config: %w", err)
+	}
+	value, found := cfg.GetWithEnv(pkgFetchMaxAgeKey)
+	if !found {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(fmt.Sprint(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", pkgFetchMaxAgeKey, err)
+	}
+	return d, nil
 }
 
 func runPkgUpdate(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
-	manager := pkgmgr.NewManager("~/.itda-skills")
+	manager, scope, err := resolvePkgManager(pkgUpdateGlobal, pkgUpdateLocal)
+	if err != nil {
+		return err
+	}
+
+	maxAge, err := pkgFetchMaxAge(cmd, pkgUpdateOffline, pkgUpdateMaxAge)
+	if err != nil {
+		return err
+	}
+	manager.SetFetchMaxAge(maxAge)
+
+	fmt.Printf("Checking for updates in %s...\n", ScopeDescription(scope))
 
-	fmt.Println("Checking for updates...")
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	manager.SetFetchOptions(repo.FetchOptions{Workers: pkgUpdateJobs, Timeout: pkgUpdateTimeout})
 
-	updates, err := manager.CheckUpdates(args...)
+	report, err := manager.CheckUpdatesContext(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("check updates: %w", err)
 	}
+	updates := report.Updates
+	checkErr := printCheckFailures(report.Failures)
 
 	if len(updates) == 0 {
-		fmt.Println("No packages to check.")
-		return nil
+		if checkErr == nil {
+			fmt.Println("No packages to check.")
+		}
+		return checkErr
 	}
 
 	// Count packages with updates
@@ -57,7 +158,7 @@ func runPkgUpdate(cmd *cobra.Command, args []string) error {
 
 	if updateCount == 0 {
 		fmt.Println("All packages are up to date.")
-		return nil
+		return checkErr
 	}
 
 	// Calculate column widths
@@ -73,17 +174,10 @@ func runPkgUpdate(cmd *cobra.Command, args []string) error {
 		if len(u.Package.Name) > nameWidth {
 			nameWidth = len(u.Package.Name)
 		}
-		current := u.CurrentSHA
-		if len(current) > 8 {
-			current = current[:8]
-		}
+		current, latest := updateVersions(u)
 		if len(current) > currentWidth {
 			currentWidth = len(current)
 		}
-		latest := u.LatestSHA
-		if len(latest) > 8 {
-			latest = latest[:8]
-		}
 		if len(latest) > latestWidth {
 			latestWidth = len(latest)
 		}
@@ -97,11 +191,11 @@ func runPkgUpdate(cmd *cobra.Command, args []string) error {
 	if nameWidth > 35 {
 		nameWidth = 35
 	}
-	if currentWidth > 12 {
-		currentWidth = 12
+	if currentWidth > 20 {
+		currentWidth = 20
 	}
-	if latestWidth > 12 {
-		latestWidth = 12
+	if latestWidth > 20 {
+		latestWidth = 20
 	}
 	if changesWidth > 15 {
 		changesWidth = 15
@@ -132,15 +226,7 @@ func runPkgUpdate(cmd *cobra.Command, args []string) error {
 			name = name[:nameWidth-3] + "..."
 		}
 
-		current := u.CurrentSHA
-		if len(current) > 8 {
-			current = current[:8]
-		}
-
-		latest := u.LatestSHA
-		if len(latest) > 8 {
-			latest = latest[:8]
-		}
+		current, latest := updateVersions(u)
 
 		changes := fmt.Sprintf("%d files", len(u.ChangedFiles))
 
@@ -151,33 +237,140 @@ func runPkgUpdate(cmd *cobra.Command, args []string) error {
 			changesWidth, changes)
 	}
 
-	if !pkgUpdateApply {
+	if !pkgUpdateApply && !pkgUpdateReview {
 		fmt.Println()
-		fmt.Println("Run with --apply to install updates:")
+		fmt.Println("Run with --apply to install updates, or --review to see the changes first:")
 		fmt.Println("  jd pkg update --apply")
-		return nil
+		return checkErr
 	}
 
-	// Apply updates
+	var selected []pkgmgr.UpdateInfo
+	for _, u := range updates {
+		if u.HasUpdate {
+			selected = append(selected, u)
+		}
+	}
+	if pkgUpdateReview {
+		selected, err = reviewUpdates(manager, selected)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			fmt.Println("\nNo updates selected.")
+			return checkErr
+		}
+	}
+
+	// Apply updates from the refs fetched above
+	manager.SetFetchMaxAge(pkgmgr.NeverFetch)
 	fmt.Println()
 	fmt.Println("Applying updates...")
 
 	successCount := 0
-	for _, u := range updates {
-		if !u.HasUpdate {
-			continue
+	for _, u := range selected {
+		fmt.Printf("  Updating %s... ", u.Package.Name)
+
+		// Install the commit that was listed or reviewed, not a newer one
+		opts := pkgmgr.UpdateOptions{OverwriteLocal: pkgUpdateForce, ForceConflicts: pkgUpdateForceConflicts, SHA: u.LatestSHA}
+		backupDir := ""
+		if pkgUpdateBackup {
+			backupDir, err = manager.Backup(u.Package.Name)
+			if err != nil {
+				fmt.Printf("FAILED: backup: %v\n", err)
+				continue
+			}
+			// The backup only covers this package's files, so conflicts are still checked
+			opts.OverwriteLocal = true
 		}
 
-		fmt.Printf("  Updating %s... ", u.Package.Name)
-		_, err := manager.Update(u.Package.Name)
+		_, err := manager.Update(u.Package.Name, opts)
 		if err != nil {
+			if errors.Is(err, pkgmgr.ErrLocalModifications) {
+				fmt.Printf("SKIPPED: %v (use --backup or --force)\n", err)
+				continue
+			}
+			if errors.Is(err, pkgmgr.ErrUpdateMoved) {
+				fmt.Printf("SKIPPED: %v (check again)\n", err)
+				continue
+			}
+			if errors.Is(err, pkgmgr.ErrConflict) {
+				fmt.Printf("SKIPPED: %v (use --force-conflicts)\n", err)
+				continue
+			}
 			fmt.Printf("FAILED: %v\n", err)
 			continue
 		}
 		fmt.Println("OK")
+		if backupDir != "" {
+			fmt.Printf("    Local changes backed up to %s\n", backupDir)
+		}
 		successCount++
 	}
 
-	fmt.Printf("\nUpdated %d of %d packages.\n", successCount, updateCount)
-	return nil
+	fmt.Printf("\nUpdated %d of %d packages.\n", successCount, len(selected))
+	return checkErr
+}
+
+// reviewUpdates shows the diff of each update and asks whether to apply
+// it. It returns the accepted updates; answering q skips the rest.
+func reviewUpdates(manager *pkgmgr.Manager, updates []pkgmgr.UpdateInfo) ([]pkgmgr.UpdateInfo, error) {
+	reader := bufio.NewReader(os.Stdin)
+
+	var accepted []pkgmgr.UpdateInfo
+	for i, u := range updates {
+		diff, err := manager.DiffUpdate(u)
+		if err != nil {
+			return nil, fmt.Errorf("diff %s: %w", u.Package.Name, err)
+		}
+
+		fmt.Printf("\n[%d/%d] ", i+1, len(updates))
+		printPkgDiff(diff)
+
+		fmt.Printf("\nApply update to %s? (y/N/q): ", u.Package.Name)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			break
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response == "q" || response == "quit" {
+			break
+		}
+		if response == "y" || response == "yes" {
+			accepted = append(accepted, u)
+		}
+	}
+	return accepted, nil
+}
+
+// printCheckFailures prints repositories and packages whose updates could
+// not be checked and returns an error counting them, or nil if there were none.
+func printCheckFailures(failures []pkgmgr.CheckFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nCould not check %d repositories or packages:\n", len(failures))
+	for _, f := range failures {
+		if f.Package != "" && f.Namespace != "" {
+			fmt.Printf("  %s (%s): %v\n", f.Package, f.Namespace, f.Err)
+			continue
+		}
+		if f.Package != "" {
+			fmt.Printf("  %s: %v\n", f.Package, f.Err)
+			continue
+		}
+		fmt.Printf("  %s: %v\n", f.Namespace, f.Err)
+	}
+	fmt.Println()
+	return fmt.Errorf("%d update checks failed", len(failures))
+}
+
+// updateVersions returns the current and latest version columns.
+// Tag-pinned packages show tag names, others abbreviated commit SHAs.
+func updateVersions(u pkgmgr.UpdateInfo) (current, latest string) {
+	if u.Package.Version.Type == pkgmgr.VersionTypeTag {
+		return u.Package.Version.Ref, u.LatestRef
+	}
+	return shortSHA(u.CurrentSHA), shortSHA(u.LatestSHA)
 }
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkgVerifyGlobal bool
+	pkgVerifyLocal  bool
+)
+
+var pkgVerifyCmd = &cobra.Command{
+	Use:   "verify [name...]",
+	Short: "Verify installed packages against recorded content hashes",
+	Long: `Verify that installed package files match the content recorded at install time.
+
+Exits with an error if any file was modified, removed, or added to a skill
+directory. Use 'jd pkg status' for a non-failing report.
+
+Default scope is local if a .claude directory exists in the current working directory, otherwise global.
+Use --global or --local to override.
+
+Examples:
+  jd pkg verify
+  jd pkg verify affa-ever--web-fetch`,
+	RunE: runPkgVerify,
+}
+
+func init() {
+	pkgCmd.AddCommand(pkgVerifyCmd)
+	pkgVerifyCmd.Flags().BoolVarP(&pkgVerifyGlobal, "global", "g", false, "Verify packages in global ~/.claude")
+	pkgVerifyCmd.Flags().BoolVarP(&pkgVerifyLocal, "local", "l", false, "Verify packages in local .claude")
+}
+
+func runPkgVerify(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	manager, _, err := resolvePkgManager(pkgVerifyGlobal, pkgVerifyLocal)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := manager.Status(args...)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	failed := 0
+	for _, s := range statuses {
+		if !s.IsClean() {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		fmt.Printf("Verified %d package(s): OK\n", len(statuses))
+		return nil
+	}
+
+	printPkgStatuses(statuses)
+	return fmt.Errorf("%d of %d package(s) failed verification", failed, len(statuses))
+}
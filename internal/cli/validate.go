@@ -147,37 +147,7 @@ func validateSkills(result *ValidationResult) error {
 			continue
 		}
 
-		// Check required fields
-		if s.Name == "" {
-			result.Warnings = append(result.Warnings, ValidationError{
-				Type:    "skill",
-				Name:    name,
-				Path:    s.Path,
-				Message: "missing 'name' in frontmatter (using directory name)",
-			})
-		}
-
-		if s.Description == "" {
-			result.Warnings = append(result.Warnings, ValidationError{
-				Type:    "skill",
-				Name:    name,
-				Path:    s.Path,
-				Message: "missing 'description' in frontmatter",
-			})
-		}
-
-		// Check allowed-tools
-		for _, tool := range s.AllowedTools {
-			tool = strings.TrimSpace(tool)
-			if tool != "" && !validTools[tool] {
-				result.Warnings = append(result.Warnings, ValidationError{
-					Type:    "skill",
-					Name:    name,
-					Path:    s.Path,
-					Message: fmt.Sprintf("unknown tool in allowed-tools: %s", tool),
-				})
-			}
-		}
+		checkSkill(result, name, s)
 
 		if validateVerbose {
 			fmt.Printf("  [OK] skill: %s\n", name)
@@ -200,15 +170,7 @@ func validateCommands(result *ValidationResult) error {
 	for _, cmd := range commands {
 		result.Checked++
 
-		// Check required fields
-		if cmd.Description == "" {
-			result.Warnings = append(result.Warnings, ValidationError{
-				Type:    "command",
-				Name:    cmd.Name,
-				Path:    cmd.Path,
-				Message: "missing 'description' in frontmatter",
-			})
-		}
+		checkCommand(result, cmd)
 
 		if validateVerbose {
 			fmt.Printf("  [OK] command: %s\n", cmd.Name)
@@ -231,40 +193,94 @@ func validateAgents(result *ValidationResult) error {
 	for _, a := range agents {
 		result.Checked++
 
-		// Check required fields
-		if a.Name == "" {
-			result.Warnings = append(result.Warnings, ValidationError{
-				Type:    "agent",
-				Name:    filepath.Base(a.Path),
-				Path:    a.Path,
-				Message: "missing 'name' in frontmatter (using filename)",
-			})
-		}
+		checkAgent(result, a)
 
-		if a.Description == "" {
-			result.Warnings = append(result.Warnings, ValidationError{
-				Type:    "agent",
-				Name:    a.Name,
-				Path:    a.Path,
-				Message: "missing 'description' in frontmatter",
-			})
+		if validateVerbose {
+			fmt.Printf("  [OK] agent: %s\n", a.Name)
 		}
+	}
+
+	return nil
+}
+
+// checkSkill adds warnings for missing frontmatter fields and unknown
+// allowed-tools of a parsed skill.
+func checkSkill(result *ValidationResult, name string, s *skill.Skill) {
+	// Check required fields
+	if s.Name == "" {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Type:    "skill",
+			Name:    name,
+			Path:    s.Path,
+			Message: "missing 'name' in frontmatter (using directory name)",
+		})
+	}
+
+	if s.Description == "" {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Type:    "skill",
+			Name:    name,
+			Path:    s.Path,
+			Message: "missing 'description' in frontmatter",
+		})
+	}
 
-		if a.Model == "" {
+	// Check allowed-tools
+	for _, tool := range s.AllowedTools {
+		tool = strings.TrimSpace(tool)
+		if tool != "" && !validTools[tool] {
 			result.Warnings = append(result.Warnings, ValidationError{
-				Type:    "agent",
-				Name:    a.Name,
-				Path:    a.Path,
-				Message: "missing 'model' in frontmatter",
+				Type:    "skill",
+				Name:    name,
+				Path:    s.Path,
+				Message: fmt.Sprintf("unknown tool in allowed-tools: %s", tool),
 			})
 		}
+	}
+}
 
-		if validateVerbose {
-			fmt.Printf("  [OK] agent: %s\n", a.Name)
-		}
+// checkCommand adds warnings for missing frontmatter fields of a parsed command.
+func checkCommand(result *ValidationResult, cmd *command.Command) {
+	// Check required fields
+	if cmd.Description == "" {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Type:    "command",
+			Name:    cmd.Name,
+			Path:    cmd.Path,
+			Message: "missing 'description' in frontmatter",
+		})
+	}
+}
+
+// checkAgent adds warnings for missing frontmatter fields of a parsed agent.
+func checkAgent(result *ValidationResult, a *agent.Agent) {
+	// Check required fields
+	if a.Name == "" {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Type:    "agent",
+			Name:    filepath.Base(a.Path),
+			Path:    a.Path,
+			Message: "missing 'name' in frontmatter (using filename)",
+		})
 	}
 
-	return nil
+	if a.Description == "" {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Type:    "agent",
+			Name:    a.Name,
+			Path:    a.Path,
+			Message: "missing 'description' in frontmatter",
+		})
+	}
+
+	if a.Model == "" {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Type:    "agent",
+			Name:    a.Name,
+			Path:    a.Path,
+			Message: "missing 'model' in frontmatter",
+		})
+	}
 }
 
 func printValidationResults(result *ValidationResult) {
@@ -0,0 +1,173 @@
+package hook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EventType represents the type of hook event
+type EventType string
+
+const (
+	PreToolUse       EventType = "PreToolUse"
+	PostToolUse      EventType = "PostToolUse"
+	Notification     EventType = "Notification"
+	UserPromptSubmit EventType = "UserPromptSubmit"
+	Stop             EventType = "Stop"
+	SubagentStop     EventType = "SubagentStop"
+	PreCompact       EventType = "PreCompact"
+	SessionStart     EventType = "SessionStart"
+	SessionEnd       EventType = "SessionEnd"
+)
+
+// MatcherKind describes what an event's matcher is matched against.
+type MatcherKind int
+
+const (
+	// MatcherNone means the event ignores matchers.
+	MatcherNone MatcherKind = iota
+	// MatcherTool means the matcher selects tool names, e.g. "Bash" or "Edit|Write".
+	MatcherTool
+	// MatcherValue means the matcher selects one of the event's MatcherValues,
+	// e.g. SessionStart's "startup|resume".
+	MatcherValue
+)
+
+// EventInfo describes a hook event.
+type EventInfo struct {
+	Type          EventType
+	Aliases       []string // short names accepted by ParseEventType; the first is shown in help
+	Description   string
+	Matcher       MatcherKind
+	MatcherValues []string // values a MatcherValue matcher can select, in documentation order
+	MatchField    string   // event payload field the matcher is matched against
+}
+
+// events describes all known events, in the order they are presented.
+var events = []EventInfo{
+	{Type: PreToolUse, Aliases: []string{"pre"}, Description: "Runs before a tool is executed", Matcher: MatcherTool, MatchField: "tool_name"},
+	{Type: PostToolUse, Aliases: []string{"post"}, Description: "Runs after a tool is executed", Matcher: MatcherTool, MatchField: "tool_name"},
+	{Type: Notification, Aliases: []string{"notify", "notif"}, Description: "Runs on notifications",
+		Matcher: MatcherValue, MatcherValues: []string{"permission_prompt", "idle_prompt", "auth_success", "elicitation_dialog"}, MatchField: "notification_type"},
+	{Type: UserPromptSubmit, Aliases: []string{"prompt", "submit"}, Description: "Runs when the user submits a prompt, before Claude processes it"},
+	{Type: Stop, Description: "Runs when Claude stops"},
+	{Type: SubagentStop, Aliases: []string{"sub", "subagent"}, Description: "Runs when a subagent stops"},
+	{Type: PreCompact, Aliases: []string{"compact"}, Description: "Runs before the conversation is compacted",
+		Matcher: MatcherValue, MatcherValues: []string{"manual", "auto"}, MatchField: "trigger"},
+	{Type: SessionStart, Aliases: []string{"start", "session-start"}, Description: "Runs when a session starts or resumes",
+		Matcher: MatcherValue, MatcherValues: []string{"startup", "resume", "clear", "compact"}, MatchField: "source"},
+	{Type: SessionEnd, Aliases: []string{"end", "session-end"}, Description: "Runs when a session ends"},
+}
+
+// AllEventTypes returns all valid event types
+func AllEventTypes() []EventType {
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+// AllEvents returns descriptions of all valid event types.
+func AllEvents() []EventInfo {
+	return append([]EventInfo(nil), events...)
+}
+
+// LookupEvent returns the description of an event type.
+func LookupEvent(et EventType) (EventInfo, bool) {
+	for _, e := range events {
+		if e.Type == et {
+			return e, true
+		}
+	}
+	return EventInfo{}, false
+}
+
+// EventTypeNames returns all valid event type names as strings (for CLI completion)
+func EventTypeNames() []string {
+	types := AllEventTypes()
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// EventCompletions returns event names with descriptions for shell completion.
+func EventCompletions() []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = fmt.Sprintf("%s\t%s", e.Type, e.Description)
+	}
+	return out
+}
+
+// ParseEventType parses a string to EventType with alias support
+// Accepts: full name (PreToolUse), lowercase (pretooluse), or alias (pre)
+func ParseEventType(s string) (EventType, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	for _, e := range events {
+		if lower == strings.ToLower(string(e.Type)) {
+			return e.Type, nil
+		}
+		for _, a := range e.Aliases {
+			if lower == a {
+				return e.Type, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("invalid event type: %s\nValid types: %s", s, eventUsage())
+}
+
+// eventUsage lists the event types with their first alias, e.g.
+// "PreToolUse(pre), PostToolUse(post), Stop".
+func eventUsage() string {
+	parts := make([]string, len(events))
+	for i, e := range events {
+		parts[i] = string(e.Type)
+		if len(e.Aliases) > 0 {
+			parts[i] += "(" + e.Aliases[0] + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// MatcherHint describes what a matcher selects for an event, for prompts
+// and help output.
+func (e EventInfo) MatcherHint() string {
+	switch e.Matcher {
+	case MatcherTool:
+		return `tool names or a regex, e.g. Bash, "Edit|Write", "mcp__github__.*", * (all tools)`
+	case MatcherValue:
+		return fmt.Sprintf("%s, or empty for all", strings.Join(e.MatcherValues, "|"))
+	default:
+		return "not used"
+	}
+}
+
+// MatcherCompletions returns suggested matchers for shell completion.
+func (e EventInfo) MatcherCompletions() []string {
+	switch e.Matcher {
+	case MatcherTool:
+		out := make([]string, 0, len(tools)+2)
+		for _, t := range tools {
+			out = append(out, t.Name+"\t"+t.Description)
+		}
+		return append(out, "mcp__\tMCP tools (mcp__<server>__<tool>)", "*\tAll tools")
+	case MatcherValue:
+		return append([]string(nil), e.MatcherValues...)
+	default:
+		return nil
+	}
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -21,10 +21,20 @@ type Version struct {
 
 // HookSnapshot represents a saved hook configuration
 type HookSnapshot struct {
-	Name      string    `json:"name"`
-	EventType EventType `json:"event_type"`
-	Matcher   string    `json:"matcher"`
-	Commands  []string  `json:"commands"`
+	Name      string        `json:"name"`
+	EventType EventType     `json:"event_type"`
+	Matcher   string        `json:"matcher"`
+	Commands  []string      `json:"commands"`
+	Hooks     []HookCommand `json:"hooks,omitempty"` // full entries; absent in snapshots saved before timeouts were kept
+}
+
+// Entries returns the hook entries of a snapshot, rebuilding them from
+// Commands for older snapshots.
+func (s *HookSnapshot) Entries() []HookCommand {
+	if len(s.Hooks) > 0 {
+		return s.Hooks
+	}
+	return CommandHooks(s.Commands)
 }
 
 // Manifest represents the history manifest for a hook
@@ -136,6 +146,7 @@ func (h *HistoryManager) SaveVersion(hook *Hook) (*Version, error) {
 		EventType: hook.EventType,
 		Matcher:   hook.Matcher,
 		Commands:  hook.Commands,
+		Hooks:     hook.Hooks,
 	}
 
 	content, err := json.MarshalIndent(snapshot, "", "  ")
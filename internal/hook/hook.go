@@ -5,83 +5,147 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-// EventType represents the type of hook event
-type EventType string
-
-const (
-	PreToolUse    EventType = "PreToolUse"
-	PostToolUse   EventType = "PostToolUse"
-	Notification  EventType = "Notification"
-	Stop          EventType = "Stop"
-	SubagentStop  EventType = "SubagentStop"
-)
-
-// AllEventTypes returns all valid event types
-func AllEventTypes() []EventType {
-	return []EventType{PreToolUse, PostToolUse, Notification, Stop, SubagentStop}
+// HookCommand represents a single hook command
+// Example: {"type": "command", "command": "echo Done", "timeout": 30}
+// Keys jd does not model are kept in Extra and written back unchanged.
+type HookCommand struct {
+	Type    string                 `json:"type"`
+	Command string                 `json:"command,omitempty"`
+	Timeout int                    `json:"timeout,omitempty"` // seconds; 0 uses Claude Code's default
+	Extra   map[string]interface{} `json:"-"`
 }
 
-// EventTypeNames returns all valid event type names as strings (for CLI completion)
-func EventTypeNames() []string {
-	types := AllEventTypes()
-	names := make([]string, len(types))
-	for i, t := range types {
-		names[i] = string(t)
+// parseHookCommand builds a HookCommand from its settings.json object.
+func parseHookCommand(m map[string]interface{}) HookCommand {
+	h := HookCommand{}
+	for k, v := range m {
+		switch k {
+		case "type":
+			if t, ok := v.(string); ok {
+				h.Type = t
+				continue
+			}
+		case "command":
+			if c, ok := v.(string); ok {
+				h.Command = c
+				continue
+			}
+		case "timeout":
+			// Only whole seconds are modeled; anything else is kept as is
+			if f, ok := v.(float64); ok && f == float64(int(f)) && f > 0 {
+				h.Timeout = int(f)
+				continue
+			}
+		}
+		if h.Extra == nil {
+			h.Extra = make(map[string]interface{})
+		}
+		h.Extra[k] = v
 	}
-	return names
+	return h
 }
 
-// ParseEventType parses a string to EventType with alias support
-// Accepts: full name (PreToolUse), lowercase (pretooluse), or alias (pre)
-func ParseEventType(s string) (EventType, error) {
-	// Aliases mapping
-	aliases := map[string]EventType{
-		// Full names (case-insensitive)
-		"pretooluse":   PreToolUse,
-		"posttooluse":  PostToolUse,
-		"notification": Notification,
-		"stop":         Stop,
-		"subagentstop": SubagentStop,
-		// Short aliases
-		"pre":      PreToolUse,
-		"post":     PostToolUse,
-		"notify":   Notification,
-		"notif":    Notification,
-		"subagent": SubagentStop,
-		"sub":      SubagentStop,
+// fields returns the settings.json object of a HookCommand.
+func (h HookCommand) fields() map[string]interface{} {
+	m := make(map[string]interface{}, len(h.Extra)+3)
+	for k, v := range h.Extra {
+		m[k] = v
 	}
-
-	lower := strings.ToLower(s)
-	if et, ok := aliases[lower]; ok {
-		return et, nil
+	if h.Type != "" {
+		m["type"] = h.Type
 	}
+	if h.Command != "" || h.Type == "command" {
+		m["command"] = h.Command
+	}
+	if h.Timeout > 0 {
+		m["timeout"] = h.Timeout
+	}
+	return m
+}
 
-	return "", fmt.Errorf("invalid event type: %s\nValid types: PreToolUse(pre), PostToolUse(post), Notification(notify), Stop, SubagentStop(sub)", s)
+// MarshalJSON writes the command with its extra keys inline.
+func (h HookCommand) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.fields())
 }
 
-// HookCommand represents a single hook command
-// Example: {"type": "command", "command": "echo Done"}
-type HookCommand struct {
-	Type    string `json:"type"`
-	Command string `json:"command"`
+// UnmarshalJSON reads a command, keeping keys jd does not model in Extra.
+func (h *HookCommand) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*h = parseHookCommand(m)
+	return nil
+}
+
+// CommandHooks builds command entries from command strings.
+func CommandHooks(commands []string) []HookCommand {
+	hooks := make([]HookCommand, len(commands))
+	for i, cmd := range commands {
+		hooks[i] = HookCommand{Type: "command", Command: cmd}
+	}
+	return hooks
 }
 
 // HookRule represents a single hook rule with matcher and commands
 // matcher is a string pattern: "Bash", "Edit|Write", "*"
 type HookRule struct {
-	Matcher string        `json:"matcher"`
-	Hooks   []HookCommand `json:"hooks"`
+	Matcher    string                 `json:"matcher"`
+	Hooks      []HookCommand          `json:"hooks"`
+	Extra      map[string]interface{} `json:"-"` // keys jd does not model, written back unchanged
+	hasMatcher bool                   // the rule was read with a matcher key, even an empty one
+	id         string                 // stable ID, recorded in jd-hook-ids.json
+	legacyName string                 // positional name, set while IDs are not yet recorded
 }
 
 // Hook represents a named hook configuration for display/management
 type Hook struct {
-	Name      string    `json:"name"`
-	EventType EventType `json:"event_type"`
-	Matcher   string    `json:"matcher"`  // pattern: "Bash", "Edit|Write", "*"
-	Commands  []string  `json:"commands"` // from hooks[].command
+	Name      string        `json:"name"` // <event>-<matcher>-<id>, e.g. PreToolUse-Bash-3f9a2c1d
+	ID        string        `json:"id"`   // stable across edits made through jd and changes to other rules
+	EventType EventType     `json:"event_type"`
+	Matcher   string        `json:"matcher"`  // pattern: "Bash", "Edit|Write", "*"
+	Commands  []string      `json:"commands"` // from hooks[].command, one per entry of Hooks
+	Hooks     []HookCommand `json:"hooks"`    // full hooks[] entries, including timeouts and extra keys
+
+	legacyName string // positional name history is kept under until it is migrated
+}
+
+// HistoryKey returns the key of the hook's version history. Unlike the
+// name, it does not change when the matcher is edited. Until the store is
+// migrated (see Store.MigrateIDs), history is still kept under the legacy
+// positional name.
+func (h *Hook) HistoryKey() string {
+	if h.legacyName != "" {
+		return h.legacyName
+	}
+	return h.idHistoryKey()
+}
+
+// idHistoryKey returns the history key of a migrated hook.
+func (h *Hook) idHistoryKey() string {
+	return string(h.EventType) + "-" + h.ID
+}
+
+// newHook returns the Hook view of a rule.
+func newHook(eventType EventType, rule HookRule) *Hook {
+	commands := make([]string, len(rule.Hooks))
+	for i, h := range rule.Hooks {
+		commands[i] = h.Command
+	}
+	return &Hook{
+		Name:      generateHookName(eventType, rule.Matcher, rule.id),
+		ID:        rule.id,
+		EventType: eventType,
+		Matcher:   rule.Matcher,
+		Commands:  commands,
+		Hooks:     rule.Hooks,
+
+		legacyName: rule.legacyName,
+	}
 }
 
 // Settings represents the Claude Code settings.json structure
@@ -89,6 +153,8 @@ type Settings struct {
 	Hooks map[EventType][]HookRule `json:"hooks,omitempty"`
 	// Other settings fields can be added here
 	Other map[string]interface{} `json:"-"`
+
+	unrecorded bool // read before jd-hook-ids.json existed; the first write migrates history
 }
 
 // Store manages hooks in settings.json
@@ -129,6 +195,11 @@ func (s *Store) readSettings() (*Settings, map[string]interface{}, error) {
 		return nil, nil, err
 	}
 
+	records, recorded, err := s.loadIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Parse into generic map to preserve unknown fields
 	var raw map[string]interface{}
 	if err := json.Unmarshal(content, &raw); err != nil {
@@ -153,28 +224,30 @@ func (s *Store) readSettings() (*Settings, map[string]interface{}, error) {
 				}
 
 				rule := HookRule{}
-
-				// Parse matcher string: "Bash", "Edit|Write", "*"
-				if matcher, ok := ruleMap["matcher"].(string); ok {
-					rule.Matcher = matcher
-				}
-
-				// Parse hooks array: [{"type": "command", "command": "..."}]
-				if hooksArr, ok := ruleMap["hooks"].([]interface{}); ok {
-					for _, h := range hooksArr {
-						if hookMap, ok := h.(map[string]interface{}); ok {
-							hookCmd := HookCommand{}
-							if t, ok := hookMap["type"].(string); ok {
-								hookCmd.Type = t
-							}
-							if cmd, ok := hookMap["command"].(string); ok {
-								hookCmd.Command = cmd
-							}
-							if hookCmd.Command != "" {
-								rule.Hooks = append(rule.Hooks, hookCmd)
+				for k, v := range ruleMap {
+					switch k {
+					case "matcher":
+						// Parse matcher string: "Bash", "Edit|Write", "*"
+						if matcher, ok := v.(string); ok {
+							rule.Matcher = matcher
+							rule.hasMatcher = true
+							continue
+						}
+					case "hooks":
+						// Parse hooks array: [{"type": "command", "command": "..."}]
+						if hooksArr, ok := v.([]interface{}); ok {
+							for _, h := range hooksArr {
+								if hookMap, ok := h.(map[string]interface{}); ok {
+									rule.Hooks = append(rule.Hooks, parseHookCommand(hookMap))
+								}
 							}
+							continue
 						}
 					}
+					if rule.Extra == nil {
+						rule.Extra = make(map[string]interface{})
+					}
+					rule.Extra[k] = v
 				}
 
 				hookRules = append(hookRules, rule)
@@ -183,6 +256,19 @@ func (s *Store) readSettings() (*Settings, map[string]interface{}, error) {
 		}
 	}
 
+	assignIDs(settings, records)
+
+	// Reading changes nothing on disk: until IDs are recorded, remember the
+	// positional names history is kept under, for the first write to migrate
+	if !recorded {
+		settings.unrecorded = true
+		for eventType, rules := range settings.Hooks {
+			for i := range rules {
+				rules[i].legacyName = legacyHookName(eventType, rules[i].Matcher, i)
+			}
+		}
+	}
+
 	return settings, raw, nil
 }
 
@@ -193,8 +279,15 @@ func (s *Store) writeSettings(settings *Settings, raw map[string]interface{}) er
 		return err
 	}
 
-	// Convert hooks to JSON format
-	hooksMap := make(map[string][]map[string]interface{})
+	// Convert hooks to JSON format, keeping events jd could not parse
+	hooksMap := make(map[string]interface{})
+	if hooksRaw, ok := raw["hooks"].(map[string]interface{}); ok {
+		for eventType, rules := range hooksRaw {
+			if _, ok := rules.([]interface{}); !ok {
+				hooksMap[eventType] = rules
+			}
+		}
+	}
 	for eventType, rules := range settings.Hooks {
 		if len(rules) == 0 {
 			continue
@@ -202,19 +295,19 @@ func (s *Store) writeSettings(settings *Settings, raw map[string]interface{}) er
 
 		var rulesOutput []map[string]interface{}
 		for _, rule := range rules {
-			ruleMap := map[string]interface{}{
-				"matcher": rule.Matcher, // string: "Bash", "Edit|Write", "*"
-				"hooks": func() []map[string]interface{} {
-					var hooks []map[string]interface{}
-					for _, h := range rule.Hooks {
-						hooks = append(hooks, map[string]interface{}{
-							"type":    h.Type,
-							"command": h.Command,
-						})
-					}
-					return hooks
-				}(),
+			ruleMap := make(map[string]interface{}, len(rule.Extra)+2)
+			for k, v := range rule.Extra {
+				ruleMap[k] = v
+			}
+			// Rules read without a matcher are written without one
+			if rule.Matcher != "" || rule.hasMatcher {
+				ruleMap["matcher"] = rule.Matcher // string: "Bash", "Edit|Write", "*"
+			}
+			hooks := make([]map[string]interface{}, len(rule.Hooks))
+			for i, h := range rule.Hooks {
+				hooks[i] = h.fields()
 			}
+			ruleMap["hooks"] = hooks
 			rulesOutput = append(rulesOutput, ruleMap)
 		}
 		hooksMap[string(eventType)] = rulesOutput
@@ -237,7 +330,29 @@ func (s *Store) writeSettings(settings *Settings, raw map[string]interface{}) er
 		return err
 	}
 
-	return os.WriteFile(path, content, 0644)
+	if err := writeFileAtomic(path, content, 0644); err != nil {
+		return err
+	}
+	return s.recordIDs(settings)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash never leaves a partially written file. A
+// symlinked path is written through to its target.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
 }
 
 // List returns all hooks as a flat list
@@ -248,63 +363,47 @@ func (s *Store) List() ([]*Hook, error) {
 	}
 
 	var hooks []*Hook
-	for eventType, rules := range settings.Hooks {
-		for i, rule := range rules {
-			name := generateHookName(eventType, rule.Matcher, i)
-
-			var commands []string
-			for _, h := range rule.Hooks {
-				commands = append(commands, h.Command)
-			}
-
-			hooks = append(hooks, &Hook{
-				Name:      name,
-				EventType: eventType,
-				Matcher:   rule.Matcher,
-				Commands:  commands,
-			})
+	for _, eventType := range sortedEvents(settings) {
+		for _, rule := range settings.Hooks[eventType] {
+			hooks = append(hooks, newHook(eventType, rule))
 		}
 	}
 
 	return hooks, nil
 }
 
-// Get retrieves a specific hook by name
+// Get retrieves a specific hook by name, ID or legacy positional name
 func (s *Store) Get(name string) (*Hook, error) {
-	hooks, err := s.List()
+	settings, _, err := s.readSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, h := range hooks {
-		if h.Name == name {
-			return h, nil
-		}
+	eventType, idx, err := findRule(settings, name)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, os.ErrNotExist
+	return newHook(eventType, settings.Hooks[eventType][idx]), nil
 }
 
 // Add adds a new hook rule
 func (s *Store) Add(eventType EventType, matcher string, commands []string) (*Hook, error) {
+	return s.AddHooks(eventType, matcher, CommandHooks(commands))
+}
+
+// AddHooks adds a new hook rule with full hook entries
+func (s *Store) AddHooks(eventType EventType, matcher string, hooks []HookCommand) (*Hook, error) {
+	return s.addRule(eventType, HookRule{Matcher: matcher, Hooks: hooks})
+}
+
+// addRule appends a rule under a new ID.
+func (s *Store) addRule(eventType EventType, rule HookRule) (*Hook, error) {
 	settings, raw, err := s.readSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	// Build hook commands
-	var hookCmds []HookCommand
-	for _, cmd := range commands {
-		hookCmds = append(hookCmds, HookCommand{
-			Type:    "command",
-			Command: cmd,
-		})
-	}
-
-	rule := HookRule{
-		Matcher: matcher,
-		Hooks:   hookCmds,
-	}
+	rule.id = newID(settings, eventType, rule)
 
 	settings.Hooks[eventType] = append(settings.Hooks[eventType], rule)
 
@@ -312,55 +411,32 @@ func (s *Store) Add(eventType EventType, matcher string, commands []string) (*Ho
 		return nil, err
 	}
 
-	idx := len(settings.Hooks[eventType]) - 1
-	return &Hook{
-		Name:      generateHookName(eventType, matcher, idx),
-		EventType: eventType,
-		Matcher:   matcher,
-		Commands:  commands,
-	}, nil
+	return newHook(eventType, rule), nil
 }
 
-// Update updates an existing hook
-func (s *Store) Update(name string, matcher string, commands []string) (*Hook, error) {
+// Update updates an existing hook, replacing its matcher and hook entries.
+// Other keys of the rule are kept.
+func (s *Store) Update(name string, matcher string, hooks []HookCommand) (*Hook, error) {
 	settings, raw, err := s.readSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	eventType, idx, err := parseHookName(name)
+	eventType, idx, err := findRule(settings, name)
 	if err != nil {
 		return nil, err
 	}
 
-	rules, ok := settings.Hooks[eventType]
-	if !ok || idx >= len(rules) {
-		return nil, os.ErrNotExist
-	}
-
-	// Build hook commands
-	var hookCmds []HookCommand
-	for _, cmd := range commands {
-		hookCmds = append(hookCmds, HookCommand{
-			Type:    "command",
-			Command: cmd,
-		})
-	}
-
+	rules := settings.Hooks[eventType]
 	rules[idx].Matcher = matcher
-	rules[idx].Hooks = hookCmds
+	rules[idx].Hooks = hooks
 	settings.Hooks[eventType] = rules
 
 	if err := s.writeSettings(settings, raw); err != nil {
 		return nil, err
 	}
 
-	return &Hook{
-		Name:      generateHookName(eventType, matcher, idx),
-		EventType: eventType,
-		Matcher:   matcher,
-		Commands:  commands,
-	}, nil
+	return newHook(eventType, rules[idx]), nil
 }
 
 // Delete removes a hook by name
@@ -370,16 +446,12 @@ func (s *Store) Delete(name string) error {
 		return err
 	}
 
-	eventType, idx, err := parseHookName(name)
+	eventType, idx, err := findRule(settings, name)
 	if err != nil {
 		return err
 	}
 
-	rules, ok := settings.Hooks[eventType]
-	if !ok || idx >= len(rules) {
-		return os.ErrNotExist
-	}
-
+	rules := settings.Hooks[eventType]
 	// Remove the rule at index
 	settings.Hooks[eventType] = append(rules[:idx], rules[idx+1:]...)
 
@@ -387,36 +459,42 @@ func (s *Store) Delete(name string) error {
 }
 
 // generateHookName creates a unique name for a hook
-func generateHookName(eventType EventType, matcher string, index int) string {
-	// Sanitize matcher for use in name
+func generateHookName(eventType EventType, matcher string, id string) string {
+	return fmt.Sprintf("%s-%s-%s", eventType, sanitizeMatcher(matcher), id)
+}
+
+// legacyHookName creates the positional name hooks had before they had IDs
+func legacyHookName(eventType EventType, matcher string, index int) string {
+	return fmt.Sprintf("%s-%s-%d", eventType, sanitizeMatcher(matcher), index)
+}
+
+// sanitizeMatcher makes a matcher usable in a hook name
+func sanitizeMatcher(matcher string) string {
 	sanitized := matcher
 	if sanitized == "" || sanitized == "*" {
 		sanitized = "all"
 	}
 	sanitized = strings.ReplaceAll(sanitized, "|", "-")
 	sanitized = strings.ReplaceAll(sanitized, " ", "_")
-
-	return fmt.Sprintf("%s-%s-%d", eventType, sanitized, index)
+	return sanitized
 }
 
-// parseHookName extracts event type and index from hook name
+// parseHookName extracts event type and index from a legacy positional
+// hook name. Event names contain no dashes, so the event is everything
+// before the first dash; events this version does not know are accepted
+// so rules already in settings.json stay addressable.
 func parseHookName(name string) (EventType, int, error) {
-	for _, et := range AllEventTypes() {
-		prefix := string(et) + "-"
-		if strings.HasPrefix(name, prefix) {
-			rest := strings.TrimPrefix(name, prefix)
-			// Find last dash to get index
-			lastDash := strings.LastIndex(rest, "-")
-			if lastDash == -1 {
-				continue
-			}
-			var idx int
-			if _, err := fmt.Sscanf(rest[lastDash+1:], "%d", &idx); err == nil {
-				return et, idx, nil
-			}
-		}
+	firstDash := strings.Index(name, "-")
+	lastDash := strings.LastIndex(name, "-")
+	if firstDash <= 0 || lastDash == firstDash {
+		return "", 0, fmt.Errorf("invalid hook name: %s", name)
+	}
+
+	idx, err := strconv.Atoi(name[lastDash+1:])
+	if err != nil || idx < 0 {
+		return "", 0, fmt.Errorf("invalid hook name: %s", name)
 	}
-	return "", 0, fmt.Errorf("invalid hook name: %s", name)
+	return EventType(name[:firstDash]), idx, nil
 }
 
 // GetHooksDir returns the hooks script directory path
@@ -0,0 +1,401 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	original := `{
+  "model": "opus",
+  "hooks": {
+    "PreToolUse": [
+      {
+        "matcher": "Bash",
+        "description": "guard",
+        "hooks": [
+          {"type": "command", "command": "guard.sh", "timeout": 30, "async": true},
+          {"type": "prompt", "prompt": "Is this safe?"}
+        ]
+      }
+    ],
+    "Stop": [
+      {"hooks": [{"type": "command", "command": "done.sh", "timeout": 2.5}]}
+    ],
+    "FutureEvent": {"opaque": true}
+  }
+}`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewStore(path)
+	h, err := store.Get("PreToolUse-Bash-0")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(h.Hooks) != 2 || h.Hooks[0].Timeout != 30 || h.Hooks[1].Type != "prompt" {
+		t.Fatalf("unexpected hooks: %+v", h.Hooks)
+	}
+
+	// Rewriting settings.json through an unrelated change keeps everything else
+	if _, err := store.Add(SessionStart, "", []string{"start.sh"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Delete("SessionStart-all-0"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	var want, got map[string]interface{}
+	data, _ := os.ReadFile(path)
+	_ = json.Unmarshal([]byte(original), &want)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("settings.json changed:\n%s", data)
+	}
+
+	// Updating a rule keeps its other keys
+	entries := h.Hooks
+	entries[0].Timeout = 60
+	if _, err := store.Update(h.Name, "Bash|Edit", entries); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, err := store.Get("PreToolUse-Bash-Edit-0")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if updated.Hooks[0].Timeout != 60 || updated.Hooks[0].Extra["async"] != true || updated.Hooks[1].Extra["prompt"] != "Is this safe?" {
+		t.Errorf("unexpected hooks after update: %+v", updated.Hooks)
+	}
+}
+
+func TestStableIDs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	original := `{
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "first.sh"}]},
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "second.sh"}]}
+    ]
+  }
+}`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// History kept under the positional name of the second rule
+	legacyDir := filepath.Join(dir, historySubDir, "PreToolUse-Bash-1")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, "manifest.json"), []byte(`{"hook_name": "PreToolUse-Bash-1", "versions": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewStore(path)
+	second, err := store.Get("PreToolUse-Bash-1")
+	if err != nil {
+		t.Fatalf("legacy name did not resolve: %v", err)
+	}
+	if second.Hooks[0].Command != "second.sh" || second.ID == "" {
+		t.Fatalf("unexpected hook: %+v", second)
+	}
+
+	// Reads change nothing on disk; history stays under the legacy name
+	if _, err := store.List(); err != nil {
+		t.Fatal(err)
+	}
+	if second.HistoryKey() != "PreToolUse-Bash-1" {
+		t.Errorf("HistoryKey before migration = %q, want the legacy name", second.HistoryKey())
+	}
+	if _, err := os.Stat(legacyDir); err != nil {
+		t.Errorf("read moved the legacy history dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, idsFileName)); !os.IsNotExist(err) {
+		t.Errorf("read created %s", idsFileName)
+	}
+
+	// The first write migrates history of the rules as they were read
+	if err := store.Delete("PreToolUse-Bash-0"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	migrated := filepath.Join(dir, historySubDir, "PreToolUse-"+second.ID)
+	if _, err := os.Stat(migrated); err != nil {
+		t.Errorf("history was not migrated: %v", err)
+	}
+	if _, err := os.Stat(legacyDir); !os.IsNotExist(err) {
+		t.Errorf("legacy history dir still exists")
+	}
+
+	// The remaining rule moved to index 0 but keeps its name
+	h, err := store.Get(second.Name)
+	if err != nil {
+		t.Fatalf("Get by name after delete failed: %v", err)
+	}
+	if h.ID != second.ID || h.Hooks[0].Command != "second.sh" || h.HistoryKey() != "PreToolUse-"+second.ID {
+		t.Errorf("hook changed after delete: %+v", h)
+	}
+	if _, err := store.Get(second.ID); err != nil {
+		t.Errorf("Get by ID failed: %v", err)
+	}
+
+	// The matcher in a name must be the rule's
+	if _, err := store.Get("PreToolUse-Anything-" + second.ID); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get with a wrong matcher error = %v, want os.ErrNotExist", err)
+	}
+	if _, err := store.Get("PostToolUse-Bash-" + second.ID); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get with a wrong event error = %v, want os.ErrNotExist", err)
+	}
+
+	// Editing keeps the ID
+	updated, err := store.Update(second.Name, "Edit", second.Hooks)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.ID != second.ID {
+		t.Errorf("ID changed on update: %s != %s", updated.ID, second.ID)
+	}
+	reloaded, err := NewStore(path).Get(updated.Name)
+	if err != nil || reloaded.ID != second.ID {
+		t.Errorf("ID not kept across reads: %v", err)
+	}
+}
+
+func TestIDsFollowHandEdits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	store := NewStore(path)
+	first, err := store.Add(PreToolUse, "Bash", []string{"first.sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Add(PreToolUse, "Bash", []string{"second.sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary settings file left behind")
+	}
+
+	// Edit settings.json by hand: a timeout on the first rule, a new
+	// command on the second
+	edited := `{
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "first.sh", "timeout": 30}]},
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "renamed.sh"}]}
+    ]
+  }
+}`
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 2 || hooks[0].ID != first.ID || hooks[1].ID != second.ID {
+		t.Fatalf("IDs after hand edits = %+v; want %s, %s", hooks, first.ID, second.ID)
+	}
+
+	// A rule added by hand gets a new ID
+	edited = strings.Replace(edited, "]}\n    ]", "]},\n      {\"matcher\": \"Edit\", \"hooks\": [{\"type\": \"command\", \"command\": \"third.sh\"}]}\n    ]", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 3 || hooks[2].ID == first.ID || hooks[2].ID == second.ID {
+		t.Errorf("IDs after adding a rule = %+v", hooks)
+	}
+}
+
+func TestSamplePayload(t *testing.T) {
+	payload := SamplePayload(PreToolUse, "Edit|Write", PayloadOptions{CWD: "/work", SessionID: "s1"})
+	if payload["tool_name"] != "Edit" || payload["hook_event_name"] != "PreToolUse" || payload["session_id"] != "s1" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+	if input, _ := payload["tool_input"].(map[string]interface{}); input["file_path"] != filepath.Join("/work", "example.txt") {
+		t.Errorf("unexpected tool_input: %v", payload["tool_input"])
+	}
+
+	payload = SamplePayload(SessionStart, "resume", PayloadOptions{})
+	if payload["source"] != "resume" {
+		t.Errorf("source = %v, want resume", payload["source"])
+	}
+
+	if err := SetPayloadField(payload, "tool_input.command", "rm -rf /"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPayloadField(payload, "stop_hook_active", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if payload["tool_input"].(map[string]interface{})["command"] != "rm -rf /" || payload["stop_hook_active"] != true {
+		t.Errorf("overrides not applied: %v", payload)
+	}
+	if err := SetPayloadField(payload, "source.x", "1"); err == nil {
+		t.Error("expected error setting a field inside a string")
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands run through sh")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	r := RunCommand(ctx, HookCommand{Type: "command", Command: `cat >/dev/null; echo '{"decision":"block","reason":"no"}'`}, []byte("{}"), dir, 0)
+	if r.ExitCode != 0 || r.Output == nil || r.Output.Decision != "block" || !r.Blocking() || r.Timeout != DefaultTimeout {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	r = RunCommand(ctx, HookCommand{Type: "command", Command: `grep -q rm && echo bad >&2 && exit 2`}, []byte(`{"command":"rm"}`), dir, 0)
+	if r.ExitCode != BlockingExitCode || r.Stderr != "bad\n" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	r = RunCommand(ctx, HookCommand{Type: "command", Command: "sleep 5", Timeout: 1}, nil, dir, 100*time.Millisecond)
+	if !r.TimedOut || r.Duration > 3*time.Second {
+		t.Errorf("expected timeout: %+v", r)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		matcher string
+		value   string
+		want    bool
+	}{
+		{"", "Bash", true},
+		{"*", "mcp__github__create_issue", true},
+		{"Bash", "Bash", true},
+		{"Edit", "MultiEdit", false},
+		{"Edit|Write", "Write", true},
+		{"Edit | Write", "Edit", true},
+		{"Edit|Write", "Read", false},
+		{"Notebook.*", "NotebookEdit", true},
+		{"mcp__github__.*", "mcp__github__create_issue", true},
+		{"mcp__github__.*", "mcp__gitlab__create_issue", false},
+		{"mcp__memory__create_entities", "mcp__memory__create_entities", true},
+		{"Bad(", "Bad(", false},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.matcher, tt.value); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.matcher, tt.value, got, tt.want)
+		}
+	}
+
+	if err := ValidateMatcher(PreToolUse, "Bad("); err == nil {
+		t.Error("expected invalid regex to be rejected")
+	}
+	if err := ValidateMatcher(SessionStart, "startup|bogus"); err == nil {
+		t.Error("expected unknown SessionStart value to be rejected")
+	}
+	if err := ValidateMatcher(Stop, "Bash"); err == nil {
+		t.Error("expected matcher on Stop to be rejected")
+	}
+	if err := ValidateMatcher(PreToolUse, "mcp__.*"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := UnknownTools(PreToolUse, "Edit|Edti|mcp__fs__read"); !reflect.DeepEqual(got, []string{"Edti"}) {
+		t.Errorf("UnknownTools = %v, want [Edti]", got)
+	}
+}
+
+func TestParseEventType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  EventType
+	}{
+		{"PreToolUse", PreToolUse},
+		{"pretooluse", PreToolUse},
+		{"PRETOOLUSE", PreToolUse},
+		{"pre", PreToolUse},
+		{"PRE", PreToolUse},
+		{" post ", PostToolUse},
+		{"notify", Notification},
+		{"notif", Notification},
+		{"prompt", UserPromptSubmit},
+		{"submit", UserPromptSubmit},
+		{"stop", Stop},
+		{"sub", SubagentStop},
+		{"subagent", SubagentStop},
+		{"compact", PreCompact},
+		{"start", SessionStart},
+		{"session-start", SessionStart},
+		{"end", SessionEnd},
+		{"Session-End", SessionEnd},
+	}
+	for _, tt := range tests {
+		got, err := ParseEventType(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseEventType(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "bogus", "PreTool", "pre-tool-use"} {
+		if got, err := ParseEventType(input); err == nil {
+			t.Errorf("ParseEventType(%q) = %q, want an error", input, got)
+		} else if !strings.Contains(err.Error(), "PreToolUse(pre)") {
+			t.Errorf("ParseEventType(%q) error does not list the valid types: %v", input, err)
+		}
+	}
+}
+
+func TestLookupEvent(t *testing.T) {
+	tests := []struct {
+		event  EventType
+		kind   MatcherKind
+		field  string
+		values []string
+	}{
+		{PreToolUse, MatcherTool, "tool_name", nil},
+		{PostToolUse, MatcherTool, "tool_name", nil},
+		{Notification, MatcherValue, "notification_type", []string{"permission_prompt", "idle_prompt", "auth_success", "elicitation_dialog"}},
+		{UserPromptSubmit, MatcherNone, "", nil},
+		{Stop, MatcherNone, "", nil},
+		{SubagentStop, MatcherNone, "", nil},
+		{PreCompact, MatcherValue, "trigger", []string{"manual", "auto"}},
+		{SessionStart, MatcherValue, "source", []string{"startup", "resume", "clear", "compact"}},
+		{SessionEnd, MatcherNone, "", nil},
+	}
+	if len(tests) != len(AllEventTypes()) {
+		t.Fatalf("%d events tested, %d known", len(tests), len(AllEventTypes()))
+	}
+	for _, tt := range tests {
+		e, ok := LookupEvent(tt.event)
+		if !ok {
+			t.Errorf("LookupEvent(%s) not found", tt.event)
+			continue
+		}
+		if e.Matcher != tt.kind || e.MatchField != tt.field || !reflect.DeepEqual(e.MatcherValues, tt.values) {
+			t.Errorf("LookupEvent(%s) = %+v", tt.event, e)
+		}
+		if e.Description == "" {
+			t.Errorf("%s has no description", tt.event)
+		}
+		if (e.Matcher == MatcherNone) != (e.MatcherHint() == "not used") {
+			t.Errorf("%s matcher hint = %q", tt.event, e.MatcherHint())
+		}
+	}
+
+	if _, ok := LookupEvent("Bogus"); ok {
+		t.Error("LookupEvent found an unknown event")
+	}
+}
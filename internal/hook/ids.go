@@ -0,0 +1,340 @@
+package hook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// idsFileName is the file next to settings.json that records hook IDs.
+// IDs are kept out of settings.json so Claude Code sees only its own keys.
+const idsFileName = "jd-hook-ids.json"
+
+// idLength is the number of hex digits of a new hook ID.
+const idLength = 8
+
+// idsVersion is the current jd-hook-ids.json format. Version 1 records
+// lack the position, matcher and commands used to follow hand edits.
+const idsVersion = 2
+
+// idRecord ties a hook ID to the content of its rule when jd last wrote it.
+// Position, Matcher and Commands let the ID follow a rule edited outside jd.
+type idRecord struct {
+	ID          string    `json:"id"`
+	Event       EventType `json:"event"`
+	Fingerprint string    `json:"fingerprint"`
+	Position    int       `json:"position"` // index among the event's rules; -1 if unknown
+	Matcher     string    `json:"matcher"`
+	Commands    []string  `json:"commands"`
+}
+
+// idsFile represents the jd-hook-ids.json file structure.
+type idsFile struct {
+	Version int        `json:"version"`
+	Hooks   []idRecord `json:"hooks"`
+}
+
+// fingerprint hashes the content of a rule.
+func fingerprint(eventType EventType, rule HookRule) string {
+	data, _ := json.Marshal(struct {
+		Event   EventType              `json:"event"`
+		Matcher string                 `json:"matcher"`
+		Hooks   []HookCommand          `json:"hooks"`
+		Extra   map[string]interface{} `json:"extra,omitempty"`
+	}{eventType, rule.Matcher, rule.Hooks, rule.Extra})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// idsPath returns the path of the hook ID file.
+func (s *Store) idsPath() (string, error) {
+	path, err := s.expandPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), idsFileName), nil
+}
+
+// loadIDs loads the recorded hook IDs. A missing file yields nil records
+// and exists=false.
+func (s *Store) loadIDs() (records []idRecord, exists bool, err error) {
+	path, err := s.idsPath()
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var f idsFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, true, fmt.Errorf("failed to parse %s: %w", idsFileName, err)
+	}
+	if f.Version < 2 {
+		for i := range f.Hooks {
+			f.Hooks[i].Position = -1
+		}
+	}
+	return f.Hooks, true, nil
+}
+
+// saveIDs records the IDs of all rules with their current content.
+func (s *Store) saveIDs(settings *Settings) error {
+	path, err := s.idsPath()
+	if err != nil {
+		return err
+	}
+
+	f := idsFile{Version: idsVersion, Hooks: []idRecord{}}
+	for _, eventType := range sortedEvents(settings) {
+		for i, rule := range settings.Hooks[eventType] {
+			if rule.id == "" {
+				continue
+			}
+			f.Hooks = append(f.Hooks, idRecord{
+				ID:          rule.id,
+				Event:       eventType,
+				Fingerprint: fingerprint(eventType, rule),
+				Position:    i,
+				Matcher:     rule.Matcher,
+				Commands:    ruleCommands(rule),
+			})
+		}
+	}
+
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, content, 0644)
+}
+
+// ruleCommands returns the commands a rule runs.
+func ruleCommands(rule HookRule) []string {
+	commands := make([]string, len(rule.Hooks))
+	for i, h := range rule.Hooks {
+		commands[i] = h.Command
+	}
+	return commands
+}
+
+// assignIDs gives every rule its recorded ID. Rules are matched to records
+// by content first; the remaining rules, edited outside jd, are matched by
+// matcher and commands (a changed timeout) and then by event and position
+// (a changed matcher or command). Rules still unmatched get an ID derived
+// from their content, so IDs are stable across reads even before they are
+// saved.
+func assignIDs(settings *Settings, records []idRecord) {
+	used := make(map[string]bool)
+	claimed := make([]bool, len(records))
+
+	claim := func(match func(eventType EventType, i int, rule HookRule, r idRecord) bool) {
+		for _, eventType := range sortedEvents(settings) {
+			rules := settings.Hooks[eventType]
+			for i := range rules {
+				if rules[i].id != "" {
+					continue
+				}
+				for j, r := range records {
+					if !claimed[j] && r.Event == eventType && !used[r.ID] && match(eventType, i, rules[i], r) {
+						claimed[j] = true
+						used[r.ID] = true
+						rules[i].id = r.ID
+						break
+					}
+				}
+			}
+		}
+	}
+	claim(func(eventType EventType, _ int, rule HookRule, r idRecord) bool {
+		return r.Fingerprint == fingerprint(eventType, rule)
+	})
+	claim(func(_ EventType, _ int, rule HookRule, r idRecord) bool {
+		return r.Position >= 0 && r.Matcher == rule.Matcher && equalStrings(r.Commands, ruleCommands(rule))
+	})
+	claim(func(_ EventType, i int, _ HookRule, r idRecord) bool {
+		return r.Position == i
+	})
+
+	// Keep IDs of recorded rules from being reused by new ones
+	for _, r := range records {
+		used[r.ID] = true
+	}
+
+	for _, eventType := range sortedEvents(settings) {
+		rules := settings.Hooks[eventType]
+		for i := range rules {
+			if rules[i].id != "" {
+				continue
+			}
+			fp := fingerprint(eventType, rules[i])
+			n := idLength
+			for n < len(fp) && used[fp[:n]] {
+				n++
+			}
+			rules[i].id = fp[:n]
+			used[rules[i].id] = true
+		}
+	}
+}
+
+// equalStrings reports whether a and b hold the same strings in order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newID returns an unused ID for a rule about to be added.
+func newID(settings *Settings, eventType EventType, rule HookRule) string {
+	used := make(map[string]bool)
+	for _, rules := range settings.Hooks {
+		for _, r := range rules {
+			used[r.id] = true
+		}
+	}
+	fp := fingerprint(eventType, rule)
+	n := idLength
+	for n < len(fp) && used[fp[:n]] {
+		n++
+	}
+	return fp[:n]
+}
+
+// sortedEvents returns the event types of settings in a fixed order:
+// known events first, in presentation order, then others by name.
+func sortedEvents(settings *Settings) []EventType {
+	var out []EventType
+	for _, et := range AllEventTypes() {
+		if _, ok := settings.Hooks[et]; ok {
+			out = append(out, et)
+		}
+	}
+	var unknown []string
+	for et := range settings.Hooks {
+		if _, ok := LookupEvent(et); !ok {
+			unknown = append(unknown, string(et))
+		}
+	}
+	sort.Strings(unknown)
+	for _, et := range unknown {
+		out = append(out, EventType(et))
+	}
+	return out
+}
+
+// findRule resolves a hook name to its rule. Names end in the rule's ID
+// (PreToolUse-Bash-3f9a2c1d, PreToolUse-3f9a2c1d or just the ID) and must
+// name the rule's event and matcher; legacy positional names
+// (PreToolUse-Bash-0) are accepted if the rule at that index still has
+// that matcher.
+func findRule(settings *Settings, name string) (EventType, int, error) {
+	key := name
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		key = name[i+1:]
+	}
+
+	for eventType, rules := range settings.Hooks {
+		for i, rule := range rules {
+			if rule.id != key {
+				continue
+			}
+			if key == name || name == string(eventType)+"-"+key || name == generateHookName(eventType, rule.Matcher, key) {
+				return eventType, i, nil
+			}
+		}
+	}
+
+	eventType, idx, err := parseHookName(name)
+	if err != nil {
+		return "", 0, os.ErrNotExist
+	}
+	rules := settings.Hooks[eventType]
+	if idx >= len(rules) || legacyHookName(eventType, rules[idx].Matcher, idx) != name {
+		return "", 0, os.ErrNotExist
+	}
+	return eventType, idx, nil
+}
+
+// MigrateIDs records hook IDs and moves history kept under legacy
+// positional names to the IDs, without rewriting settings.json. Writes do
+// this on their own; commands that work with history call it first so the
+// history they open is not moved by a later write.
+func (s *Store) MigrateIDs() error {
+	settings, _, err := s.readSettings()
+	if err != nil {
+		return err
+	}
+	if !settings.unrecorded || len(settings.Hooks) == 0 {
+		return nil
+	}
+	return s.recordIDs(settings)
+}
+
+// recordIDs saves the IDs of settings, first migrating history if they
+// were read before IDs were recorded.
+func (s *Store) recordIDs(settings *Settings) error {
+	if settings.unrecorded {
+		if err := s.migrateHistory(settings); err != nil {
+			return fmt.Errorf("failed to migrate hook history: %w", err)
+		}
+		settings.unrecorded = false
+		for _, rules := range settings.Hooks {
+			for i := range rules {
+				rules[i].legacyName = ""
+			}
+		}
+	}
+	return s.saveIDs(settings)
+}
+
+// migrateHistory moves history directories keyed by the legacy positional
+// names rules had when they were read to the stable keys of the rules.
+func (s *Store) migrateHistory(settings *Settings) error {
+	path, err := s.expandPath()
+	if err != nil {
+		return err
+	}
+	claudeDir := filepath.Dir(path)
+	historyDir := filepath.Join(claudeDir, historySubDir)
+
+	for eventType, rules := range settings.Hooks {
+		for _, rule := range rules {
+			if rule.legacyName == "" || rule.id == "" {
+				continue
+			}
+			h := newHook(eventType, rule)
+			src := filepath.Join(historyDir, sanitizeHookName(rule.legacyName))
+			dest := filepath.Join(historyDir, sanitizeHookName(h.idHistoryKey()))
+			if info, err := os.Stat(src); err != nil || !info.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(dest); err == nil {
+				continue
+			}
+			if err := os.Rename(src, dest); err != nil {
+				return err
+			}
+			mgr := NewHistoryManager(claudeDir, h.idHistoryKey())
+			if manifest, err := mgr.loadManifest(); err == nil {
+				manifest.HookName = h.idHistoryKey()
+				_ = mgr.saveManifest(manifest)
+			}
+		}
+	}
+	return nil
+}
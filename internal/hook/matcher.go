@@ -0,0 +1,184 @@
+package hook
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ToolInfo describes a built-in Claude Code tool.
+type ToolInfo struct {
+	Name        string
+	Description string
+}
+
+// tools lists the built-in tools hook matchers can name.
+var tools = []ToolInfo{
+	{"Bash", "Execute shell commands"},
+	{"BashOutput", "Read output of background shells"},
+	{"KillShell", "Stop background shells"},
+	{"Read", "Read files"},
+	{"Write", "Write files"},
+	{"Edit", "Edit files"},
+	{"MultiEdit", "Edit files in several places"},
+	{"NotebookEdit", "Edit Jupyter notebooks"},
+	{"Glob", "Find files by pattern"},
+	{"Grep", "Search file contents"},
+	{"LS", "List directories"},
+	{"WebFetch", "Fetch web pages"},
+	{"WebSearch", "Search the web"},
+	{"Task", "Run subagent tasks"},
+	{"TodoWrite", "Update the todo list"},
+	{"ExitPlanMode", "Leave plan mode"},
+	{"SlashCommand", "Run slash commands"},
+	{"Skill", "Run skills"},
+}
+
+// Tools returns the built-in tools hook matchers can name.
+func Tools() []ToolInfo {
+	return append([]ToolInfo(nil), tools...)
+}
+
+// IsKnownTool reports whether name is a built-in tool or an MCP tool name
+// (mcp__<server>__<tool>).
+func IsKnownTool(name string) bool {
+	for _, t := range tools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return mcpToolPattern.MatchString(name)
+}
+
+var (
+	// namesPattern matches matchers that are plain names separated by "|".
+	namesPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\s*\|\s*[A-Za-z0-9_-]+)*$`)
+	// mcpToolPattern matches MCP tool names.
+	mcpToolPattern = regexp.MustCompile(`^mcp__[A-Za-z0-9_-]+?__[A-Za-z0-9_-]+$`)
+)
+
+// Matcher is a parsed hook matcher. An empty matcher or "*" selects every
+// value, names separated by "|" select exactly those names, and anything
+// else is a regular expression that must match the whole value, e.g.
+// "mcp__github__.*" or "Notebook.*".
+type Matcher struct {
+	pattern string
+	names   []string       // exact names; nil for "*" and regexes
+	re      *regexp.Regexp // nil unless the pattern is a regex
+}
+
+// ParseMatcher parses a matcher, reporting an invalid regular expression.
+func ParseMatcher(pattern string) (*Matcher, error) {
+	m := &Matcher{pattern: pattern}
+	switch {
+	case pattern == "" || pattern == "*":
+	case namesPattern.MatchString(pattern):
+		for _, name := range strings.Split(pattern, "|") {
+			m.names = append(m.names, strings.TrimSpace(name))
+		}
+	default:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", pattern, err)
+		}
+		m.re = regexp.MustCompile("^(?:" + pattern + ")$")
+	}
+	return m, nil
+}
+
+// String returns the matcher as written.
+func (m *Matcher) String() string {
+	return m.pattern
+}
+
+// MatchesAll reports whether the matcher selects every value.
+func (m *Matcher) MatchesAll() bool {
+	return m.names == nil && m.re == nil
+}
+
+// IsRegex reports whether the matcher is a regular expression.
+func (m *Matcher) IsRegex() bool {
+	return m.re != nil
+}
+
+// Names returns the names of an exact matcher, or nil.
+func (m *Matcher) Names() []string {
+	return append([]string(nil), m.names...)
+}
+
+// Match reports whether the matcher selects value.
+func (m *Matcher) Match(value string) bool {
+	switch {
+	case m.re != nil:
+		return m.re.MatchString(value)
+	case m.names != nil:
+		return contains(m.names, value)
+	default:
+		return true
+	}
+}
+
+// Matches reports whether matcher selects value. Invalid matchers select
+// nothing, as Claude Code never runs their hooks.
+func Matches(matcher, value string) bool {
+	m, err := ParseMatcher(matcher)
+	if err != nil {
+		return false
+	}
+	return m.Match(value)
+}
+
+// ValidateMatcher checks a matcher against the semantics of an event.
+// Regexes must compile, events without matchers accept only an empty
+// matcher or "*", and names given to value matchers must be known values.
+// Unknown events accept any valid matcher.
+func ValidateMatcher(et EventType, matcher string) error {
+	m, err := ParseMatcher(matcher)
+	if err != nil {
+		return err
+	}
+	e, ok := LookupEvent(et)
+	if !ok || m.MatchesAll() {
+		return nil
+	}
+
+	switch e.Matcher {
+	case MatcherNone:
+		return fmt.Errorf("%s hooks do not use a matcher (got %q)", et, matcher)
+	case MatcherValue:
+		var unknown []string
+		for _, v := range m.names {
+			if !contains(e.MatcherValues, v) {
+				unknown = append(unknown, v)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return fmt.Errorf("invalid %s matcher %q: unknown %s (use %s)",
+				et, matcher, strings.Join(unknown, ", "), strings.Join(e.MatcherValues, "|"))
+		}
+	}
+	return nil
+}
+
+// UnknownTools returns the names a tool matcher selects that are neither
+// built-in tools nor MCP tools. They are likely typos, but may also be
+// tools added after this version of jd, so callers should warn rather
+// than fail.
+func UnknownTools(et EventType, matcher string) []string {
+	e, ok := LookupEvent(et)
+	if !ok || e.Matcher != MatcherTool {
+		return nil
+	}
+	m, err := ParseMatcher(matcher)
+	if err != nil {
+		return nil
+	}
+	var unknown []string
+	for _, name := range m.names {
+		if !IsKnownTool(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,67 @@
+package hook
+
+import "strings"
+
+// RemoveScriptRules removes the commands that run script, an installed hook
+// package's script as its commands refer to it, along with rules left
+// without commands. Rules are found by what they run, so they are removed
+// even after their matcher or other fields were edited. Returns the number
+// of commands removed.
+func (s *Store) RemoveScriptRules(script string) (int, error) {
+	if script == "" {
+		return 0, nil
+	}
+
+	settings, raw, err := s.readSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for eventType, rules := range settings.Hooks {
+		var kept []HookRule
+		for _, rule := range rules {
+			var hooks []HookCommand
+			for _, h := range rule.Hooks {
+				if !RunsScript(h.Command, script) {
+					hooks = append(hooks, h)
+				}
+			}
+			removed += len(rule.Hooks) - len(hooks)
+			if len(hooks) == 0 && len(rule.Hooks) > 0 {
+				continue
+			}
+			rule.Hooks = hooks
+			kept = append(kept, rule)
+		}
+		settings.Hooks[eventType] = kept
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, s.writeSettings(settings, raw)
+}
+
+// RunsScript reports whether command refers to script as a whole path, so
+// hooks/foo.sh does not match hooks/foo.sh.bak.
+func RunsScript(command, script string) bool {
+	for i := 0; ; {
+		j := strings.Index(command[i:], script)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(script)
+		if (start == 0 || !isPathByte(command[start-1])) && (end == len(command) || !isPathByte(command[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// isPathByte reports whether b can be part of a file path in a command.
+func isPathByte(b byte) bool {
+	return b == '/' || b == '.' || b == '-' || b == '_' || b == '~' ||
+		('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
@@ -0,0 +1,188 @@
+package hook
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PayloadOptions customizes a simulated event payload. Empty fields get
+// realistic defaults.
+type PayloadOptions struct {
+	SessionID      string
+	CWD            string
+	TranscriptPath string
+	Tool           string // tool name for tool events; defaults to the first tool the matcher names, else Bash
+	Value          string // matched value for value events, e.g. SessionStart's source
+}
+
+// SamplePayload builds the JSON object Claude Code sends on stdin to a hook
+// of eventType configured with matcher.
+func SamplePayload(eventType EventType, matcher string, opts PayloadOptions) map[string]interface{} {
+	if opts.CWD == "" {
+		opts.CWD, _ = os.Getwd()
+	}
+	if opts.SessionID == "" {
+		opts.SessionID = newSessionID()
+	}
+	if opts.TranscriptPath == "" {
+		home, _ := os.UserHomeDir()
+		project := strings.NewReplacer("/", "-", "\\", "-", ":", "-", ".", "-").Replace(opts.CWD)
+		opts.TranscriptPath = filepath.Join(home, ".claude", "projects", project, opts.SessionID+".jsonl")
+	}
+
+	payload := map[string]interface{}{
+		"session_id":      opts.SessionID,
+		"transcript_path": opts.TranscriptPath,
+		"cwd":             opts.CWD,
+		"permission_mode": "default",
+		"hook_event_name": string(eventType),
+	}
+
+	event, _ := LookupEvent(eventType)
+	switch eventType {
+	case PreToolUse, PostToolUse:
+		tool := opts.Tool
+		if tool == "" {
+			tool = defaultTool(matcher)
+		}
+		payload["tool_name"] = tool
+		payload["tool_input"] = sampleToolInput(tool, opts.CWD)
+		if eventType == PostToolUse {
+			payload["tool_response"] = sampleToolResponse(tool, opts.CWD)
+		}
+	case Notification:
+		payload["message"] = "Claude needs your permission to use Bash"
+	case UserPromptSubmit:
+		payload["prompt"] = "Write a function that reverses a string"
+	case Stop, SubagentStop:
+		payload["stop_hook_active"] = false
+	case PreCompact:
+		payload["custom_instructions"] = ""
+	case SessionEnd:
+		payload["reason"] = "other"
+	}
+
+	if event.Matcher == MatcherValue {
+		value := opts.Value
+		if value == "" {
+			value = defaultValue(event, matcher)
+		}
+		payload[event.MatchField] = value
+	}
+	return payload
+}
+
+// defaultTool returns the first tool a matcher names, or Bash.
+func defaultTool(matcher string) string {
+	if m, err := ParseMatcher(matcher); err == nil {
+		if names := m.Names(); len(names) > 0 {
+			return names[0]
+		}
+	}
+	return "Bash"
+}
+
+// defaultValue returns the first known value a matcher selects, or the
+// event's first value.
+func defaultValue(event EventInfo, matcher string) string {
+	if m, err := ParseMatcher(matcher); err == nil {
+		for _, v := range event.MatcherValues {
+			if !m.MatchesAll() && m.Match(v) {
+				return v
+			}
+		}
+	}
+	if len(event.MatcherValues) > 0 {
+		return event.MatcherValues[0]
+	}
+	return ""
+}
+
+// sampleToolInput returns plausible input for a tool call.
+func sampleToolInput(tool, cwd string) map[string]interface{} {
+	file := filepath.Join(cwd, "example.txt")
+	switch tool {
+	case "Bash":
+		return map[string]interface{}{"command": "ls -la", "description": "List files in current directory"}
+	case "Read":
+		return map[string]interface{}{"file_path": file}
+	case "Write":
+		return map[string]interface{}{"file_path": file, "content": "Hello, world!\n"}
+	case "Edit":
+		return map[string]interface{}{"file_path": file, "old_string": "Hello", "new_string": "Goodbye"}
+	case "MultiEdit":
+		return map[string]interface{}{"file_path": file, "edits": []interface{}{
+			map[string]interface{}{"old_string": "Hello", "new_string": "Goodbye"},
+		}}
+	case "Glob":
+		return map[string]interface{}{"pattern": "**/*.go"}
+	case "Grep":
+		return map[string]interface{}{"pattern": "TODO", "path": cwd}
+	case "WebFetch":
+		return map[string]interface{}{"url": "https://example.com", "prompt": "Summarize this page"}
+	case "WebSearch":
+		return map[string]interface{}{"query": "golang context timeout"}
+	case "Task":
+		return map[string]interface{}{"description": "Find TODOs", "prompt": "List every TODO comment", "subagent_type": "general-purpose"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// sampleToolResponse returns a plausible result of a tool call.
+func sampleToolResponse(tool, cwd string) map[string]interface{} {
+	switch tool {
+	case "Bash":
+		return map[string]interface{}{"stdout": "example.txt\n", "stderr": "", "interrupted": false}
+	case "Write", "Edit", "MultiEdit":
+		return map[string]interface{}{"filePath": filepath.Join(cwd, "example.txt"), "success": true}
+	default:
+		return map[string]interface{}{"success": true}
+	}
+}
+
+// newSessionID returns a random UUID like the session IDs of Claude Code.
+func newSessionID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// SetPayloadField sets a field of a payload. Dotted keys address nested
+// objects (tool_input.command), which are created as needed. A value that
+// parses as JSON is used as such, anything else as a string.
+func SetPayloadField(payload map[string]interface{}, key, value string) error {
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		v = value
+	}
+
+	parts := strings.Split(key, ".")
+	m := payload
+	for _, part := range parts[:len(parts)-1] {
+		if part == "" {
+			return fmt.Errorf("invalid field: %s", key)
+		}
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			if _, exists := m[part]; exists {
+				return fmt.Errorf("field %s is not an object", part)
+			}
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+		m = next
+	}
+	last := parts[len(parts)-1]
+	if last == "" {
+		return fmt.Errorf("invalid field: %s", key)
+	}
+	m[last] = v
+	return nil
+}
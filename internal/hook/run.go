@@ -0,0 +1,109 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is how long Claude Code lets a hook command run when its
+// entry sets no timeout.
+const DefaultTimeout = 60 * time.Second
+
+// BlockingExitCode is the exit code with which a hook command blocks the
+// action it was called for; stderr is fed back to Claude.
+const BlockingExitCode = 2
+
+// Output is the JSON a hook command may print on stdout to control Claude
+// Code.
+type Output struct {
+	Continue           *bool                  `json:"continue,omitempty"`
+	StopReason         string                 `json:"stopReason,omitempty"`
+	SuppressOutput     bool                   `json:"suppressOutput,omitempty"`
+	SystemMessage      string                 `json:"systemMessage,omitempty"`
+	Decision           string                 `json:"decision,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	HookSpecificOutput map[string]interface{} `json:"hookSpecificOutput,omitempty"`
+}
+
+// RunResult is the outcome of running one hook command.
+type RunResult struct {
+	Command  string        `json:"command"`
+	Timeout  time.Duration `json:"-"`
+	ExitCode int           `json:"exit_code"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	Duration time.Duration `json:"-"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+	Output   *Output       `json:"output,omitempty"` // parsed stdout, if it is a JSON object
+	Err      error         `json:"-"`                // the command could not be started
+}
+
+// Blocking reports whether the command blocked the action, by exit code or
+// by a "block" decision.
+func (r *RunResult) Blocking() bool {
+	return r.ExitCode == BlockingExitCode || (r.Output != nil && r.Output.Decision == "block")
+}
+
+// RunCommand runs a hook command the way Claude Code does: through the
+// shell, with payload on stdin, in dir, and killed after timeout. A zero
+// timeout uses the entry's own timeout, or DefaultTimeout.
+func RunCommand(ctx context.Context, h HookCommand, payload []byte, dir string, timeout time.Duration) *RunResult {
+	if timeout <= 0 {
+		timeout = time.Duration(h.Timeout) * time.Second
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	result := &RunResult{Command: h.Command, Timeout: timeout, ExitCode: -1}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", h.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", h.Command)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CLAUDE_PROJECT_DIR="+dir)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait for background children holding the pipes open
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.TimedOut = true
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.Err = err
+	}
+
+	if trimmed := strings.TrimSpace(result.Stdout); strings.HasPrefix(trimmed, "{") {
+		var out Output
+		if err := json.Unmarshal([]byte(trimmed), &out); err == nil {
+			result.Output = &out
+		}
+	}
+	return result
+}
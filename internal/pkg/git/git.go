@@ -2,11 +2,17 @@ package git
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // IsInstalled checks if git is installed and available in PATH.
@@ -136,12 +142,41 @@ func EnsureInstalled() error {
 	return PromptInstall()
 }
 
+// CloneOptions limits what a clone fetches and checks out.
+type CloneOptions struct {
+	Depth  int      // commits of history to fetch; 0 fetches the full history
+	Sparse []string // directories to check out (cone mode); empty checks out everything
+}
+
 // Clone clones a repository to the specified path.
 func Clone(url, destPath string) error {
-	cmd := exec.Command("git", "clone", "--depth", "1", url, destPath)
+	return CloneWithOptions(url, destPath, CloneOptions{Depth: 1})
+}
+
+// CloneWithOptions clones a repository, printing git's progress. Sparse
+// clones are also partial clones: blobs outside the checked out directories
+// are fetched on demand when a commit's files are read.
+func CloneWithOptions(url, destPath string, opts CloneOptions) error {
+	args := []string{"clone"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if len(opts.Sparse) > 0 {
+		args = append(args, "--filter=blob:none", "--sparse")
+	}
+	args = append(args, url, destPath)
+
+	cmd := exec.Command("git", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	if len(opts.Sparse) > 0 {
+		return SetSparseCheckout(destPath, opts.Sparse)
+	}
+	return nil
 }
 
 // CloneQuiet clones a repository quietly.
@@ -150,6 +185,35 @@ func CloneQuiet(url, destPath string) error {
 	return cmd.Run()
 }
 
+// SetSparseCheckout limits the working tree to dirs and files at the root.
+func SetSparseCheckout(repoPath string, dirs []string) error {
+	args := append([]string{"-C", repoPath, "sparse-checkout", "set", "--cone", "--"}, dirs...)
+	return runContext(context.Background(), args...)
+}
+
+// SparseCheckoutDirs returns the directories of a cone-mode sparse
+// checkout, or nil if the working tree is complete.
+func SparseCheckoutDirs(repoPath string) []string {
+	cmd := exec.Command("git", "-C", repoPath, "config", "--bool", "core.sparseCheckout")
+	output, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(output)) != "true" {
+		return nil
+	}
+
+	cmd = exec.Command("git", "-C", repoPath, "sparse-checkout", "list")
+	output, err = cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs
+}
+
 // Pull pulls the latest changes in a repository.
 func Pull(repoPath string) error {
 	cmd := exec.Command("git", "-C", repoPath, "pull", "--ff-only")
@@ -158,16 +222,50 @@ func Pull(repoPath string) error {
 	return cmd.Run()
 }
 
+// FastForward fast-forwards the checked out branch to a commit already in
+// the clone, such as a fetched origin/<branch>. Nothing is fetched.
+func FastForward(repoPath, commit string) error {
+	return runContext(context.Background(), "-C", repoPath, "merge", "--ff-only", "--quiet", commit)
+}
+
 // PullQuiet pulls quietly.
 func PullQuiet(repoPath string) error {
-	cmd := exec.Command("git", "-C", repoPath, "pull", "--ff-only", "--quiet")
-	return cmd.Run()
+	return PullQuietContext(context.Background(), repoPath)
+}
+
+// PullQuietContext pulls quietly, stopping when ctx is done.
+func PullQuietContext(ctx context.Context, repoPath string) error {
+	return runContext(ctx, "-C", repoPath, "pull", "--ff-only", "--quiet")
 }
 
 // Fetch fetches the latest changes without merging.
 func Fetch(repoPath string) error {
-	cmd := exec.Command("git", "-C", repoPath, "fetch", "--quiet")
-	return cmd.Run()
+	return FetchContext(context.Background(), repoPath)
+}
+
+// FetchContext fetches the latest changes without merging, stopping when ctx is done.
+func FetchContext(ctx context.Context, repoPath string) error {
+	return runContext(ctx, "-C", repoPath, "fetch", "--quiet")
+}
+
+// runContext runs a non-interactive git command. Credential prompts are
+// disabled so commands running in parallel fail instead of blocking, and
+// the error includes git's stderr or the context error.
+func runContext(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
 }
 
 // GetCurrentCommit returns the current commit SHA.
@@ -251,3 +349,374 @@ func ListChangedFiles(repoPath, fromCommit, toCommit string) ([]string, error) {
 	}
 	return files, nil
 }
+
+// DiffCommits returns the unified diff of path between two commits.
+func DiffCommits(repoPath, fromCommit, toCommit, path string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "diff", "--no-color", "--no-ext-diff", fromCommit, toCommit, "--", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// Commit is a commit as listed by LogCommits.
+type Commit struct {
+	SHA     string    `json:"sha"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// LogCommits returns the commits reachable from toCommit but not from
+// fromCommit that touch path, newest first. An empty path lists all of them.
+func LogCommits(repoPath, fromCommit, toCommit, path string) ([]Commit, error) {
+	args := []string{"-C", repoPath, "log", "--no-color", "--format=%H%x1f%an%x1f%aI%x1f%s", fromCommit + ".." + toCommit}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{SHA: fields[0], Author: fields[1], Date: date, Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// DiffNoIndex returns the unified diff between two files or directories
+// outside a repository. Paths are relative to dir and shown without a/ b/
+// prefixes.
+func DiffNoIndex(dir, oldPath, newPath string) (string, error) {
+	cmd := exec.Command("git", "diff", "--no-index", "--no-color", "--no-ext-diff", "--no-prefix", "--", oldPath, newPath)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the paths differ
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return string(output), nil
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// Ref kinds returned by ResolveRef.
+const (
+	RefTag    = "tag"
+	RefBranch = "branch"
+	RefCommit = "commit"
+)
+
+// ResolvedRef is a ref resolved to a commit in a local clone.
+type ResolvedRef struct {
+	Name string // tag, branch or the ref as given for commits
+	Kind string // RefTag, RefBranch or RefCommit
+	SHA  string // full commit SHA
+}
+
+// shaRegex matches abbreviated or full commit SHAs.
+var shaRegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// revParse resolves a revision to a full SHA.
+func revParse(repoPath, rev string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", rev)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// resolveLocalRef resolves a tag, remote branch or commit that is already present locally.
+func resolveLocalRef(repoPath, ref string) *ResolvedRef {
+	if sha, err := revParse(repoPath, "refs/tags/"+ref+"^{commit}"); err == nil {
+		return &ResolvedRef{Name: ref, Kind: RefTag, SHA: sha}
+	}
+	if sha, err := revParse(repoPath, "refs/remotes/origin/"+ref+"^{commit}"); err == nil {
+		return &ResolvedRef{Name: ref, Kind: RefBranch, SHA: sha}
+	}
+	if shaRegex.MatchString(ref) {
+		if sha, err := revParse(repoPath, ref+"^{commit}"); err == nil {
+			return &ResolvedRef{Name: ref, Kind: RefCommit, SHA: sha}
+		}
+	}
+	return nil
+}
+
+// ResolveRef resolves a tag, branch or commit SHA to a commit.
+// Refs missing from the clone (shallow clones only carry the default branch)
+// are fetched from origin. The working tree is never touched.
+func ResolveRef(repoPath, ref string) (*ResolvedRef, error) {
+	if r := resolveLocalRef(repoPath, ref); r != nil {
+		return r, nil
+	}
+
+	refspecs := []string{
+		"+refs/tags/" + ref + ":refs/tags/" + ref,
+		"+refs/heads/" + ref + ":refs/remotes/origin/" + ref,
+	}
+	for _, refspec := range refspecs {
+		if runContext(context.Background(), "-C", repoPath, "fetch", "--quiet", "origin", refspec) != nil {
+			continue
+		}
+		if r := resolveLocalRef(repoPath, ref); r != nil {
+			return r, nil
+		}
+	}
+
+	// Commits older than a shallow clone's history are fetched on demand
+	if shaRegex.MatchString(ref) && EnsureCommit(repoPath, ref) == nil {
+		if r := resolveLocalRef(repoPath, ref); r != nil {
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown ref: %s", ref)
+}
+
+// pinnedRefPrefix holds refs that keep fetched commits reachable, so gc
+// does not prune commits that packages are installed from.
+const pinnedRefPrefix = "refs/jd/pinned/"
+
+// deepenSteps are the history depths tried, in turn, when looking for a
+// commit missing from a shallow clone, before fetching the full history.
+var deepenSteps = []int{50, 500}
+
+// HasCommit reports whether a commit is present in the local clone.
+// sha may be abbreviated.
+func HasCommit(repoPath, sha string) bool {
+	_, err := revParse(repoPath, sha+"^{commit}")
+	return err == nil
+}
+
+// EnsureCommit makes a commit available in the local clone. A full SHA is
+// fetched directly and pinned; otherwise a shallow clone is deepened step
+// by step, and finally unshallowed, until the commit appears.
+func EnsureCommit(repoPath, sha string) error {
+	if HasCommit(repoPath, sha) {
+		return nil
+	}
+
+	if len(sha) == 40 {
+		refspec := "+" + sha + ":" + pinnedRefPrefix + sha
+		if runContext(context.Background(), "-C", repoPath, "fetch", "--quiet", "origin", refspec) == nil && HasCommit(repoPath, sha) {
+			return nil
+		}
+	}
+
+	if IsShallow(repoPath) {
+		for _, depth := range deepenSteps {
+			if Deepen(repoPath, depth) != nil {
+				break
+			}
+			if HasCommit(repoPath, sha) {
+				return nil
+			}
+		}
+		if err := Unshallow(repoPath); err == nil && HasCommit(repoPath, sha) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("commit %s not found in %s", sha, repoPath)
+}
+
+// Deepen fetches the given number of additional commits of history into a
+// shallow clone.
+func Deepen(repoPath string, commits int) error {
+	return runContext(context.Background(), "-C", repoPath, "fetch", "--quiet", "--deepen="+strconv.Itoa(commits), "origin")
+}
+
+// PinCommits makes shas the pinned commits of a clone, replacing earlier
+// pins. Commits missing from the clone are skipped.
+func PinCommits(repoPath string, shas []string) error {
+	cmd := exec.Command("git", "-C", repoPath, "for-each-ref", "--format=%(refname)", pinnedRefPrefix)
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	for _, sha := range shas {
+		if full, err := revParse(repoPath, sha+"^{commit}"); err == nil {
+			keep[pinnedRefPrefix+full] = true
+			if err := runContext(context.Background(), "-C", repoPath, "update-ref", pinnedRefPrefix+full, full); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ref := range strings.Fields(string(output)) {
+		if !keep[ref] {
+			if err := runContext(context.Background(), "-C", repoPath, "update-ref", "-d", ref); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GC expires reflogs and prunes unreachable objects of a clone.
+func GC(repoPath string) error {
+	ctx := context.Background()
+	if err := runContext(ctx, "-C", repoPath, "reflog", "expire", "--expire=now", "--all"); err != nil {
+		return err
+	}
+	return runContext(ctx, "-C", repoPath, "gc", "--prune=now", "--quiet")
+}
+
+// Signature is the signature of a commit as reported by git.
+type Signature struct {
+	Status     string // %G? code: G good, U good with unknown validity, B bad, N none, ...
+	Signer     string // signer name or principal
+	Key        string // signing key fingerprint (SHA256:... for SSH keys)
+	PrimaryKey string // primary key fingerprint for GPG subkeys
+}
+
+// Good reports whether the signature is cryptographically valid. It says
+// nothing about whether the key is trusted.
+func (s *Signature) Good() bool {
+	return s.Status == "G" || s.Status == "U"
+}
+
+// CommitSignature returns the signature of a commit. SSH signatures are
+// checked against allowedSigners, an ssh-keygen allowed signers file; GPG
+// signatures against the user's keyring.
+func CommitSignature(repoPath, sha, allowedSigners string) (*Signature, error) {
+	cmd := exec.Command("git", "-C", repoPath, "-c", "gpg.ssh.allowedSignersFile="+allowedSigners,
+		"log", "-1", "--format=%G?%x00%GS%x00%GF%x00%GP", sha, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimRight(string(output), "\n"), "\x00")
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+	return &Signature{Status: fields[0], Signer: fields[1], Key: fields[2], PrimaryKey: fields[3]}, nil
+}
+
+// IsWorkTree reports whether path is inside a git working tree.
+func IsWorkTree(path string) bool {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--is-inside-work-tree")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// CommitPaths stages paths (relative to repoPath) and commits only them.
+// It returns false without committing if the paths have no changes.
+func CommitPaths(repoPath, message string, paths ...string) (bool, error) {
+	ctx := context.Background()
+	if err := runContext(ctx, append([]string{"-C", repoPath, "add", "--all", "--"}, paths...)...); err != nil {
+		return false, err
+	}
+
+	// diff --quiet exits 1 when there are staged changes
+	cmd := exec.Command("git", append([]string{"-C", repoPath, "diff", "--cached", "--quiet", "--"}, paths...)...)
+	if err := cmd.Run(); err == nil {
+		return false, nil
+	}
+
+	args := append([]string{"-C", repoPath, "commit", "--quiet", "-m", message, "--"}, paths...)
+	if err := runContext(ctx, args...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsShallow reports whether the repository is a shallow clone.
+func IsShallow(repoPath string) bool {
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--is-shallow-repository")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// Unshallow fetches the full history of a shallow clone.
+func Unshallow(repoPath string) error {
+	return runContext(context.Background(), "-C", repoPath, "fetch", "--quiet", "--unshallow", "origin")
+}
+
+// FetchBranch fetches a single branch into refs/remotes/origin/<branch>.
+func FetchBranch(repoPath, branch string) error {
+	return FetchBranchContext(context.Background(), repoPath, branch)
+}
+
+// FetchBranchContext is FetchBranch, stopping when ctx is done.
+func FetchBranchContext(ctx context.Context, repoPath, branch string) error {
+	refspec := "+refs/heads/" + branch + ":refs/remotes/origin/" + branch
+	return runContext(ctx, "-C", repoPath, "fetch", "--quiet", "origin", refspec)
+}
+
+// FetchTags fetches all tags from origin.
+func FetchTags(repoPath string) error {
+	return FetchTagsContext(context.Background(), repoPath)
+}
+
+// FetchTagsContext is FetchTags, stopping when ctx is done.
+func FetchTagsContext(ctx context.Context, repoPath string) error {
+	return runContext(ctx, "-C", repoPath, "fetch", "--quiet", "--tags", "origin")
+}
+
+// ListTags returns local tags mapped to the commit SHA they point to.
+func ListTags(repoPath string) (map[string]string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "for-each-ref",
+		"--format=%(refname:short) %(objectname) %(*objectname)", "refs/tags")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// Annotated tags carry the peeled commit in the third field
+		sha := fields[1]
+		if len(fields) == 3 {
+			sha = fields[2]
+		}
+		tags[fields[0]] = sha
+	}
+	return tags, nil
+}
+
+// ListTreeFiles returns all file paths under path in the given commit.
+func ListTreeFiles(repoPath, commit, path string) ([]string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "ls-tree", "-r", "-z", "--name-only", commit, "--", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range strings.Split(string(output), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// ReadFileAt returns the content of a file at the given commit.
+func ReadFileAt(repoPath, commit, path string) ([]byte, error) {
+	cmd := exec.Command("git", "-C", repoPath, "cat-file", "blob", commit+":"+path)
+	return cmd.Output()
+}
@@ -0,0 +1,99 @@
+package pkgmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallAs(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "commit.md"), "v1")
+		commitAll(t, upstream, "initial")
+	})
+
+	pkg, err := env.manager.InstallAs("test:commands/commit.md", "commit", false)
+	if err != nil {
+		t.Fatalf("InstallAs failed: %v", err)
+	}
+	if pkg.Name != "commit" || pkg.OriginalName != "commit" || !pkg.Alias {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+	target := filepath.Join(env.claudeDir, "commands", "commit.md")
+	if content, _ := os.ReadFile(target); string(content) != "v1" {
+		t.Fatalf("aliased command not installed: %q", content)
+	}
+
+	// The source cannot be installed again under the same alias
+	if _, err := env.manager.InstallAs("test:commands/commit.md", "commit", false); !errors.Is(err, ErrPackageAlreadyInstalled) {
+		t.Errorf("second InstallAs error = %v, want ErrPackageAlreadyInstalled", err)
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "commands", "commit.md"), "v2")
+	commitAll(t, env.upstream, "update")
+	runGit(t, env.clone, "pull", "--quiet")
+
+	updated, err := env.manager.Update("commit", UpdateOptions{})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Name != "commit" || !updated.Alias {
+		t.Errorf("update lost the alias: %+v", updated)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "v2" {
+		t.Errorf("aliased command not updated: %q", content)
+	}
+
+	if err := env.manager.Uninstall("commit"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("aliased command not removed: %v", err)
+	}
+}
+
+func TestInstallAsConflict(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "commit.md"), "package")
+		commitAll(t, upstream, "initial")
+	})
+
+	target := filepath.Join(env.claudeDir, "commands", "commit.md")
+	writeFile(t, target, "hand-written")
+
+	if _, err := env.manager.InstallAs("test:commands/commit.md", "commit", false); !errors.Is(err, ErrConflict) {
+		t.Fatalf("InstallAs error = %v, want ErrConflict", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "hand-written" {
+		t.Errorf("conflicting file was overwritten: %q", content)
+	}
+
+	for _, name := range []string{"../commit", "a/b", "-x", "a:b:c"} {
+		if _, err := env.manager.InstallAs("test:commands/commit.md", name, false); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("InstallAs(%q) error = %v, want ErrInvalidName", name, err)
+		}
+	}
+}
+
+func TestInstallUnprefixedRepo(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "demo")
+		commitAll(t, upstream, "initial")
+	})
+
+	if err := env.manager.RepoStore().SetUnprefixed("test", true); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if pkg.Name != "demo" || pkg.Alias {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+	if _, err := os.Stat(filepath.Join(env.claudeDir, "skills", "demo", "SKILL.md")); err != nil {
+		t.Errorf("unprefixed skill not installed: %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+// NeverFetch is a fetch max age that never fetches, so update checks only
+// use refs already in the local clones.
+const NeverFetch = time.Duration(math.MaxInt64)
+
+// SetFetchMaxAge sets the staleness window for update checks. Repositories
+// fetched within maxAge are checked against their local refs instead of
+// being fetched again. Zero always fetches; NeverFetch works fully offline.
+func (m *Manager) SetFetchMaxAge(maxAge time.Duration) {
+	m.fetchMaxAge = maxAge
+}
+
+// SetFetchOptions sets the worker count and per-repository timeout used
+// when fetching repositories for update checks.
+func (m *Manager) SetFetchOptions(opts repo.FetchOptions) {
+	m.fetchOpts = opts
+}
+
+// fetchIsFresh reports whether a repository was fetched within the
+// staleness window and can be checked without fetching.
+func (m *Manager) fetchIsFresh(namespace string) bool {
+	switch {
+	case m.fetchMaxAge <= 0:
+		return false
+	case m.fetchMaxAge == NeverFetch:
+		return true
+	}
+
+	last, err := m.repoStore.LastFetched(namespace)
+	return err == nil && !last.IsZero() && time.Since(last) < m.fetchMaxAge
+}
+
+// CheckFailure records a repository or package whose updates could not be checked.
+type CheckFailure struct {
+	Namespace string
+	Package   string // empty when fetching the whole repository failed
+	Err       error
+}
+
+// Error implements error.
+func (f CheckFailure) Error() string {
+	if f.Package != "" {
+		return fmt.Sprintf("%s: %v", f.Package, f.Err)
+	}
+	return fmt.Sprintf("%s: %v", f.Namespace, f.Err)
+}
+
+// Unwrap returns the underlying error.
+func (f CheckFailure) Unwrap() error {
+	return f.Err
+}
+
+// UpdateReport is the result of checking installed packages for updates.
+type UpdateReport struct {
+	Updates  []UpdateInfo      // one per package that could be checked
+	Fetched  []repo.RepoResult // fetch outcome per repository; repos within the staleness window are left out
+	Failures []CheckFailure
+}
+
+// Err returns the failures joined into one error, or nil if there were none.
+func (r *UpdateReport) Err() error {
+	errs := make([]error, len(r.Failures))
+	for i, f := range r.Failures {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
+
+// CheckUpdates checks installed packages for updates. Packages that could
+// not be checked are left out and reported in the returned error; use
+// CheckUpdatesContext for a per-repository breakdown.
+func (m *Manager) CheckUpdates(names ...string) ([]UpdateInfo, error) {
+	report, err := m.CheckUpdatesContext(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+	return report.Updates, report.Err()
+}
+
+// CheckUpdatesContext checks installed packages for updates. Each
+// repository is fetched once, in parallel, and packages are then checked
+// against the fetched refs. If names are given, only those packages are checked.
+func (m *Manager) CheckUpdatesContext(ctx context.Context, names ...string) (*UpdateReport, error) {
+	installed, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []*InstalledPackage
+	for i := range installed.Packages {
+		pkg := &installed.Packages[i]
+		if len(names) > 0 && !contains(names, pkg.Name) {
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	return m.checkUpdates(ctx, pkgs), nil
+}
+
+// checkPackageUpdate checks for updates for a single package.
+func (m *Manager) checkPackageUpdate(pkg *InstalledPackage) (*UpdateInfo, error) {
+	report := m.checkUpdates(context.Background(), []*InstalledPackage{pkg})
+	if len(report.Failures) > 0 {
+		return nil, report.Failures[0].Err
+	}
+	return &report.Updates[0], nil
+}
+
+// checkUpdates fetches the repositories of pkgs and computes their updates.
+func (m *Manager) checkUpdates(ctx context.Context, pkgs []*InstalledPackage) *UpdateReport {
+	report := &UpdateReport{}
+
+	fetches, namespaces := m.planFetches(pkgs)
+	started := time.Now().UTC()
+	report.Fetched = repo.ForEachRepo(ctx, namespaces, m.fetchOpts, func(ctx context.Context, namespace string) error {
+		return fetches[namespace].run(ctx)
+	})
+
+	failed := make(map[string]bool)
+	for _, r := range report.Fetched {
+		if r.Err != nil {
+			failed[r.Namespace] = true
+			report.Failures = append(report.Failures, CheckFailure{Namespace: r.Namespace, Err: r.Err})
+			continue
+		}
+		_ = m.repoStore.MarkFetched(r.Namespace, started)
+	}
+
+	for _, pkg := range pkgs {
+		if failed[pkg.Namespace] {
+			continue
+		}
+		info, err := m.updateInfo(pkg)
+		if err != nil {
+			report.Failures = append(report.Failures, CheckFailure{Namespace: pkg.Namespace, Package: pkg.Name, Err: err})
+			continue
+		}
+		report.Updates = append(report.Updates, *info)
+	}
+
+	return report
+}
+
+// repoFetch is the network work needed to check the packages of one repository.
+type repoFetch struct {
+	path          string
+	tags          bool     // fetch tags for tag-pinned packages
+	defaultBranch bool     // fetch the default branch
+	branches      []string // other tracked branches
+}
+
+// empty reports whether there is nothing to fetch.
+func (f *repoFetch) empty() bool {
+	return !f.tags && !f.defaultBranch && len(f.branches) == 0
+}
+
+// run performs the fetches.
+func (f *repoFetch) run(ctx context.Context) error {
+	if f.defaultBranch {
+		if err := git.FetchContext(ctx, f.path); err != nil {
+			return fmt.Errorf("fetch: %w", err)
+		}
+	}
+	if f.tags {
+		if err := git.FetchTagsContext(ctx, f.path); err != nil {
+			return fmt.Errorf("fetch tags: %w", err)
+		}
+	}
+	for _, branch := range f.branches {
+		if err := git.FetchBranchContext(ctx, f.path, branch); err != nil {
+			return fmt.Errorf("fetch branch %s: %w", branch, err)
+		}
+	}
+	return nil
+}
+
+// planFetches works out what each repository needs fetched to check pkgs.
+// Repositories within the staleness window are only fetched for branches
+// that were never fetched into the clone. Namespaces are returned in the
+// order they first appear.
+func (m *Manager) planFetches(pkgs []*InstalledPackage) (map[string]*repoFetch, []string) {
+	fetches := make(map[string]*repoFetch)
+	var namespaces []string
+
+	for _, pkg := range pkgs {
+		if pkg.Version.Pinned && pkg.Version.Type != VersionTypeTag {
+			continue // Pinned to a commit: nothing to fetch
+		}
+
+		repoConfig, err := m.repoStore.Get(pkg.Namespace)
+		if err != nil {
+			continue // Reported when the package is checked
+		}
+		repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+		if err != nil {
+			continue
+		}
+
+		f, ok := fetches[pkg.Namespace]
+		if !ok {
+			f = &repoFetch{path: repoLocalPath}
+			fetches[pkg.Namespace] = f
+		}
+		fresh := m.fetchIsFresh(pkg.Namespace)
+
+		switch {
+		case pkg.Version.Type == VersionTypeTag:
+			f.tags = f.tags || !fresh
+		default:
+			branch := pkg.Version.Ref
+			if branch == "" || branch == repoConfig.DefaultBranch {
+				f.defaultBranch = f.defaultBranch || !fresh
+				break
+			}
+			if contains(f.branches, branch) {
+				break
+			}
+			if !fresh {
+				f.branches = append(f.branches, branch)
+			} else if _, err := git.GetRemoteCommit(repoLocalPath, branch); err != nil && m.fetchMaxAge != NeverFetch {
+				// The branch was never fetched into this clone
+				f.branches = append(f.branches, branch)
+			}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if f, ok := fetches[pkg.Namespace]; ok && !f.empty() && !contains(namespaces, pkg.Namespace) {
+			namespaces = append(namespaces, pkg.Namespace)
+		}
+	}
+
+	return fetches, namespaces
+}
+
+// updateInfo computes the update for a package from the refs in its local clone.
+// Tag-pinned packages are offered the newest semver tag, commit-pinned
+// packages never update, and everything else follows its branch.
+func (m *Manager) updateInfo(pkg *InstalledPackage) (*UpdateInfo, error) {
+	repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	repoConfig, err := m.repoStore.Get(pkg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UpdateInfo{
+		Package:    pkg,
+		CurrentSHA: pkg.Version.SHA,
+		LatestSHA:  pkg.Version.SHA,
+		LatestRef:  pkg.Version.Ref,
+	}
+
+	switch {
+	case pkg.Version.Type == VersionTypeTag:
+		tags, err := git.ListTags(repoLocalPath)
+		if err != nil {
+			return nil, err
+		}
+		if latest := latestSemverTag(tags, pkg.Version.Ref); latest != "" {
+			info.LatestRef = latest
+			info.LatestSHA = tags[latest]
+		}
+
+	case pkg.Version.Pinned:
+		// Pinned to a commit: nothing to update to
+
+	default:
+		branch := pkg.Version.Ref
+		if branch == "" {
+			branch = repoConfig.DefaultBranch
+		}
+		latestSHA, err := git.GetRemoteCommit(repoLocalPath, branch)
+		if err != nil {
+			return nil, fmt.Errorf("resolve origin/%s: %w", branch, err)
+		}
+		info.LatestSHA = latestSHA
+		info.LatestRef = branch
+	}
+
+	info.HasUpdate = info.CurrentSHA != info.LatestSHA
+
+	if info.HasUpdate {
+		// The installed commit may predate a shallow clone's history
+		if m.fetchMaxAge != NeverFetch {
+			_ = git.EnsureCommit(repoLocalPath, pkg.Version.SHA)
+		}
+
+		// Get changed files
+		changedFiles, err := git.ListChangedFiles(repoLocalPath, pkg.Version.SHA, info.LatestSHA)
+		if err == nil {
+			for _, f := range changedFiles {
+				if strings.HasPrefix(f, pkg.SourcePath) {
+					info.ChangedFiles = append(info.ChangedFiles, f)
+				}
+			}
+		}
+	}
+
+	return info, nil
+}
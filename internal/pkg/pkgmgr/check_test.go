@@ -0,0 +1,72 @@
+package pkgmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckUpdatesFetchesEachRepoOnce(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "one.md"), "v1")
+		writeFile(t, filepath.Join(upstream, "commands", "two.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	for _, spec := range []string{"test:commands/one.md", "test:commands/two.md"} {
+		if _, err := env.manager.Install(spec, false); err != nil {
+			t.Fatalf("Install %s failed: %v", spec, err)
+		}
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "commands", "one.md"), "v2")
+	commitAll(t, env.upstream, "v2")
+
+	report, err := env.manager.CheckUpdatesContext(context.Background())
+	if err != nil {
+		t.Fatalf("CheckUpdatesContext failed: %v", err)
+	}
+	if len(report.Fetched) != 1 || report.Fetched[0].Namespace != "test" {
+		t.Errorf("expected a single fetch of test, got %+v", report.Fetched)
+	}
+	if len(report.Failures) != 0 || len(report.Updates) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, u := range report.Updates {
+		if !u.HasUpdate {
+			t.Errorf("%s: expected an update", u.Package.Name)
+		}
+	}
+}
+
+func TestCheckUpdatesReportsFailures(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// The remote disappears
+	if err := os.RemoveAll(env.upstream); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := env.manager.CheckUpdatesContext(context.Background())
+	if err != nil {
+		t.Fatalf("CheckUpdatesContext failed: %v", err)
+	}
+	if len(report.Updates) != 0 || len(report.Failures) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if f := report.Failures[0]; f.Namespace != "test" || f.Package != "" || f.Err == nil {
+		t.Errorf("unexpected failure: %+v", f)
+	}
+
+	if _, err := env.manager.CheckUpdates(); err == nil {
+		t.Error("CheckUpdates should return the failure")
+	}
+}
@@ -0,0 +1,239 @@
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/internal/pkg/scan"
+)
+
+// ErrConflict is returned when an install would overwrite files it does not own.
+var ErrConflict = errors.New("install conflicts with existing files")
+
+// ConflictKind classifies an install conflict.
+type ConflictKind string
+
+const (
+	// ConflictUnowned is a target already present but not installed by any package.
+	ConflictUnowned ConflictKind = "unowned"
+	// ConflictOwned is a target installed by a different package.
+	ConflictOwned ConflictKind = "owned"
+	// ConflictCommandName is a command invoked by the same name from another .claude directory.
+	ConflictCommandName ConflictKind = "command-name"
+)
+
+// Conflict is an existing file or command that an install would overwrite or shadow.
+type Conflict struct {
+	Kind    ConflictKind `json:"kind"`
+	Package string       `json:"package"`         // package being installed
+	Path    string       `json:"path"`            // conflicting file or skill directory
+	Owner   string       `json:"owner,omitempty"` // owning package for ConflictOwned
+}
+
+// String describes the conflict.
+func (c Conflict) String() string {
+	switch c.Kind {
+	case ConflictOwned:
+		return fmt.Sprintf("%s is installed by package %s", c.Path, c.Owner)
+	case ConflictCommandName:
+		return fmt.Sprintf("%s defines the same command as %s", c.Path, c.Package)
+	default:
+		return fmt.Sprintf("%s exists and is not managed by any package", c.Path)
+	}
+}
+
+// ConflictError lists the conflicts that stopped an install.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+// Error implements error.
+func (e *ConflictError) Error() string {
+	msg := fmt.Sprintf("%v: %s", ErrConflict, e.Conflicts[0])
+	if n := len(e.Conflicts) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// Unwrap returns ErrConflict.
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// InstallPlan describes what an install would do, without changing anything.
+type InstallPlan struct {
+	Packages  []PlannedInstall `json:"packages"` // in install order; the requested package is last
+	Conflicts []Conflict       `json:"conflicts,omitempty"`
+}
+
+// PlannedInstall is one package of an install plan.
+type PlannedInstall struct {
+	Name       string           `json:"name"`
+	Spec       string           `json:"spec"`
+	Type       repo.PackageType `json:"type"`
+	Dependency bool             `json:"dependency,omitempty"`
+	Targets    []string         `json:"targets"`         // files or skill directories to be written
+	Hooks      []InstalledHook  `json:"hooks,omitempty"` // settings.json rules a hook package registers
+	Findings   []scan.Finding   `json:"findings,omitempty"`
+}
+
+// AddPeerClaudeDir registers another .claude directory whose commands share
+// the slash-command namespace with this manager's, so installs can detect
+// command name collisions across user and project scope.
+func (m *Manager) AddPeerClaudeDir(dir string) {
+	m.peerDirs = append(m.peerDirs, dir)
+}
+
+// PlanInstall resolves a package and its dependencies and reports the files
+// they would write and any conflicts, without installing anything. alias is
+// the install name as for InstallAs; empty uses the default name.
+func (m *Manager) PlanInstall(specStr, alias string) (*InstallPlan, error) {
+	spec, installed, err := m.prepareInstall(specStr, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := m.planInstall(spec, installed, "", alias)
+	if err != nil {
+		return nil, err
+	}
+	return m.describePlan(plan, installed, nil)
+}
+
+// describePlan lists the targets of planned packages, scans them and checks
+// them for conflicts. replace is the installed package being updated, if any.
+func (m *Manager) describePlan(plan []plannedPackage, installed *InstalledFile2, replace *InstalledPackage) (*InstallPlan, error) {
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Map each installed entry to the package that owns it
+	owners := make(map[string]string)
+	for _, pkg := range installed.Packages {
+		for _, f := range pkg.Files {
+			if e, ok := entryOf(claudeDir, f.Target); ok {
+				owners[e] = pkg.Name
+			}
+		}
+	}
+
+	out := &InstallPlan{}
+	for i, p := range plan {
+		pkgType, _, _, err := m.packageIdentity(p.spec)
+		if err != nil {
+			return nil, err
+		}
+		name := p.name
+
+		findings, hooks, err := m.scanSpec(p.spec, pkgType, name)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := targetEntry(pkgType, p.spec.Path, name)
+		out.Packages = append(out.Packages, PlannedInstall{
+			Name:       name,
+			Spec:       formatSpec(p.spec),
+			Type:       pkgType,
+			Dependency: i < len(plan)-1,
+			Targets:    []string{filepath.Join(claudeDir, entry)},
+			Hooks:      hooks,
+			Findings:   findings,
+		})
+
+		if owner, ok := owners[entry]; ok {
+			if replace == nil || owner != replace.Name {
+				out.Conflicts = append(out.Conflicts, Conflict{Kind: ConflictOwned, Package: name, Path: filepath.Join(claudeDir, entry), Owner: owner})
+			}
+		} else if _, err := os.Lstat(filepath.Join(claudeDir, entry)); err == nil {
+			out.Conflicts = append(out.Conflicts, Conflict{Kind: ConflictUnowned, Package: name, Path: filepath.Join(claudeDir, entry)})
+		}
+
+		if pkgType == repo.TypeCommand {
+			out.Conflicts = append(out.Conflicts, m.commandNameConflicts(name)...)
+		}
+	}
+
+	return out, nil
+}
+
+// commandNameConflicts returns commands in peer .claude directories that
+// are invoked by the same name as the command package name.
+func (m *Manager) commandNameConflicts(name string) []Conflict {
+	claudeDir, _ := m.expandClaudeDir()
+
+	var conflicts []Conflict
+	for _, dir := range m.peerDirs {
+		peer, err := expandPath(dir)
+		if err != nil || peer == claudeDir {
+			continue
+		}
+		path := filepath.Join(peer, "commands", nestedFileName(name))
+		if _, err := os.Stat(path); err == nil {
+			conflicts = append(conflicts, Conflict{Kind: ConflictCommandName, Package: name, Path: path})
+		}
+	}
+	return conflicts
+}
+
+// overwriteConflicts returns the conflicts that replace files on disk.
+// Command name collisions only shadow another command and are not included.
+func overwriteConflicts(conflicts []Conflict) []Conflict {
+	var out []Conflict
+	for _, c := range conflicts {
+		if c.Kind != ConflictCommandName {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// targetEntry returns the entry a package is installed as, relative to the
+// .claude directory: its skill directory or its single file.
+func targetEntry(pkgType repo.PackageType, path, namespacedName string) string {
+	switch pkgType {
+	case repo.TypeSkill:
+		return filepath.Join("skills", namespacedName)
+	case repo.TypeCommand:
+		return filepath.Join("commands", nestedFileName(namespacedName))
+	case repo.TypeAgent:
+		return filepath.Join("agents", nestedFileName(namespacedName))
+	default:
+		return filepath.Join("hooks", hookFileName(path, namespacedName))
+	}
+}
+
+// hookFileName returns the installed file name of a hook script: the
+// namespaced name with the script's extension.
+func hookFileName(path, namespacedName string) string {
+	name := namespacedName
+	if ext := filepath.Ext(filepath.Base(path)); ext != "" && !strings.HasSuffix(name, ext) {
+		name += ext
+	}
+	return name
+}
+
+// releaseEntries removes files under entries from every package except
+// keep, so a forced install takes ownership of the files it overwrote.
+func releaseEntries(installed *InstalledFile2, claudeDir string, entries []string, keep string) {
+	for i := range installed.Packages {
+		pkg := &installed.Packages[i]
+		if pkg.Name == keep {
+			continue
+		}
+		var files []InstalledFile
+		for _, f := range pkg.Files {
+			if e, ok := entryOf(claudeDir, f.Target); ok && contains(entries, e) {
+				continue
+			}
+			files = append(files, f)
+		}
+		pkg.Files = files
+	}
+}
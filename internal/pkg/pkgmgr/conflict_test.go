@@ -0,0 +1,146 @@
+package pkgmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallConflictUnowned(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "package")
+		commitAll(t, upstream, "initial")
+	})
+
+	target := filepath.Join(env.claudeDir, "commands", "test--hello.md")
+	writeFile(t, target, "hand-written")
+
+	plan, err := env.manager.PlanInstall("test:commands/hello.md", "")
+	if err != nil {
+		t.Fatalf("PlanInstall failed: %v", err)
+	}
+	if len(plan.Packages) != 1 || len(plan.Conflicts) != 1 || plan.Conflicts[0].Kind != ConflictUnowned || plan.Conflicts[0].Path != target {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	_, err = env.manager.Install("test:commands/hello.md", false)
+	var conflictErr *ConflictError
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 {
+		t.Fatalf("Install error = %v, want a conflict", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "hand-written" {
+		t.Errorf("conflicting file was overwritten: %q", content)
+	}
+
+	if _, err := env.manager.Install("test:commands/hello.md", true); err != nil {
+		t.Fatalf("forced Install failed: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "package" {
+		t.Errorf("forced install did not overwrite: %q", content)
+	}
+}
+
+func TestInstallConflictOwned(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "demo")
+		commitAll(t, upstream, "initial")
+	})
+
+	if _, err := env.manager.Install("test:skills/demo", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// Another package claims the skill directory
+	installed, err := env.manager.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	installed.Packages[0].Name = "other--demo"
+	if err := env.manager.save(installed); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := env.manager.PlanInstall("test:skills/demo", "")
+	if err != nil {
+		t.Fatalf("PlanInstall failed: %v", err)
+	}
+	if len(plan.Conflicts) != 1 || plan.Conflicts[0].Kind != ConflictOwned || plan.Conflicts[0].Owner != "other--demo" {
+		t.Fatalf("unexpected conflicts: %+v", plan.Conflicts)
+	}
+
+	if _, err := env.manager.Install("test:skills/demo", false); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Install error = %v, want %v", err, ErrConflict)
+	}
+	if _, err := env.manager.Install("test:skills/demo", true); err != nil {
+		t.Fatalf("forced Install failed: %v", err)
+	}
+
+	// The overwritten files now belong to the new package only
+	other, err := env.manager.Get("other--demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Files) != 0 {
+		t.Errorf("other package still owns %d file(s)", len(other.Files))
+	}
+}
+
+func TestInstallCommandNameConflict(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "package")
+		commitAll(t, upstream, "initial")
+	})
+
+	peer := t.TempDir()
+	writeFile(t, filepath.Join(peer, "commands", "test--hello.md"), "project command")
+	env.manager.AddPeerClaudeDir(peer)
+
+	plan, err := env.manager.PlanInstall("test:commands/hello.md", "")
+	if err != nil {
+		t.Fatalf("PlanInstall failed: %v", err)
+	}
+	if len(plan.Conflicts) != 1 || plan.Conflicts[0].Kind != ConflictCommandName {
+		t.Fatalf("unexpected conflicts: %+v", plan.Conflicts)
+	}
+
+	// Name collisions shadow a command but overwrite nothing
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+}
+
+func TestUpdateConflictWithLocalEdits(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	pkg, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	writeFile(t, filepath.Join(env.claudeDir, "skills", pkg.Name, "SKILL.md"), "edited")
+
+	// The new version pulls in a command that collides with a hand-written one
+	writeFile(t, filepath.Join(env.upstream, "skills", "demo", "SKILL.md"), "---\nname: demo\nrequires: commands/hello.md\n---\nv2")
+	writeFile(t, filepath.Join(env.upstream, "commands", "hello.md"), "package")
+	commitAll(t, env.upstream, "v2")
+	target := filepath.Join(env.claudeDir, "commands", "test--hello.md")
+	writeFile(t, target, "hand-written")
+
+	// Overwriting local edits still checks for conflicts
+	if _, err := env.manager.Update(pkg.Name, UpdateOptions{OverwriteLocal: true}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Update error = %v, want %v", err, ErrConflict)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "hand-written" {
+		t.Errorf("conflicting file was overwritten: %q", content)
+	}
+
+	if _, err := env.manager.Update(pkg.Name, UpdateOptions{OverwriteLocal: true, ForceConflicts: true}); err != nil {
+		t.Fatalf("forced Update failed: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "package" {
+		t.Errorf("forced update did not overwrite: %q", content)
+	}
+}
@@ -0,0 +1,289 @@
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+var (
+	// ErrDependencyCycle is returned when package dependencies form a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+	// ErrDependencyConflict is returned when a dependency is required in incompatible ways.
+	ErrDependencyConflict = errors.New("dependency conflict")
+)
+
+// plannedPackage is a package to install as part of a dependency graph.
+type plannedPackage struct {
+	spec     *InstallSpec
+	name     string
+	requires []string // installed names of direct dependencies
+}
+
+// depPlanner walks the dependency graph of a package.
+type depPlanner struct {
+	m         *Manager
+	installed *InstalledFile2
+	replacing string // installed package being updated, if any
+	rootName  string // install name of the root package, if not the default
+	specs     map[string]*InstallSpec
+	visiting  map[string]bool
+	order     []plannedPackage
+}
+
+// planInstall resolves the dependency graph of root and returns the
+// packages to install in dependency order, ending with root. Dependencies
+// that are already installed are left out. replacing names an installed
+// package that root replaces, so it is not treated as satisfied. rootName
+// overrides the install name of root.
+func (m *Manager) planInstall(root *InstallSpec, installed *InstalledFile2, replacing, rootName string) ([]plannedPackage, error) {
+	p := &depPlanner{
+		m:         m,
+		installed: installed,
+		replacing: replacing,
+		rootName:  rootName,
+		specs:     make(map[string]*InstallSpec),
+		visiting:  make(map[string]bool),
+	}
+	if _, err := p.visit(root, nil); err != nil {
+		return nil, err
+	}
+	return p.order, nil
+}
+
+// visit plans spec and its dependencies and returns its installed name.
+func (p *depPlanner) visit(spec *InstallSpec, stack []string) (string, error) {
+	_, _, name, err := p.m.packageIdentity(spec)
+	if err != nil {
+		return "", err
+	}
+	if len(stack) == 0 && p.rootName != "" {
+		name = p.rootName
+	}
+
+	if p.visiting[name] {
+		return "", fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(stack, name), " -> "))
+	}
+	if prev, ok := p.specs[name]; ok {
+		if *prev != *spec {
+			return "", fmt.Errorf("%w: %s is required as both %s and %s", ErrDependencyConflict, name, formatSpec(prev), formatSpec(spec))
+		}
+		return name, nil
+	}
+	p.specs[name] = spec
+
+	if len(stack) > 0 && name != p.replacing {
+		pkg := findPackage(p.installed, name)
+		if pkg == nil {
+			// The dependency may be installed under an alias
+			if alias := findSource(p.installed, spec.Namespace, spec.Path); alias != nil && alias.Name != p.replacing {
+				pkg = alias
+			}
+		}
+		if pkg != nil {
+			name = pkg.Name
+			if err := checkInstalledDependency(pkg, spec); err != nil {
+				return "", fmt.Errorf("%w (required by %s)", err, stack[len(stack)-1])
+			}
+			return name, nil
+		}
+	}
+
+	requires, err := p.m.readRequires(spec)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	p.visiting[name] = true
+	var deps []string
+	for _, ref := range requires {
+		depSpec, err := parseDependency(ref, spec)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		depName, err := p.visit(depSpec, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+		if !contains(deps, depName) {
+			deps = append(deps, depName)
+		}
+	}
+	p.visiting[name] = false
+
+	p.order = append(p.order, plannedPackage{spec: spec, name: name, requires: deps})
+	return name, nil
+}
+
+// findSource returns the installed package copied from path in namespace, or nil.
+func findSource(installed *InstalledFile2, namespace, path string) *InstalledPackage {
+	for i := range installed.Packages {
+		if installed.Packages[i].Namespace == namespace && installed.Packages[i].SourcePath == path {
+			return &installed.Packages[i]
+		}
+	}
+	return nil
+}
+
+// readRequires returns the dependencies a package declares at the requested version.
+func (m *Manager) readRequires(spec *InstallSpec) ([]string, error) {
+	repoConfig, err := m.repoStore.Get(spec.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("repository not found: %w", err)
+	}
+
+	repoLocalPath, err := m.repoStore.RepoLocalPath(spec.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	_, src, err := m.resolveVersion(repoLocalPath, repoConfig, spec.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.ReadRequires(determinePackageType(spec.Path), spec.Path, src.readFile)
+}
+
+// parseDependency parses a requires entry. Entries without a namespace refer
+// to the parent's repository and inherit its version, so a package pinned to
+// a tag pulls its dependencies from the same tag.
+func parseDependency(ref string, parent *InstallSpec) (*InstallSpec, error) {
+	colon := strings.Index(ref, ":")
+	if colon != -1 && !strings.Contains(ref[:colon], "/") {
+		return ParseSpec(ref)
+	}
+
+	spec := &InstallSpec{Namespace: parent.Namespace, Path: ref, Version: parent.Version}
+	if idx := strings.LastIndex(ref, "@"); idx != -1 {
+		spec.Path, spec.Version = ref[:idx], ref[idx+1:]
+	}
+	spec.Path = strings.TrimSuffix(strings.TrimPrefix(spec.Path, "./"), "/")
+	if spec.Path == "" || determinePackageType(spec.Path) == "" {
+		return nil, fmt.Errorf("%w: requires entry %q", ErrInvalidSpec, ref)
+	}
+	return spec, nil
+}
+
+// checkInstalledDependency reports whether an installed package satisfies a
+// dependency: it must come from the same source path and, if the dependency
+// names a version, be installed at that tag, branch or commit.
+func checkInstalledDependency(pkg *InstalledPackage, spec *InstallSpec) error {
+	if pkg.SourcePath != spec.Path {
+		return fmt.Errorf("%w: %s is installed from %s, not %s", ErrDependencyConflict, pkg.Name, pkg.SourcePath, spec.Path)
+	}
+	if spec.Version == "" || spec.Version == pkg.Version.Ref || strings.HasPrefix(pkg.Version.SHA, spec.Version) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is installed at %s, but %s is required", ErrDependencyConflict, pkg.Name, versionLabel(pkg.Version), spec.Version)
+}
+
+// versionLabel returns the ref of a version, or its SHA for commit pins.
+func versionLabel(v VersionInfo) string {
+	if v.Ref != "" {
+		return v.Ref
+	}
+	return v.SHA
+}
+
+// formatSpec formats an install spec as namespace:path[@version].
+func formatSpec(spec *InstallSpec) string {
+	s := spec.Namespace + ":" + spec.Path
+	if spec.Version != "" {
+		s += "@" + spec.Version
+	}
+	return s
+}
+
+// installPlan installs planned packages in order. The last one is the
+// requested package and replaces replace if set; the others are recorded as
+// dependencies. If an install fails, dependencies installed so far are
+// removed again.
+func (m *Manager) installPlan(plan []plannedPackage, replace *InstalledPackage) (*InstalledPackage, error) {
+	var done []string
+	for i, p := range plan {
+		opts := installOptions{name: p.name, requires: p.requires}
+		last := i == len(plan)-1
+		if last {
+			opts.replace = replace
+			opts.dependency = replace != nil && replace.Dependency
+		} else {
+			opts.dependency = true
+		}
+
+		pkg, err := m.install(p.spec, opts)
+		if err != nil {
+			for j := len(done) - 1; j >= 0; j-- {
+				_ = m.Uninstall(done[j])
+			}
+			if !last {
+				err = fmt.Errorf("install dependency %s: %w", p.name, err)
+			}
+			return nil, err
+		}
+		if last {
+			return pkg, nil
+		}
+		done = append(done, pkg.Name)
+	}
+	return nil, errors.New("empty install plan")
+}
+
+// Dependents returns the names of installed packages that require name.
+func (m *Manager) Dependents(name string) ([]string, error) {
+	installed, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, pkg := range installed.Packages {
+		if contains(pkg.Requires, name) {
+			names = append(names, pkg.Name)
+		}
+	}
+	return names, nil
+}
+
+// Orphans returns packages that were installed as dependencies but are no
+// longer required by any explicitly installed package, directly or through
+// other dependencies.
+func (m *Manager) Orphans() ([]InstalledPackage, error) {
+	installed, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*InstalledPackage, len(installed.Packages))
+	var queue []string
+	for i := range installed.Packages {
+		pkg := &installed.Packages[i]
+		byName[pkg.Name] = pkg
+		if !pkg.Dependency {
+			queue = append(queue, pkg.Name)
+		}
+	}
+
+	needed := make(map[string]bool)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if needed[name] {
+			continue
+		}
+		needed[name] = true
+		if pkg, ok := byName[name]; ok {
+			queue = append(queue, pkg.Requires...)
+		}
+	}
+
+	var orphans []InstalledPackage
+	for _, pkg := range installed.Packages {
+		if !needed[pkg.Name] {
+			orphans = append(orphans, pkg)
+		}
+	}
+	return orphans, nil
+}
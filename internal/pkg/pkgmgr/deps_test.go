@@ -0,0 +1,119 @@
+package pkgmgr
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDependency(t *testing.T) {
+	parent := &InstallSpec{Namespace: "test", Path: "skills/demo", Version: "v1.0.0"}
+
+	tests := []struct {
+		ref     string
+		want    InstallSpec
+		wantErr bool
+	}{
+		{ref: "agents/reviewer.md", want: InstallSpec{Namespace: "test", Path: "agents/reviewer.md", Version: "v1.0.0"}},
+		{ref: "./skills/helper/", want: InstallSpec{Namespace: "test", Path: "skills/helper", Version: "v1.0.0"}},
+		{ref: "commands/game:init.md@main", want: InstallSpec{Namespace: "test", Path: "commands/game:init.md", Version: "main"}},
+		{ref: "other:skills/x@v2", want: InstallSpec{Namespace: "other", Path: "skills/x", Version: "v2"}},
+		{ref: "README.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := parseDependency(tt.ref, parent)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDependency failed: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallDependencies(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"),
+			"---\nname: demo\nrequires:\n  - agents/reviewer.md\n---\n# Demo\n")
+		writeFile(t, filepath.Join(upstream, "agents", "reviewer.md"),
+			"---\nname: reviewer\nrequires: commands/hello.md\n---\n")
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "hello")
+		writeFile(t, filepath.Join(upstream, "commands", "greet.md"), "greet")
+		writeFile(t, filepath.Join(upstream, "commands", "greet.jd-package.yaml"), "requires:\n  - commands/hello.md\n")
+		commitAll(t, upstream, "initial")
+	})
+
+	pkg, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if pkg.Dependency || len(pkg.Requires) != 1 || pkg.Requires[0] != "test--reviewer" {
+		t.Errorf("unexpected root package: dependency=%t requires=%v", pkg.Dependency, pkg.Requires)
+	}
+
+	reviewer, err := env.manager.Get("test--reviewer")
+	if err != nil {
+		t.Fatalf("dependency not installed: %v", err)
+	}
+	if !reviewer.Dependency || len(reviewer.Requires) != 1 || reviewer.Requires[0] != "test--hello" {
+		t.Errorf("unexpected dependency: dependency=%t requires=%v", reviewer.Dependency, reviewer.Requires)
+	}
+	if _, err := env.manager.Get("test--hello"); err != nil {
+		t.Fatalf("transitive dependency not installed: %v", err)
+	}
+
+	// Sidecar manifest; hello is already installed and satisfies it
+	greet, err := env.manager.Install("test:commands/greet.md", false)
+	if err != nil {
+		t.Fatalf("Install greet failed: %v", err)
+	}
+	if len(greet.Requires) != 1 || greet.Requires[0] != "test--hello" {
+		t.Errorf("greet requires = %v", greet.Requires)
+	}
+
+	dependents, err := env.manager.Dependents("test--hello")
+	if err != nil || len(dependents) != 2 {
+		t.Errorf("Dependents = %v, %v; want 2", dependents, err)
+	}
+
+	if err := env.manager.Uninstall(pkg.Name); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	orphans, err := env.manager.Orphans()
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].Name != "test--reviewer" {
+		t.Errorf("orphans = %+v, want only test--reviewer", orphans)
+	}
+}
+
+func TestInstallDependencyErrors(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "agents", "a.md"), "---\nrequires: [agents/b.md]\n---\n")
+		writeFile(t, filepath.Join(upstream, "agents", "b.md"), "---\nrequires: [agents/a.md]\n---\n")
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "---\nrequires: [agents/missing.md]\n---\n")
+		commitAll(t, upstream, "initial")
+	})
+
+	if _, err := env.manager.Install("test:agents/a.md", false); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Install error = %v, want %v", err, ErrDependencyCycle)
+	}
+
+	if _, err := env.manager.Install("test:skills/demo", false); err == nil {
+		t.Error("expected error for missing dependency")
+	}
+
+	if pkgs, _ := env.manager.List(); len(pkgs) != 0 {
+		t.Errorf("manifest lists %d packages after failed installs, want 0", len(pkgs))
+	}
+}
@@ -0,0 +1,135 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+)
+
+// PackageDiff shows what an update of an installed package would change.
+type PackageDiff struct {
+	Update   UpdateInfo
+	Upstream string // unified diff of the package source between the installed and latest commits
+	Local    string // unified diff of installed files edited since install, against what was installed
+}
+
+// Empty reports whether there are neither upstream nor local changes.
+func (d *PackageDiff) Empty() bool {
+	return d.Upstream == "" && d.Local == ""
+}
+
+// Diff checks a package for updates and returns the upstream and local
+// changes. Repositories are fetched as for CheckUpdates.
+func (m *Manager) Diff(name string) (*PackageDiff, error) {
+	pkg, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := m.checkPackageUpdate(pkg)
+	if err != nil {
+		return nil, err
+	}
+	return m.DiffUpdate(*info)
+}
+
+// DiffUpdate returns the changes of an already checked update without
+// checking again. Only a missing installed commit is fetched, and only if
+// fetching is not disabled with NeverFetch.
+func (m *Manager) DiffUpdate(info UpdateInfo) (*PackageDiff, error) {
+	pkg := info.Package
+	repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// The installed commit may predate a shallow clone's history
+	if m.fetchMaxAge == NeverFetch {
+		if !git.HasCommit(repoLocalPath, info.CurrentSHA) {
+			return nil, fmt.Errorf("installed commit %s is not in the local clone and fetching is disabled", shortCommit(info.CurrentSHA))
+		}
+	} else if err := git.EnsureCommit(repoLocalPath, info.CurrentSHA); err != nil {
+		return nil, err
+	}
+
+	diff := &PackageDiff{Update: info}
+	if info.HasUpdate {
+		diff.Upstream, err = git.DiffCommits(repoLocalPath, info.CurrentSHA, info.LatestSHA, pkg.SourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("diff %s..%s: %w", shortCommit(info.CurrentSHA), shortCommit(info.LatestSHA), err)
+		}
+	}
+
+	diff.Local, err = m.localDiff(pkg, repoLocalPath)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// localDiff diffs the locally changed files of a package against the
+// content it was installed with. Paths are shown relative to the .claude
+// directory under installed/ and local/.
+func (m *Manager) localDiff(pkg *InstalledPackage, repoLocalPath string) (string, error) {
+	status, err := m.packageStatus(pkg)
+	if err != nil {
+		return "", err
+	}
+	if status.IsClean() {
+		return "", nil
+	}
+
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return "", err
+	}
+	sources := make(map[string]string, len(pkg.Files))
+	for _, f := range pkg.Files {
+		sources[f.Target] = f.Source
+	}
+
+	dir, err := os.MkdirTemp("", "jd-diff-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	place := func(side, target string, data []byte) error {
+		rel, err := filepath.Rel(claudeDir, target)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(dir, side, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(dest, data, 0644)
+	}
+
+	changed := append(append(append([]string{}, status.Modified...), status.Missing...), status.Extra...)
+	for _, target := range changed {
+		if source, ok := sources[target]; ok {
+			data, err := git.ReadFileAt(repoLocalPath, pkg.Version.SHA, source)
+			if err != nil {
+				return "", fmt.Errorf("read %s at %s: %w", source, shortCommit(pkg.Version.SHA), err)
+			}
+			if err := place("installed", target, data); err != nil {
+				return "", err
+			}
+		}
+		if data, err := os.ReadFile(target); err == nil {
+			if err := place("local", target, data); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// Both sides must exist for a directory diff
+	for _, side := range []string{"installed", "local"} {
+		if err := os.MkdirAll(filepath.Join(dir, side), 0755); err != nil {
+			return "", err
+		}
+	}
+	return git.DiffNoIndex(dir, "installed", "local")
+}
@@ -0,0 +1,55 @@
+package pkgmgr
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "line one\n")
+		writeFile(t, filepath.Join(upstream, "skills", "other", "SKILL.md"), "other\n")
+		commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:skills/demo", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	diff, err := env.manager.Diff("test--demo")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("expected empty diff, got %+v", diff)
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "skills", "demo", "SKILL.md"), "line one\nline two\n")
+	writeFile(t, filepath.Join(env.upstream, "skills", "other", "SKILL.md"), "changed\n")
+	commitAll(t, env.upstream, "v2")
+	writeFile(t, filepath.Join(env.claudeDir, "skills", "test--demo", "SKILL.md"), "my edit\n")
+
+	diff, err = env.manager.Diff("test--demo")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !diff.Update.HasUpdate {
+		t.Fatal("expected an update")
+	}
+	if !strings.Contains(diff.Upstream, "+line two") || strings.Contains(diff.Upstream, "skills/other") {
+		t.Errorf("upstream diff:\n%s", diff.Upstream)
+	}
+	if !strings.Contains(diff.Local, "-line one") || !strings.Contains(diff.Local, "+my edit") ||
+		!strings.Contains(diff.Local, "local/skills/test--demo/SKILL.md") {
+		t.Errorf("local diff:\n%s", diff.Local)
+	}
+
+	// Offline, a missing installed commit is an error rather than a fetch
+	env.manager.SetFetchMaxAge(NeverFetch)
+	info := diff.Update
+	info.CurrentSHA = strings.Repeat("0", 40)
+	if _, err := env.manager.DiffUpdate(info); err == nil || !strings.Contains(err.Error(), "fetching is disabled") {
+		t.Errorf("DiffUpdate offline = %v, want fetching is disabled", err)
+	}
+}
@@ -0,0 +1,184 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/hook"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+const (
+	settingsFileName = "settings.json"
+
+	// scriptPlaceholder is replaced by the installed script in hook command templates.
+	scriptPlaceholder = "{{script}}"
+)
+
+// hookBindings reads the manifest of a hook package and renders its rules
+// for the script installed as namespacedName. Packages without a manifest
+// register no rules.
+func (m *Manager) hookBindings(src packageSource, path, namespacedName string) ([]InstalledHook, error) {
+	manifest, err := repo.ReadManifest(repo.TypeHook, path, src.readFile)
+	if err != nil || manifest == nil {
+		return nil, err
+	}
+
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+	script, err := m.scriptReference(filepath.Join(claudeDir, targetEntry(repo.TypeHook, path, namespacedName)))
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []InstalledHook
+	for _, b := range manifest.Hooks {
+		event, err := hook.ParseEventType(b.Event)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repo.ManifestPath(repo.TypeHook, path), err)
+		}
+
+		command := b.Command
+		if command == "" {
+			command = scriptPlaceholder
+		}
+		// Rules are found again by the script they run
+		if !strings.Contains(command, scriptPlaceholder) {
+			return nil, fmt.Errorf("%s: hook command %q does not run %s", repo.ManifestPath(repo.TypeHook, path), command, scriptPlaceholder)
+		}
+
+		hooks = append(hooks, InstalledHook{
+			Event:   string(event),
+			Matcher: b.Matcher,
+			Command: strings.ReplaceAll(command, scriptPlaceholder, script),
+			Script:  script,
+		})
+	}
+
+	return hooks, nil
+}
+
+// scriptReference returns how hook commands refer to an installed script.
+// Project installs go through $CLAUDE_PROJECT_DIR so settings.json can be committed.
+func (m *Manager) scriptReference(target string) (string, error) {
+	if m.project {
+		claudeDir, err := m.expandClaudeDir()
+		if err != nil {
+			return "", err
+		}
+		if rel, err := filepath.Rel(claudeDir, target); err == nil {
+			return `"$CLAUDE_PROJECT_DIR"/.claude/` + filepath.ToSlash(rel), nil
+		}
+	}
+	return shellQuote(target), nil
+}
+
+// shellQuote quotes s for a POSIX shell if it contains special characters.
+func shellQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\n'\"$`\\;&|<>()*?[]#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// hookStore returns the hook store for the settings.json next to installed packages.
+func (m *Manager) hookStore() (*hook.Store, error) {
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+	return hook.NewStore(filepath.Join(claudeDir, settingsFileName)), nil
+}
+
+// syncHookRules removes the settings.json rules registered for prev and
+// registers the rules of next. Either may be nil. Rules of prev are found
+// by the script they run, so edits to their matcher or other fields do not
+// leave them behind.
+func (m *Manager) syncHookRules(prev, next *InstalledPackage) error {
+	if (prev == nil || len(prev.Hooks) == 0) && (next == nil || len(next.Hooks) == 0) {
+		return nil
+	}
+
+	store, err := m.hookStore()
+	if err != nil {
+		return err
+	}
+
+	if prev != nil && len(prev.Hooks) > 0 {
+		script, err := m.hookScript(prev)
+		if err != nil {
+			return err
+		}
+		if _, err := store.RemoveScriptRules(script); err != nil {
+			return fmt.Errorf("remove hook rules: %w", err)
+		}
+	}
+
+	if next != nil {
+		for _, h := range next.Hooks {
+			if _, err := store.Add(hook.EventType(h.Event), h.Matcher, []string{h.Command}); err != nil {
+				return fmt.Errorf("register hook rule: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hookScript returns how the hook rules of an installed package refer to
+// its script. Packages installed before it was recorded get it from the
+// script's install location.
+func (m *Manager) hookScript(pkg *InstalledPackage) (string, error) {
+	for _, h := range pkg.Hooks {
+		if h.Script != "" {
+			return h.Script, nil
+		}
+	}
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return "", err
+	}
+	return m.scriptReference(filepath.Join(claudeDir, targetEntry(repo.TypeHook, pkg.SourcePath, pkg.Name)))
+}
+
+// HookOwner returns the name of the installed hook package whose script
+// one of commands runs, or an empty string if there is none.
+func (m *Manager) HookOwner(commands []string) (string, error) {
+	installed, err := m.load()
+	if err != nil {
+		return "", err
+	}
+	for i := range installed.Packages {
+		pkg := &installed.Packages[i]
+		if len(pkg.Hooks) == 0 {
+			continue
+		}
+		script, err := m.hookScript(pkg)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range commands {
+			if hook.RunsScript(c, script) {
+				return pkg.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// saveWithHooks swaps the hook rules of prev for those of next and saves
+// installed. If either step fails, the previous rules are restored.
+func (m *Manager) saveWithHooks(installed *InstalledFile2, prev, next *InstalledPackage) error {
+	if err := m.syncHookRules(prev, next); err != nil {
+		_ = m.syncHookRules(next, prev)
+		return err
+	}
+	if err := m.save(installed); err != nil {
+		_ = m.syncHookRules(next, prev)
+		return err
+	}
+	return nil
+}
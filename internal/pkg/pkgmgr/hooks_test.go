@@ -0,0 +1,129 @@
+package pkgmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itda-skills/jindo/internal/hook"
+)
+
+func TestHookPackageRegistersRules(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "hooks", "lint.sh"), "#!/bin/sh\nexit 0\n")
+		writeFile(t, filepath.Join(upstream, "hooks", "lint.jd-package.yaml"),
+			"hooks:\n  - event: pre\n    matcher: Bash\n    command: \"{{script}} --strict\"\n  - event: Stop\n")
+		commitAll(t, upstream, "initial")
+	})
+
+	store := hook.NewStore(filepath.Join(env.claudeDir, "settings.json"))
+	if _, err := store.Add(hook.PostToolUse, "Edit", []string{"echo mine"}); err != nil {
+		t.Fatalf("add user hook: %v", err)
+	}
+
+	pkg, err := env.manager.Install("test:hooks/lint.sh", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	script := shellQuote(filepath.Join(env.claudeDir, "hooks", "test--lint.sh"))
+	if len(pkg.Hooks) != 2 || pkg.Hooks[0].Event != string(hook.PreToolUse) || pkg.Hooks[0].Command != script+" --strict" || pkg.Hooks[0].Script != script {
+		t.Fatalf("unexpected hooks: %+v", pkg.Hooks)
+	}
+
+	hooks, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	owned := 0
+	for _, h := range hooks {
+		owner, err := env.manager.HookOwner(h.Commands)
+		if err != nil {
+			t.Fatalf("HookOwner failed: %v", err)
+		}
+		if owner == pkg.Name {
+			owned++
+			if len(h.Hooks[0].Extra) != 0 {
+				t.Errorf("ownership stored in settings.json: %+v", h.Hooks[0])
+			}
+		}
+	}
+	if owned != 2 || len(hooks) != 3 {
+		t.Errorf("got %d hooks (%d package-owned), want 3 (2)", len(hooks), owned)
+	}
+
+	if err := env.manager.Uninstall(pkg.Name); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	hooks, err = store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(hooks) != 1 || hooks[0].Commands[0] != "echo mine" {
+		t.Errorf("after uninstall, hooks = %+v; want only the user hook", hooks)
+	}
+}
+
+func TestHookPackageUninstallEditedRules(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "hooks", "lint.sh"), "#!/bin/sh\nexit 0\n")
+		writeFile(t, filepath.Join(upstream, "hooks", "lint.jd-package.yaml"),
+			"hooks:\n  - event: pre\n    matcher: Bash\n  - event: Stop\n")
+		commitAll(t, upstream, "initial")
+	})
+
+	settingsPath := filepath.Join(env.claudeDir, "settings.json")
+	store := hook.NewStore(settingsPath)
+	if _, err := store.Add(hook.PreToolUse, "Bash", []string{"echo mine"}); err != nil {
+		t.Fatalf("add user hook: %v", err)
+	}
+
+	pkg, err := env.manager.Install("test:hooks/lint.sh", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// The rule is edited by hand and jd-hook-ids.json is not committed
+	hooks, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	for _, h := range hooks {
+		if h.EventType == hook.PreToolUse && h.Commands[0] != "echo mine" {
+			entries := h.Hooks
+			entries[0].Timeout = 30
+			if _, err := store.Update(h.Name, "Bash|Edit", entries); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+		}
+	}
+	if err := os.Remove(filepath.Join(env.claudeDir, "jd-hook-ids.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.manager.Uninstall(pkg.Name); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	hooks, err = store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(hooks) != 1 || hooks[0].Commands[0] != "echo mine" {
+		t.Errorf("after uninstall, hooks = %+v; want only the user hook", hooks)
+	}
+}
+
+func TestHookPackageInvalidEvent(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "hooks", "bad.sh"), "#!/bin/sh\n")
+		writeFile(t, filepath.Join(upstream, "hooks", "bad.jd-package.yaml"), "hooks:\n  - event: nope\n")
+		commitAll(t, upstream, "initial")
+	})
+
+	if _, err := env.manager.Install("test:hooks/bad.sh", false); err == nil {
+		t.Fatal("expected Install to fail for an invalid event")
+	}
+	if pkgs, _ := env.manager.List(); len(pkgs) != 0 {
+		t.Errorf("manifest lists %d packages, want 0", len(pkgs))
+	}
+}
@@ -0,0 +1,98 @@
+package pkgmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+func TestPackagePathLayouts(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantType repo.PackageType
+		wantName string
+	}{
+		{path: "skills/web-fetch", wantType: repo.TypeSkill, wantName: "web-fetch"},
+		{path: ".claude/skills/web-fetch", wantType: repo.TypeSkill, wantName: "web-fetch"},
+		{path: "commands/commit.md", wantType: repo.TypeCommand, wantName: "commit"},
+		{path: ".claude/commands/commit.md", wantType: repo.TypeCommand, wantName: "commit"},
+		{path: "commands/game/init.md", wantType: repo.TypeCommand, wantName: "game:init"},
+		{path: ".claude/agents/dev/tester.md", wantType: repo.TypeAgent, wantName: "dev:tester"},
+		{path: ".claude/hooks/lint.sh", wantType: repo.TypeHook, wantName: "lint.sh"},
+		{path: "commands/README", wantType: repo.TypeCommand, wantName: ""},
+		{path: ".claude/settings.json", wantType: "", wantName: ""},
+		{path: "skills", wantType: "", wantName: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pkgType := determinePackageType(tt.path)
+			if pkgType != tt.wantType {
+				t.Fatalf("determinePackageType(%q) = %q, want %q", tt.path, pkgType, tt.wantType)
+			}
+			if pkgType == "" {
+				return
+			}
+			if got := extractPackageName(tt.path, pkgType); got != tt.wantName {
+				t.Errorf("extractPackageName(%q) = %q, want %q", tt.path, got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestInstallBrowsedLayouts(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "root-skill", "SKILL.md"), "root")
+		writeFile(t, filepath.Join(upstream, ".claude", "skills", "dot-skill", "SKILL.md"), "dot")
+		writeFile(t, filepath.Join(upstream, ".claude", "commands", "deploy.md"), "deploy")
+		writeFile(t, filepath.Join(upstream, "commands", "game", "init.md"), "init")
+		writeFile(t, filepath.Join(upstream, "commands", "game", "save.md"), "save")
+		writeFile(t, filepath.Join(upstream, ".claude", "agents", "dev", "tester.md"), "tester")
+		writeFile(t, filepath.Join(upstream, ".claude", "hooks", "lint.sh"), "#!/bin/sh\n")
+		commitAll(t, upstream, "initial")
+	})
+
+	items, err := env.manager.RepoStore().Browse("test", "")
+	if err != nil {
+		t.Fatalf("Browse failed: %v", err)
+	}
+	if len(items) != 7 {
+		t.Fatalf("Browse returned %d items, want 7: %+v", len(items), items)
+	}
+
+	// Everything Browse shows must install under the name the TUI expects
+	for _, item := range items {
+		if _, err := env.manager.Install("test:"+item.Path, false); err != nil {
+			t.Fatalf("Install %s failed: %v", item.Path, err)
+		}
+		if _, err := env.manager.Get(MakeNamespacedName("test", item.Name)); err != nil {
+			t.Errorf("%s not installed as %s: %v", item.Path, MakeNamespacedName("test", item.Name), err)
+		}
+	}
+
+	nested := filepath.Join(env.claudeDir, "commands", "test--game", "init.md")
+	if content, err := os.ReadFile(nested); err != nil || string(content) != "init" {
+		t.Errorf("nested command = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(env.claudeDir, "agents", "test--dev", "tester.md")); err != nil {
+		t.Errorf("nested agent not installed: %v", err)
+	}
+
+	// Uninstalling one nested command keeps its sibling
+	if err := env.manager.Uninstall("test--game:init"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(env.claudeDir, "commands", "test--game", "save.md")); err != nil {
+		t.Errorf("sibling command removed: %v", err)
+	}
+
+	// The last one removes the now empty subdirectory
+	if err := env.manager.Uninstall("test--game:save"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(env.claudeDir, "commands", "test--game")); !os.IsNotExist(err) {
+		t.Errorf("empty command subdirectory left behind")
+	}
+}
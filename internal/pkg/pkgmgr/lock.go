@@ -0,0 +1,334 @@
+package pkgmgr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+// LockFileName is the name of the project lockfile inside .claude/.
+const LockFileName = "jd-lock.json"
+
+// ErrLockOutOfSync is returned in frozen mode when installed packages differ from the lockfile.
+var ErrLockOutOfSync = errors.New("installed packages do not match the lockfile")
+
+// LockedRepo represents a repository recorded in the lockfile.
+type LockedRepo struct {
+	Namespace string `json:"namespace"`
+	URL       string `json:"url"`
+}
+
+// LockedFile represents an installed file and its content hash.
+type LockedFile struct {
+	Source string `json:"source"` // Source path in repository
+	SHA256 string `json:"sha256"` // SHA-256 of the installed content
+}
+
+// LockedPackage represents a package pinned by the lockfile.
+type LockedPackage struct {
+	Name       string           `json:"name"`
+	Namespace  string           `json:"namespace"`
+	SourcePath string           `json:"source_path"`
+	Type       repo.PackageType `json:"type"`
+	Version    VersionInfo      `json:"version"`
+	Files      []LockedFile     `json:"files"`
+	Requires   []string         `json:"requires,omitempty"`
+	Dependency bool             `json:"dependency,omitempty"`
+}
+
+// LockFile represents the jd-lock.json file structure.
+type LockFile struct {
+	Version  int             `json:"version"`
+	Repos    []LockedRepo    `json:"repos"`
+	Packages []LockedPackage `json:"packages"`
+}
+
+// SyncPlan lists the changes needed to match a lockfile.
+type SyncPlan struct {
+	AddRepos  []LockedRepo
+	Install   []LockedPackage
+	Reinstall []LockedPackage // installed at a different commit, or with files that differ from the lockfile
+	Remove    []InstalledPackage
+}
+
+// IsEmpty reports whether the plan has no changes.
+func (p *SyncPlan) IsEmpty() bool {
+	return len(p.AddRepos) == 0 && len(p.Install) == 0 && len(p.Reinstall) == 0 && len(p.Remove) == 0
+}
+
+// ReadLockFile reads a lockfile from path.
+func ReadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock LockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", LockFileName, err)
+	}
+
+	return &lock, nil
+}
+
+// WriteLockFile writes a lockfile to path.
+func WriteLockFile(path string, lock *LockFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create lockfile directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", LockFileName, err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", LockFileName, err)
+	}
+
+	return nil
+}
+
+// Lock builds a lockfile from the installed packages.
+func (m *Manager) Lock() (*LockFile, error) {
+	installed, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &LockFile{Version: 1, Repos: []LockedRepo{}, Packages: []LockedPackage{}}
+	seenRepos := make(map[string]bool)
+
+	for _, pkg := range installed.Packages {
+		if pkg.Version.SHA == "" || pkg.Version.SHA == "unknown" {
+			return nil, fmt.Errorf("%s: installed commit is unknown, reinstall it before locking", pkg.Name)
+		}
+
+		if !seenRepos[pkg.Namespace] {
+			repoConfig, err := m.repoStore.Get(pkg.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pkg.Name, err)
+			}
+			lock.Repos = append(lock.Repos, LockedRepo{Namespace: repoConfig.Namespace, URL: repoConfig.URL})
+			seenRepos[pkg.Namespace] = true
+		}
+
+		locked := LockedPackage{
+			Name:       pkg.Name,
+			Namespace:  pkg.Namespace,
+			SourcePath: pkg.SourcePath,
+			Type:       pkg.Type,
+			Version:    pkg.Version,
+			Requires:   pkg.Requires,
+			Dependency: pkg.Dependency,
+		}
+		for _, f := range pkg.Files {
+			// Prefer the hash recorded at install time so local edits are not locked
+			sum := f.SHA
+			if sum == "" {
+				sum, err = hashFile(f.Target)
+				if err != nil {
+					return nil, fmt.Errorf("%s: hash %s: %w", pkg.Name, f.Target, err)
+				}
+			}
+			locked.Files = append(locked.Files, LockedFile{Source: f.Source, SHA256: sum})
+		}
+		sort.Slice(locked.Files, func(i, j int) bool {
+			return locked.Files[i].Source < locked.Files[j].Source
+		})
+
+		lock.Packages = append(lock.Packages, locked)
+	}
+
+	sort.Slice(lock.Repos, func(i, j int) bool {
+		return lock.Repos[i].Namespace < lock.Repos[j].Namespace
+	})
+	sort.Slice(lock.Packages, func(i, j int) bool {
+		return lock.Packages[i].Name < lock.Packages[j].Name
+	})
+
+	return lock, nil
+}
+
+// PlanSync computes the changes needed to make installed packages match lock.
+func (m *Manager) PlanSync(lock *LockFile) (*SyncPlan, error) {
+	plan := &SyncPlan{}
+
+	for _, lr := range lock.Repos {
+		existing, err := m.repoStore.Get(lr.Namespace)
+		if err != nil {
+			if errors.Is(err, repo.ErrRepoNotFound) {
+				plan.AddRepos = append(plan.AddRepos, lr)
+				continue
+			}
+			return nil, err
+		}
+		if existing.URL != lr.URL {
+			return nil, fmt.Errorf("namespace %s is registered to %s, but the lockfile expects %s", lr.Namespace, existing.URL, lr.URL)
+		}
+	}
+
+	installed, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	installedByName := make(map[string]InstalledPackage)
+	for _, pkg := range installed.Packages {
+		installedByName[pkg.Name] = pkg
+	}
+
+	locked := make(map[string]bool)
+	for _, lp := range lock.Packages {
+		locked[lp.Name] = true
+		pkg, ok := installedByName[lp.Name]
+		switch {
+		case !ok:
+			plan.Install = append(plan.Install, lp)
+		case pkg.Version.SHA != lp.Version.SHA || pkg.SourcePath != lp.SourcePath:
+			plan.Reinstall = append(plan.Reinstall, lp)
+		case filesDrifted(pkg, lp):
+			plan.Reinstall = append(plan.Reinstall, lp)
+		}
+	}
+
+	for _, pkg := range installed.Packages {
+		if !locked[pkg.Name] {
+			plan.Remove = append(plan.Remove, pkg)
+		}
+	}
+
+	return plan, nil
+}
+
+// filesDrifted reports whether the installed files of a package are
+// missing or differ from the hashes in the lockfile, e.g. after local edits.
+func filesDrifted(pkg InstalledPackage, lp LockedPackage) bool {
+	if len(pkg.Files) != len(lp.Files) {
+		return true
+	}
+	expected := make(map[string]string, len(lp.Files))
+	for _, f := range lp.Files {
+		expected[f.Source] = f.SHA256
+	}
+	for _, f := range pkg.Files {
+		sum, err := hashFile(f.Target)
+		if err != nil || sum != expected[f.Source] {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplySync applies a sync plan computed by PlanSync. Packages are
+// installed before any are removed, so a failed install or fetch leaves
+// the packages it would have replaced in place. Installed content is
+// checked against the lockfile hashes before it is committed. Before
+// anything is installed, it refuses with a *ConflictError if a package
+// would overwrite files it does not own, unless force is set; files of
+// packages the sync removes do not count.
+func (m *Manager) ApplySync(plan *SyncPlan, force bool) error {
+	for _, lr := range plan.AddRepos {
+		if _, err := m.repoStore.Add(lr.URL, lr.Namespace); err != nil {
+			return fmt.Errorf("add repository %s: %w", lr.Namespace, err)
+		}
+	}
+
+	toInstall := append(append([]LockedPackage{}, plan.Reinstall...), plan.Install...)
+	if !force {
+		if err := m.checkSyncConflicts(toInstall, plan.Remove); err != nil {
+			return err
+		}
+	}
+	for _, lp := range toInstall {
+		// Reinstalls replace the installed package in one transaction
+		var replace *InstalledPackage
+		if existing, err := m.Get(lp.Name); err == nil {
+			replace = existing
+		}
+
+		version := lp.Version
+		opts := installOptions{
+			name:       lp.Name,
+			locked:     &version,
+			replace:    replace,
+			requires:   lp.Requires,
+			dependency: lp.Dependency,
+			lockFiles:  append([]LockedFile{}, lp.Files...),
+		}
+		if _, err := m.install(&InstallSpec{Namespace: lp.Namespace, Path: lp.SourcePath}, opts); err != nil {
+			return fmt.Errorf("install %s: %w", lp.Name, err)
+		}
+	}
+
+	for _, pkg := range plan.Remove {
+		if err := m.Uninstall(pkg.Name); err != nil {
+			return fmt.Errorf("remove %s: %w", pkg.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkSyncConflicts refuses with a *ConflictError if the locked packages
+// would overwrite files owned by no package or by a package that stays.
+func (m *Manager) checkSyncConflicts(packages []LockedPackage, removed []InstalledPackage) error {
+	installed, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	removing := make(map[string]bool, len(removed))
+	for _, pkg := range removed {
+		removing[pkg.Name] = true
+	}
+
+	var conflicts []Conflict
+	for _, lp := range packages {
+		replace := findPackage(installed, lp.Name)
+		p := plannedPackage{
+			spec: &InstallSpec{Namespace: lp.Namespace, Path: lp.SourcePath, Version: lp.Version.SHA},
+			name: lp.Name,
+		}
+		described, err := m.describePlan([]plannedPackage{p}, installed, replace)
+		if err != nil {
+			return fmt.Errorf("check %s: %w", lp.Name, err)
+		}
+		for _, c := range overwriteConflicts(described.Conflicts) {
+			if c.Kind == ConflictOwned && removing[c.Owner] {
+				continue
+			}
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// verifyLockedFiles checks the hashes of staged files against the lockfile.
+func verifyLockedFiles(name string, files []InstalledFile, locked []LockedFile) error {
+	expected := make(map[string]string, len(locked))
+	for _, f := range locked {
+		expected[f.Source] = f.SHA256
+	}
+
+	if len(files) != len(expected) {
+		return fmt.Errorf("%s: installed %d files, lockfile lists %d", name, len(files), len(expected))
+	}
+
+	for _, f := range files {
+		if expected[f.Source] != f.SHA {
+			return fmt.Errorf("%s: content hash mismatch for %s", name, f.Source)
+		}
+	}
+
+	return nil
+}
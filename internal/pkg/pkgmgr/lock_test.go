@@ -0,0 +1,189 @@
+package pkgmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockAndSync(t *testing.T) {
+	var v1SHA string
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "hello")
+		v1SHA = commitAll(t, upstream, "v1")
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v2")
+		commitAll(t, upstream, "v2")
+	})
+
+	if _, err := env.manager.Install("test:skills/demo@"+v1SHA, false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	lock, err := env.manager.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if len(lock.Packages) != 1 || len(lock.Repos) != 1 || len(lock.Packages[0].Files) != 1 {
+		t.Fatalf("unexpected lockfile: %+v", lock)
+	}
+
+	lockPath := filepath.Join(t.TempDir(), LockFileName)
+	if err := WriteLockFile(lockPath, lock); err != nil {
+		t.Fatalf("WriteLockFile failed: %v", err)
+	}
+	lock, err = ReadLockFile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadLockFile failed: %v", err)
+	}
+
+	plan, err := env.manager.PlanSync(lock)
+	if err != nil {
+		t.Fatalf("PlanSync failed: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Fatalf("expected empty plan, got %+v", plan)
+	}
+
+	// Drift: remove the locked package and install an unlisted one
+	if err := env.manager.Uninstall("test--demo"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	plan, err = env.manager.PlanSync(lock)
+	if err != nil {
+		t.Fatalf("PlanSync failed: %v", err)
+	}
+	if len(plan.Install) != 1 || len(plan.Remove) != 1 || len(plan.Reinstall) != 0 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	if err := env.manager.ApplySync(plan, false); err != nil {
+		t.Fatalf("ApplySync failed: %v", err)
+	}
+
+	pkgs, err := env.manager.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Name != "test--demo" || pkgs[0].Version.SHA != v1SHA {
+		t.Fatalf("unexpected packages after sync: %+v", pkgs)
+	}
+
+	content, err := os.ReadFile(filepath.Join(env.claudeDir, "skills", "test--demo", "SKILL.md"))
+	if err != nil {
+		t.Fatalf("read installed file: %v", err)
+	}
+	if string(content) != "v1" {
+		t.Errorf("installed content = %q, want %q", content, "v1")
+	}
+}
+
+func TestSyncFileDrift(t *testing.T) {
+	var v1SHA string
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "hello")
+		v1SHA = commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:skills/demo@"+v1SHA, false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	lock, err := env.manager.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	// A locally edited package is out of sync even at the locked commit
+	skillFile := filepath.Join(env.claudeDir, "skills", "test--demo", "SKILL.md")
+	writeFile(t, skillFile, "edited")
+	plan, err := env.manager.PlanSync(lock)
+	if err != nil {
+		t.Fatalf("PlanSync failed: %v", err)
+	}
+	if len(plan.Reinstall) != 1 || plan.Reinstall[0].Name != "test--demo" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if err := env.manager.ApplySync(plan, false); err != nil {
+		t.Fatalf("ApplySync failed: %v", err)
+	}
+	if content, _ := os.ReadFile(skillFile); string(content) != "v1" {
+		t.Fatalf("content after sync = %q, want %q", content, "v1")
+	}
+
+	// A hash mismatch is refused before anything is replaced or removed
+	writeFile(t, skillFile, "edited")
+	tampered := *lock
+	tampered.Packages = nil
+	for _, lp := range lock.Packages {
+		if lp.Name == "test--demo" {
+			lp.Files = []LockedFile{{Source: lp.Files[0].Source, SHA256: "bogus"}}
+			tampered.Packages = append(tampered.Packages, lp)
+		}
+	}
+	plan, err = env.manager.PlanSync(&tampered)
+	if err != nil {
+		t.Fatalf("PlanSync failed: %v", err)
+	}
+	if len(plan.Reinstall) != 1 || len(plan.Remove) != 1 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if err := env.manager.ApplySync(plan, false); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Fatalf("ApplySync error = %v, want hash mismatch", err)
+	}
+	if content, _ := os.ReadFile(skillFile); string(content) != "edited" {
+		t.Errorf("content after failed sync = %q, want it untouched", content)
+	}
+	if _, err := env.manager.Get("test--hello"); err != nil {
+		t.Errorf("package removed by failed sync: %v", err)
+	}
+}
+
+func TestSyncConflict(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "package")
+		commitAll(t, upstream, "initial")
+	})
+
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	lock, err := env.manager.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if err := env.manager.Uninstall("test--hello"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+
+	// A hand-written file now sits at the locked package's target
+	target := filepath.Join(env.claudeDir, "commands", "test--hello.md")
+	writeFile(t, target, "hand-written")
+
+	plan, err := env.manager.PlanSync(lock)
+	if err != nil {
+		t.Fatalf("PlanSync failed: %v", err)
+	}
+	if err := env.manager.ApplySync(plan, false); !errors.Is(err, ErrConflict) {
+		t.Fatalf("ApplySync error = %v, want %v", err, ErrConflict)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "hand-written" {
+		t.Errorf("conflicting file was overwritten: %q", content)
+	}
+
+	if err := env.manager.ApplySync(plan, true); err != nil {
+		t.Fatalf("forced ApplySync failed: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "package" {
+		t.Errorf("forced sync did not overwrite: %q", content)
+	}
+}
@@ -0,0 +1,74 @@
+package pkgmgr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+)
+
+// Outdated checks installed packages for updates like CheckUpdatesContext,
+// but reports only the packages with changed files or commits that touch
+// them since the installed commit, and whether each was edited locally.
+// Nothing is installed or changed. Names that are not installed are
+// reported as failures with ErrPackageNotFound.
+func (m *Manager) Outdated(ctx context.Context, names ...string) (*UpdateReport, error) {
+	report, err := m.CheckUpdatesContext(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) > 0 {
+		installed, err := m.load()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if findPackage(installed, name) == nil {
+				report.Failures = append(report.Failures, CheckFailure{Package: name, Err: ErrPackageNotFound})
+			}
+		}
+	}
+
+	var outdated []UpdateInfo
+	for _, u := range report.Updates {
+		if !u.HasUpdate {
+			continue
+		}
+		if err := m.describeUpdate(&u); err != nil {
+			report.Failures = append(report.Failures, CheckFailure{Namespace: u.Package.Namespace, Package: u.Package.Name, Err: err})
+			continue
+		}
+		// New commits elsewhere in the repository don't change the package
+		if len(u.ChangedFiles) == 0 && len(u.Commits) == 0 {
+			continue
+		}
+		outdated = append(outdated, u)
+	}
+	report.Updates = outdated
+	return report, nil
+}
+
+// describeUpdate fills in the commits and local modification state of an update.
+func (m *Manager) describeUpdate(info *UpdateInfo) error {
+	pkg := info.Package
+	repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// updateInfo has already fetched a missing installed commit if allowed
+	if git.HasCommit(repoLocalPath, info.CurrentSHA) {
+		info.Commits, err = git.LogCommits(repoLocalPath, info.CurrentSHA, info.LatestSHA, pkg.SourcePath)
+		if err != nil {
+			return fmt.Errorf("log %s..%s: %w", shortCommit(info.CurrentSHA), shortCommit(info.LatestSHA), err)
+		}
+	}
+
+	status, err := m.packageStatus(pkg)
+	if err != nil {
+		return err
+	}
+	info.LocallyModified = !status.IsClean()
+	return nil
+}
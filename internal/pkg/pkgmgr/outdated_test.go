@@ -0,0 +1,63 @@
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutdated(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1\n")
+		writeFile(t, filepath.Join(upstream, "skills", "other", "SKILL.md"), "v1\n")
+		commitAll(t, upstream, "v1")
+	})
+
+	for _, spec := range []string{"test:skills/demo", "test:skills/other"} {
+		if _, err := env.manager.Install(spec, false); err != nil {
+			t.Fatalf("Install %s failed: %v", spec, err)
+		}
+	}
+
+	report, err := env.manager.Outdated(context.Background())
+	if err != nil {
+		t.Fatalf("Outdated failed: %v", err)
+	}
+	if len(report.Updates) != 0 || len(report.Failures) != 0 {
+		t.Fatalf("expected nothing outdated, got %+v", report)
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "skills", "demo", "SKILL.md"), "v2\n")
+	commitAll(t, env.upstream, "demo: v2")
+	writeFile(t, filepath.Join(env.upstream, "README.md"), "readme\n")
+	commitAll(t, env.upstream, "add readme")
+	writeFile(t, filepath.Join(env.claudeDir, "skills", "test--demo", "SKILL.md"), "my edit\n")
+
+	report, err = env.manager.Outdated(context.Background())
+	if err != nil {
+		t.Fatalf("Outdated failed: %v", err)
+	}
+	// Only packages whose files changed upstream are outdated
+	if len(report.Updates) != 1 {
+		t.Fatalf("expected one outdated package, got %+v", report.Updates)
+	}
+	demo := report.Updates[0]
+	if demo.Package.Name != "test--demo" || !demo.LocallyModified {
+		t.Errorf("unexpected update: %+v", demo)
+	}
+	if len(demo.Commits) != 1 || demo.Commits[0].Subject != "demo: v2" || demo.Commits[0].SHA == "" {
+		t.Errorf("commits = %+v", demo.Commits)
+	}
+	if len(demo.ChangedFiles) != 1 || demo.ChangedFiles[0] != "skills/demo/SKILL.md" {
+		t.Errorf("changed files = %v", demo.ChangedFiles)
+	}
+
+	report, err = env.manager.Outdated(context.Background(), "test--demo", "nosuch")
+	if err != nil {
+		t.Fatalf("Outdated failed: %v", err)
+	}
+	if len(report.Updates) != 1 || len(report.Failures) != 1 || !errors.Is(report.Failures[0].Err, ErrPackageNotFound) {
+		t.Errorf("unknown name not reported: %+v", report)
+	}
+}
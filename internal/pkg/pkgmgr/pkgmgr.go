@@ -1,6 +1,8 @@
 package pkgmgr
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,11 +15,15 @@ import (
 
 	"github.com/itda-skills/jindo/internal/pkg/git"
 	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/internal/updater"
 )
 
 const (
 	installedFileName = "installed.json"
 	namespaceSep      = "--"
+
+	// ProjectManifestName is the per-project installed packages manifest inside .claude/.
+	ProjectManifestName = "jd-packages.json"
 )
 
 var (
@@ -27,30 +33,68 @@ var (
 	ErrPackageAlreadyInstalled = errors.New("package already installed")
 	// ErrInvalidSpec is returned when the install spec is invalid.
 	ErrInvalidSpec = errors.New("invalid package specification")
+	// ErrPackagePinned is returned when updating a package pinned to a commit.
+	ErrPackagePinned = errors.New("package is pinned to a commit")
+	// ErrInvalidName is returned when an install name is not usable as a file name.
+	ErrInvalidName = errors.New("invalid install name")
+	// ErrUpdateMoved is returned when a package's ref moved past the commit an update was checked or reviewed at.
+	ErrUpdateMoved = errors.New("update moved since it was checked")
 )
 
+// installNameRegex matches install names. Commands and agents may use one
+// "dir:name" level of nesting.
+var installNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*(:[A-Za-z0-9][A-Za-z0-9_.-]*)?$`)
+
 // installSpecRegex matches namespace:path[@version] format.
 var installSpecRegex = regexp.MustCompile(`^([a-z0-9-]+):(.+?)(?:@(.+))?$`)
 
 // Manager manages installed packages.
 type Manager struct {
-	baseDir   string          // ~/.itda-skills (for metadata: installed.json, repos)
-	claudeDir string          // ~/.claude (for actual installed files)
-	repoStore *repo.Store
+	baseDir       string // ~/.itda-skills (for metadata: installed.json, repos)
+	claudeDir     string // ~/.claude or <project>/.claude (for actual installed files)
+	installedPath string // installed packages manifest
+	project       bool   // project scope: manifest targets are relative to claudeDir
+	repoStore     *repo.Store
+	fetchMaxAge   time.Duration // skip fetching repos fetched more recently than this; 0 always fetches
+	fetchOpts     repo.FetchOptions
+	peerDirs      []string // other .claude directories sharing the slash-command namespace
+	trust         TrustPolicy
+	strictScan    bool // refuse packages with high-severity scan findings
 }
 
-// NewManager creates a new package manager.
+// NewManager creates a new package manager that installs into ~/.claude.
 func NewManager(baseDir string) *Manager {
 	return &Manager{
-		baseDir:   baseDir,
-		claudeDir: "~/.claude",
-		repoStore: repo.NewStore(baseDir),
+		baseDir:       baseDir,
+		claudeDir:     "~/.claude",
+		installedPath: filepath.Join(baseDir, installedFileName),
+		repoStore:     repo.NewStore(baseDir),
 	}
 }
 
-// expandDir expands ~ to home directory for baseDir.
-func (m *Manager) expandDir() (string, error) {
-	return expandPath(m.baseDir)
+// NewProjectManager creates a package manager that installs into
+// <projectDir>/.claude and tracks packages in .claude/jd-packages.json.
+// Repository clones are still shared under baseDir.
+func NewProjectManager(baseDir, projectDir string) *Manager {
+	claudeDir := filepath.Join(projectDir, ".claude")
+	return &Manager{
+		baseDir:       baseDir,
+		claudeDir:     claudeDir,
+		installedPath: filepath.Join(claudeDir, ProjectManifestName),
+		project:       true,
+		repoStore:     repo.NewStore(baseDir),
+		peerDirs:      []string{"~/.claude"},
+	}
+}
+
+// IsProject reports whether the manager installs into a project .claude directory.
+func (m *Manager) IsProject() bool {
+	return m.project
+}
+
+// ClaudeDir returns the .claude directory packages are installed into.
+func (m *Manager) ClaudeDir() string {
+	return m.claudeDir
 }
 
 // expandClaudeDir expands ~ to home directory for claudeDir.
@@ -70,13 +114,9 @@ func expandPath(dir string) (string, error) {
 	return dir, nil
 }
 
-// installedFilePath returns the path to installed.json.
+// installedFilePath returns the path to the installed packages manifest.
 func (m *Manager) installedFilePath() (string, error) {
-	base, err := m.expandDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(base, installedFileName), nil
+	return expandPath(m.installedPath)
 }
 
 // load loads the installed packages file.
@@ -96,7 +136,22 @@ func (m *Manager) load() (*InstalledFile2, error) {
 
 	var installed InstalledFile2
 	if err := json.Unmarshal(data, &installed); err != nil {
-		return nil, fmt.Errorf("parse installed.json: %w", err)
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+
+	// Project manifests store targets relative to .claude so they can be committed
+	if m.project {
+		claudeDir, err := m.expandClaudeDir()
+		if err != nil {
+			return nil, err
+		}
+		for i := range installed.Packages {
+			for j, f := range installed.Packages[i].Files {
+				if !filepath.IsAbs(f.Target) {
+					installed.Packages[i].Files[j].Target = filepath.Join(claudeDir, filepath.FromSlash(f.Target))
+				}
+			}
+		}
 	}
 
 	return &installed, nil
@@ -104,32 +159,57 @@ func (m *Manager) load() (*InstalledFile2, error) {
 
 // save saves the installed packages file.
 func (m *Manager) save(installed *InstalledFile2) error {
-	baseDir, err := m.expandDir()
+	path, err := m.installedFilePath()
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("create data directory: %w", err)
 	}
 
-	path, err := m.installedFilePath()
-	if err != nil {
-		return err
+	if m.project {
+		installed, err = m.relativeTargets(installed)
+		if err != nil {
+			return err
+		}
 	}
 
 	data, err := json.MarshalIndent(installed, "", "  ")
 	if err != nil {
-		return fmt.Errorf("marshal installed.json: %w", err)
+		return fmt.Errorf("marshal %s: %w", filepath.Base(path), err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("write installed.json: %w", err)
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
 	}
 
 	return nil
 }
 
+// relativeTargets returns a copy of installed with file targets made
+// relative to the .claude directory.
+func (m *Manager) relativeTargets(installed *InstalledFile2) (*InstalledFile2, error) {
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	out := &InstalledFile2{Version: installed.Version, Packages: make([]InstalledPackage, len(installed.Packages))}
+	for i, pkg := range installed.Packages {
+		files := make([]InstalledFile, len(pkg.Files))
+		for j, f := range pkg.Files {
+			if rel, err := filepath.Rel(claudeDir, f.Target); err == nil && !strings.HasPrefix(rel, "..") {
+				f.Target = filepath.ToSlash(rel)
+			}
+			files[j] = f
+		}
+		pkg.Files = files
+		out.Packages[i] = pkg
+	}
+	return out, nil
+}
+
 // ParseSpec parses an install specification (namespace:path[@version]).
 func ParseSpec(spec string) (*InstallSpec, error) {
 	matches := installSpecRegex.FindStringSubmatch(spec)
@@ -149,6 +229,18 @@ func MakeNamespacedName(namespace, name string) string {
 	return namespace + namespaceSep + name
 }
 
+// ValidateInstallName checks that name can be used as the install name of
+// a package of the given type.
+func ValidateInstallName(pkgType repo.PackageType, name string) error {
+	if !installNameRegex.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	if strings.Contains(name, ":") && pkgType != repo.TypeCommand && pkgType != repo.TypeAgent {
+		return fmt.Errorf("%w: %q (only commands and agents may be nested)", ErrInvalidName, name)
+	}
+	return nil
+}
+
 // ParseNamespacedName parses a namespaced name.
 func ParseNamespacedName(name string) (namespace, originalName string) {
 	parts := strings.SplitN(name, namespaceSep, 2)
@@ -158,10 +250,22 @@ func ParseNamespacedName(name string) (namespace, originalName string) {
 	return "", name
 }
 
+// claudeDirPrefix is the optional .claude/ directory packages may live under in a source repository.
+const claudeDirPrefix = ".claude/"
+
+// packagePathParts splits a package path into its components, dropping a
+// leading .claude/ so both repository layouts that Browse emits are accepted.
+func packagePathParts(path string) []string {
+	path = strings.TrimPrefix(strings.Trim(path, "/"), claudeDirPrefix)
+	return strings.Split(path, "/")
+}
+
 // determinePackageType determines the package type from the path.
+// Accepts skills/, commands/, agents/ and hooks/ at the repository root
+// or under .claude/.
 func determinePackageType(path string) repo.PackageType {
-	parts := strings.Split(path, "/")
-	if len(parts) == 0 {
+	parts := packagePathParts(path)
+	if len(parts) < 2 {
 		return ""
 	}
 
@@ -180,8 +284,10 @@ func determinePackageType(path string) repo.PackageType {
 }
 
 // extractPackageName extracts the package name from the path.
+// Nested commands and agents are named like Browse names them:
+// commands/game/init.md -> game:init.
 func extractPackageName(path string, pkgType repo.PackageType) string {
-	parts := strings.Split(path, "/")
+	parts := packagePathParts(path)
 	if len(parts) < 2 {
 		return ""
 	}
@@ -191,47 +297,165 @@ func extractPackageName(path string, pkgType repo.PackageType) string {
 		// skills/<name>/...
 		return parts[1]
 	case repo.TypeCommand, repo.TypeAgent:
-		// commands/<name>.md or agents/<name>.md
-		name := parts[1]
+		// commands/<name>.md or commands/<sub>/<name>.md
+		if !strings.HasSuffix(parts[len(parts)-1], ".md") {
+			return ""
+		}
+		name := strings.Join(parts[1:], ":")
 		return strings.TrimSuffix(name, ".md")
 	case repo.TypeHook:
 		// hooks/<name>
+		if len(parts) != 2 {
+			return ""
+		}
 		return parts[1]
 	default:
 		return ""
 	}
 }
 
-// Install installs a package from local repository clone.
-func (m *Manager) Install(specStr string) (*InstalledPackage, error) {
-	spec, err := ParseSpec(specStr)
+// nestedFileName maps a namespaced command or agent name to its file path
+// below the type directory, so sub:name installs as <ns>--sub/name.md and
+// Claude Code keeps invoking it as <ns>--sub:name.
+func nestedFileName(namespacedName string) string {
+	return filepath.FromSlash(strings.ReplaceAll(namespacedName, ":", "/")) + ".md"
+}
+
+// Install installs a package from local repository clone, together with
+// the packages it declares in requires. Dependencies are installed first
+// and recorded as such; if any install fails, the dependencies installed
+// so far are removed again.
+//
+// Install refuses with a *ConflictError if a package would overwrite files
+// it does not own, unless force is set; forced installs take ownership of
+// the overwritten files. Use PlanInstall to inspect conflicts first.
+func (m *Manager) Install(specStr string, force bool) (*InstalledPackage, error) {
+	return m.InstallAs(specStr, "", force)
+}
+
+// InstallAs installs a package like Install, under the install name alias
+// instead of its namespaced name. An empty alias uses the default name.
+func (m *Manager) InstallAs(specStr, alias string, force bool) (*InstalledPackage, error) {
+	spec, installed, err := m.prepareInstall(specStr, alias)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get repository info and local path
-	repoConfig, err := m.repoStore.Get(spec.Namespace)
+	plan, err := m.planInstall(spec, installed, "", alias)
 	if err != nil {
-		return nil, fmt.Errorf("repository not found: %w", err)
+		return nil, err
 	}
+	if err := m.checkConflicts(plan, installed, nil, force); err != nil {
+		return nil, err
+	}
+	return m.installPlan(plan, nil)
+}
 
-	repoLocalPath, err := m.repoStore.RepoLocalPath(spec.Namespace)
+// prepareInstall parses a spec, validates the alias and checks that the
+// install name is free. It returns the spec and the installed packages.
+func (m *Manager) prepareInstall(specStr, alias string) (*InstallSpec, *InstalledFile2, error) {
+	spec, err := ParseSpec(specStr)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Determine package type and name
+	pkgType, _, name, err := m.packageIdentity(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if alias != "" {
+		if err := ValidateInstallName(pkgType, alias); err != nil {
+			return nil, nil, err
+		}
+		name = alias
+	}
+
+	installed, err := m.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if findPackage(installed, name) != nil {
+		return nil, nil, ErrPackageAlreadyInstalled
+	}
+	return spec, installed, nil
+}
+
+// checkConflicts refuses with a *ConflictError if the planned packages
+// would overwrite files they do not own, unless force is set.
+func (m *Manager) checkConflicts(plan []plannedPackage, installed *InstalledFile2, replace *InstalledPackage, force bool) error {
+	if force {
+		return nil
+	}
+	described, err := m.describePlan(plan, installed, replace)
+	if err != nil {
+		return err
+	}
+	if conflicts := overwriteConflicts(described.Conflicts); len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// installOptions controls how install records a package.
+type installOptions struct {
+	name       string            // install name; empty derives it from the spec
+	locked     *VersionInfo      // copy files from this exact version
+	replace    *InstalledPackage // swap out this installed package
+	requires   []string          // installed names of direct dependencies
+	dependency bool              // installed only to satisfy another package
+	lockFiles  []LockedFile      // content hashes the staged files must match
+}
+
+// packageIdentity returns the type, original name and default install name
+// of a spec. The install name is namespaced unless the repository is
+// registered as unprefixed.
+func (m *Manager) packageIdentity(spec *InstallSpec) (repo.PackageType, string, string, error) {
 	pkgType := determinePackageType(spec.Path)
 	if pkgType == "" {
-		return nil, fmt.Errorf("cannot determine package type from path: %s", spec.Path)
+		return "", "", "", fmt.Errorf("cannot determine package type from path: %s", spec.Path)
 	}
 
 	originalName := extractPackageName(spec.Path, pkgType)
 	if originalName == "" {
-		return nil, fmt.Errorf("cannot extract package name from path: %s", spec.Path)
+		return "", "", "", fmt.Errorf("cannot extract package name from path: %s", spec.Path)
 	}
 
-	namespacedName := MakeNamespacedName(spec.Namespace, originalName)
+	if rc, err := m.repoStore.Get(spec.Namespace); err == nil && rc.Unprefixed {
+		return pkgType, originalName, originalName, nil
+	}
+	return pkgType, originalName, MakeNamespacedName(spec.Namespace, originalName), nil
+}
+
+// install installs a single package. When opts.locked is set, files are
+// copied from locked.SHA and the locked version info is recorded as-is.
+// When opts.replace is set, that installed package is swapped out for the
+// new one.
+//
+// Files are staged first and moved into place by a transaction, so a failed
+// install or manifest save leaves the previous files and manifest untouched.
+func (m *Manager) install(spec *InstallSpec, opts installOptions) (*InstalledPackage, error) {
+	locked, replace := opts.locked, opts.replace
+
+	// Get repository info and local path
+	repoConfig, err := m.repoStore.Get(spec.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("repository not found: %w", err)
+	}
+
+	repoLocalPath, err := m.repoStore.RepoLocalPath(spec.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine package type and name
+	pkgType, originalName, defaultName, err := m.packageIdentity(spec)
+	if err != nil {
+		return nil, err
+	}
+	namespacedName := defaultName
+	if opts.name != "" {
+		namespacedName = opts.name
+	}
 
 	// Check if already installed
 	installed, err := m.load()
@@ -240,191 +464,300 @@ func (m *Manager) Install(specStr string) (*InstalledPackage, error) {
 	}
 
 	for _, pkg := range installed.Packages {
-		if pkg.Name == namespacedName {
+		if pkg.Name == namespacedName && (replace == nil || replace.Name != namespacedName) {
 			return nil, ErrPackageAlreadyInstalled
 		}
 	}
 
-	// Get current commit SHA from local repo
-	currentSHA, err := git.GetCurrentCommit(repoLocalPath)
+	// Resolve the requested version (or the checked out commit)
+	var version VersionInfo
+	var src packageSource
+	if locked != nil {
+		version, src, err = resolveLockedVersion(repoLocalPath, *locked)
+	} else {
+		version, src, err = m.resolveVersion(repoLocalPath, repoConfig, spec.Version)
+	}
 	if err != nil {
-		currentSHA = "unknown"
+		return nil, err
+	}
+
+	// Hooks and agents must come from a commit the trust policy accepts
+	provenance, err := m.checkTrust(repoConfig, repoLocalPath, pkgType, namespacedName, version.SHA)
+	if err != nil {
+		return nil, err
 	}
 
-	// Install files to ~/.claude directory
+	// Render the settings.json rules of hook packages so they are scanned too
+	var hooks []InstalledHook
+	if pkgType == repo.TypeHook {
+		hooks, err = m.hookBindings(src, spec.Path, namespacedName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Scan for risky patterns before copying anything
+	if err := m.checkScan(src, pkgType, spec.Path, namespacedName, hooks); err != nil {
+		return nil, err
+	}
+
+	// Stage files for the ~/.claude directory
 	claudeDir, err := m.expandClaudeDir()
 	if err != nil {
 		return nil, err
 	}
 
+	tx, err := newTransaction(claudeDir)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.close()
+
 	var files []InstalledFile
 
 	switch pkgType {
 	case repo.TypeSkill:
-		files, err = m.installSkill(repoLocalPath, spec.Path, namespacedName, claudeDir)
+		files, err = m.installSkill(src, spec.Path, namespacedName, tx.stageDir)
 	case repo.TypeCommand:
-		files, err = m.installCommand(repoLocalPath, spec.Path, namespacedName, claudeDir)
+		files, err = m.installCommand(src, spec.Path, namespacedName, tx.stageDir)
 	case repo.TypeAgent:
-		files, err = m.installAgent(repoLocalPath, spec.Path, namespacedName, claudeDir)
+		files, err = m.installAgent(src, spec.Path, namespacedName, tx.stageDir)
 	case repo.TypeHook:
-		files, err = m.installHook(repoLocalPath, spec.Path, namespacedName, claudeDir)
+		files, err = m.installHook(src, spec.Path, namespacedName, tx.stageDir)
 	}
 
 	if err != nil {
 		return nil, err
 	}
+	if pkgType == repo.TypeSkill && replace != nil && replace.Type == repo.TypeSkill {
+		// Keep the skill's adapt history across updates
+		oldDir := filepath.Join(claudeDir, "skills", replace.Name)
+		if err := copySkillHistory(oldDir, filepath.Join(tx.stageDir, "skills", namespacedName)); err != nil {
+			return nil, fmt.Errorf("keep skill history: %w", err)
+		}
+	}
+	if opts.lockFiles != nil {
+		// Refuse content that differs from the lockfile before anything is replaced
+		if err := verifyLockedFiles(namespacedName, files, opts.lockFiles); err != nil {
+			return nil, err
+		}
+	}
+	files = tx.finalFiles(files)
 
 	now := time.Now().UTC()
 	pkg := InstalledPackage{
 		Name:         namespacedName,
 		OriginalName: originalName,
+		Alias:        namespacedName != defaultName,
 		Type:         pkgType,
 		Namespace:    spec.Namespace,
 		SourcePath:   spec.Path,
-		Version: VersionInfo{
-			Type: "commit",
-			SHA:  currentSHA,
-			Ref:  repoConfig.DefaultBranch,
-		},
-		Files:       files,
-		InstalledAt: now,
-		UpdatedAt:   now,
+		Version:      version,
+		Files:        files,
+		Hooks:        hooks,
+		Requires:     opts.requires,
+		Dependency:   opts.dependency,
+		Provenance:   provenance,
+		InstalledAt:  now,
+		UpdatedAt:    now,
+	}
+
+	// Take over files of other packages that a forced install overwrites
+	var entries []string
+	for _, f := range files {
+		if e, ok := entryOf(claudeDir, f.Target); ok {
+			entries = append(entries, e)
+		}
+	}
+	releaseEntries(installed, claudeDir, entries, namespacedName)
+
+	var oldFiles []InstalledFile
+	if replace != nil {
+		pkg.InstalledAt = replace.InstalledAt
+		oldFiles = replace.Files
+		installed.Packages = removePackage(installed.Packages, replace.Name)
 	}
 
 	installed.Packages = append(installed.Packages, pkg)
 
-	if err := m.save(installed); err != nil {
-		// Try to clean up installed files
-		for _, f := range files {
-			_ = os.RemoveAll(f.Target)
-		}
+	save := func() error { return m.saveWithHooks(installed, replace, &pkg) }
+	if err := tx.commit(oldFiles, files, save); err != nil {
 		return nil, err
 	}
 
 	return &pkg, nil
 }
 
+// removePackage returns packages without the package named name.
+func removePackage(packages []InstalledPackage, name string) []InstalledPackage {
+	out := make([]InstalledPackage, 0, len(packages))
+	for _, p := range packages {
+		if p.Name != name {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// resolveVersion resolves a version string from an install spec.
+// An empty version installs the checked out working tree and tracks the
+// default branch. A branch tracks that branch, while a tag or commit SHA
+// pins the package. Pinned installs read files from the resolved commit.
+func (m *Manager) resolveVersion(repoLocalPath string, repoConfig *repo.RepoConfig, version string) (VersionInfo, packageSource, error) {
+	if version == "" {
+		currentSHA, err := git.GetCurrentCommit(repoLocalPath)
+		if err != nil {
+			currentSHA = "unknown"
+		}
+		return VersionInfo{
+			Type: VersionTypeCommit,
+			SHA:  currentSHA,
+			Ref:  repoConfig.DefaultBranch,
+		}, packageSource{repoPath: repoLocalPath}, nil
+	}
+
+	ref, err := git.ResolveRef(repoLocalPath, version)
+	if err != nil {
+		return VersionInfo{}, packageSource{}, fmt.Errorf("resolve version %s: %w", version, err)
+	}
+
+	info := VersionInfo{Type: VersionTypeCommit, SHA: ref.SHA}
+	switch ref.Kind {
+	case git.RefTag:
+		info.Type = VersionTypeTag
+		info.Ref = ref.Name
+		info.Pinned = true
+	case git.RefBranch:
+		info.Ref = ref.Name
+	default:
+		info.Pinned = true
+	}
+
+	return info, packageSource{repoPath: repoLocalPath, commit: ref.SHA}, nil
+}
+
+// resolveLockedVersion resolves the exact commit recorded in a lockfile.
+func resolveLockedVersion(repoLocalPath string, locked VersionInfo) (VersionInfo, packageSource, error) {
+	ref, err := git.ResolveRef(repoLocalPath, locked.SHA)
+	if err != nil {
+		return VersionInfo{}, packageSource{}, fmt.Errorf("resolve locked commit %s: %w", locked.SHA, err)
+	}
+	locked.SHA = ref.SHA
+	return locked, packageSource{repoPath: repoLocalPath, commit: ref.SHA}, nil
+}
+
 // installSkill installs a skill package from local clone.
-func (m *Manager) installSkill(repoLocalPath, path, namespacedName, baseDir string) ([]InstalledFile, error) {
-	srcDir := filepath.Join(repoLocalPath, path)
+func (m *Manager) installSkill(src packageSource, path, namespacedName, baseDir string) ([]InstalledFile, error) {
 	destDir := filepath.Join(baseDir, "skills", namespacedName)
 
+	srcFiles, err := src.listFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("list skill files: %w", err)
+	}
+
+	if len(srcFiles) == 0 {
+		return nil, fmt.Errorf("no files found in skill: %s", path)
+	}
+
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return nil, fmt.Errorf("create skill directory: %w", err)
 	}
 
 	var files []InstalledFile
 
-	err := filepath.Walk(srcDir, func(srcPath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		// Calculate relative path
-		relPath, err := filepath.Rel(srcDir, srcPath)
-		if err != nil {
-			return err
-		}
-
-		destPath := filepath.Join(destDir, relPath)
+	for _, srcPath := range srcFiles {
+		// Calculate path relative to the skill directory
+		relPath := strings.TrimPrefix(srcPath, strings.TrimSuffix(path, "/")+"/")
+		destPath := filepath.Join(destDir, filepath.FromSlash(relPath))
 
 		// Create parent directories
 		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			return err
+			_ = os.RemoveAll(destDir)
+			return nil, fmt.Errorf("copy skill files: %w", err)
 		}
 
 		// Copy file
-		if err := copyFile(srcPath, destPath); err != nil {
-			return err
+		if err := src.copyTo(srcPath, destPath); err != nil {
+			_ = os.RemoveAll(destDir)
+			return nil, fmt.Errorf("copy skill files: %w", err)
+		}
+
+		sum, err := hashFile(destPath)
+		if err != nil {
+			_ = os.RemoveAll(destDir)
+			return nil, fmt.Errorf("hash skill file: %w", err)
 		}
 
 		files = append(files, InstalledFile{
-			Source: filepath.Join(path, relPath),
+			Source: srcPath,
 			Target: destPath,
-			SHA:    "", // Not tracking file SHA for local copies
+			SHA:    sum,
 		})
-
-		return nil
-	})
-
-	if err != nil {
-		_ = os.RemoveAll(destDir)
-		return nil, fmt.Errorf("copy skill files: %w", err)
-	}
-
-	if len(files) == 0 {
-		_ = os.RemoveAll(destDir)
-		return nil, fmt.Errorf("no files found in skill: %s", path)
 	}
 
 	return files, nil
 }
 
 // installCommand installs a command package from local clone.
-func (m *Manager) installCommand(repoLocalPath, path, namespacedName, baseDir string) ([]InstalledFile, error) {
-	srcPath := filepath.Join(repoLocalPath, path)
+func (m *Manager) installCommand(src packageSource, path, namespacedName, baseDir string) ([]InstalledFile, error) {
 	commandsDir := filepath.Join(baseDir, "commands")
+	destPath := filepath.Join(commandsDir, nestedFileName(namespacedName))
 
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 		return nil, fmt.Errorf("create commands directory: %w", err)
 	}
 
-	destPath := filepath.Join(commandsDir, namespacedName+".md")
-	if err := copyFile(srcPath, destPath); err != nil {
+	if err := src.copyTo(path, destPath); err != nil {
 		return nil, fmt.Errorf("copy command file: %w", err)
 	}
 
+	sum, err := hashFile(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash command file: %w", err)
+	}
+
 	return []InstalledFile{{
 		Source: path,
 		Target: destPath,
-		SHA:    "",
+		SHA:    sum,
 	}}, nil
 }
 
 // installAgent installs an agent package from local clone.
-func (m *Manager) installAgent(repoLocalPath, path, namespacedName, baseDir string) ([]InstalledFile, error) {
-	srcPath := filepath.Join(repoLocalPath, path)
+func (m *Manager) installAgent(src packageSource, path, namespacedName, baseDir string) ([]InstalledFile, error) {
 	agentsDir := filepath.Join(baseDir, "agents")
+	destPath := filepath.Join(agentsDir, nestedFileName(namespacedName))
 
-	if err := os.MkdirAll(agentsDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 		return nil, fmt.Errorf("create agents directory: %w", err)
 	}
 
-	destPath := filepath.Join(agentsDir, namespacedName+".md")
-	if err := copyFile(srcPath, destPath); err != nil {
+	if err := src.copyTo(path, destPath); err != nil {
 		return nil, fmt.Errorf("copy agent file: %w", err)
 	}
 
+	sum, err := hashFile(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash agent file: %w", err)
+	}
+
 	return []InstalledFile{{
 		Source: path,
 		Target: destPath,
-		SHA:    "",
+		SHA:    sum,
 	}}, nil
 }
 
 // installHook installs a hook package from local clone.
-func (m *Manager) installHook(repoLocalPath, path, namespacedName, baseDir string) ([]InstalledFile, error) {
-	srcPath := filepath.Join(repoLocalPath, path)
+func (m *Manager) installHook(src packageSource, path, namespacedName, baseDir string) ([]InstalledFile, error) {
 	hooksDir := filepath.Join(baseDir, "hooks")
 
 	if err := os.MkdirAll(hooksDir, 0755); err != nil {
 		return nil, fmt.Errorf("create hooks directory: %w", err)
 	}
 
-	// Get original filename (including extension)
-	originalName := filepath.Base(path)
-	// Prepend namespace
-	destName := namespacedName
-	if ext := filepath.Ext(originalName); ext != "" {
-		destName += ext
-	}
-
-	destPath := filepath.Join(hooksDir, destName)
-	if err := copyFile(srcPath, destPath); err != nil {
+	destPath := filepath.Join(hooksDir, hookFileName(path, namespacedName))
+	if err := src.copyTo(path, destPath); err != nil {
 		return nil, fmt.Errorf("copy hook file: %w", err)
 	}
 
@@ -433,13 +766,41 @@ func (m *Manager) installHook(repoLocalPath, path, namespacedName, baseDir strin
 		return nil, fmt.Errorf("make hook executable: %w", err)
 	}
 
+	sum, err := hashFile(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash hook file: %w", err)
+	}
+
 	return []InstalledFile{{
 		Source: path,
 		Target: destPath,
-		SHA:    "",
+		SHA:    sum,
 	}}, nil
 }
 
+// copySkillHistory copies the history directory of an installed skill
+// into a staged skill directory. Skills without history are skipped.
+func copySkillHistory(skillDir, stagedDir string) error {
+	src := filepath.Join(skillDir, skillHistoryDir)
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(skillDir, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(stagedDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(dest, 0755)
+		}
+		return copyFile(path, dest)
+	})
+}
+
 // copyFile copies a file from src to dest.
 func copyFile(src, dest string) error {
 	srcFile, err := os.Open(src)
@@ -458,6 +819,21 @@ func copyFile(src, dest string) error {
 	return err
 }
 
+// hashFile returns the hex-encoded SHA-256 of a file's content.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // Uninstall removes an installed package.
 func (m *Manager) Uninstall(name string) error {
 	installed, err := m.load()
@@ -465,38 +841,29 @@ func (m *Manager) Uninstall(name string) error {
 		return err
 	}
 
-	var pkg *InstalledPackage
-	var idx int
-	for i, p := range installed.Packages {
-		if p.Name == name {
-			pkg = &installed.Packages[i]
-			idx = i
-			break
-		}
-	}
-
+	pkg := findPackage(installed, name)
 	if pkg == nil {
 		return ErrPackageNotFound
 	}
 
-	// Remove files
-	for _, f := range pkg.Files {
-		_ = os.Remove(f.Target)
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return err
 	}
 
-	// For skills, remove the directory
-	if pkg.Type == repo.TypeSkill {
-		baseDir, err := m.expandDir()
-		if err == nil {
-			skillDir := filepath.Join(baseDir, "skills", pkg.Name)
-			_ = os.RemoveAll(skillDir)
-		}
+	tx, err := newTransaction(claudeDir)
+	if err != nil {
+		return err
 	}
+	defer tx.close()
 
-	// Remove from installed list
-	installed.Packages = append(installed.Packages[:idx], installed.Packages[idx+1:]...)
+	// Remove from installed list; files (for skills, the whole
+	// directory) are moved aside and discarded once this is saved
+	// Hook rules registered for the package are removed from settings.json
+	files := pkg.Files
+	installed.Packages = removePackage(installed.Packages, name)
 
-	return m.save(installed)
+	return tx.commit(files, nil, func() error { return m.saveWithHooks(installed, pkg, nil) })
 }
 
 // List returns all installed packages.
@@ -524,112 +891,115 @@ func (m *Manager) Get(name string) (*InstalledPackage, error) {
 	return nil, ErrPackageNotFound
 }
 
-// CheckUpdates checks for updates for installed packages.
-func (m *Manager) CheckUpdates(names ...string) ([]UpdateInfo, error) {
-	installed, err := m.load()
-	if err != nil {
-		return nil, err
-	}
+// semverTagRegex matches release tags such as v1.2.3 or 1.2.
+var semverTagRegex = regexp.MustCompile(`^v?\d+(\.\d+){0,2}$`)
 
-	var results []UpdateInfo
+// latestSemverTag returns the newest semver tag greater than current,
+// or an empty string if there is none or current is not a semver tag.
+func latestSemverTag(tags map[string]string, current string) string {
+	if !semverTagRegex.MatchString(current) {
+		return ""
+	}
 
-	for _, pkg := range installed.Packages {
-		// Filter by names if provided
-		if len(names) > 0 {
-			found := false
-			for _, n := range names {
-				if pkg.Name == n {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
+	latest := ""
+	for tag := range tags {
+		if !semverTagRegex.MatchString(tag) {
+			continue
 		}
-
-		info, err := m.checkPackageUpdate(&pkg)
-		if err != nil {
-			// Skip packages that fail to check
+		if updater.CompareVersions(tag, current) <= 0 {
 			continue
 		}
-
-		results = append(results, *info)
+		if latest == "" || updater.CompareVersions(tag, latest) > 0 {
+			latest = tag
+		}
 	}
+	return latest
+}
 
-	return results, nil
+// UpdateOptions controls what Update may overwrite.
+type UpdateOptions struct {
+	OverwriteLocal bool   // replace installed files that were edited or added locally
+	ForceConflicts bool   // overwrite files the package does not own
+	SHA            string // commit the update was checked or reviewed at; empty takes the latest
 }
 
-// checkPackageUpdate checks for updates for a single package.
-func (m *Manager) checkPackageUpdate(pkg *InstalledPackage) (*UpdateInfo, error) {
-	repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+// Update updates a package to the latest version allowed by its pin.
+// It refuses with ErrLocalModifications if installed files were edited or
+// added since install, unless opts.OverwriteLocal is set, and with a
+// *ConflictError if the new version or its dependencies would overwrite
+// files they do not own, unless opts.ForceConflicts is set. Use Backup to
+// keep the edits. If opts.SHA is set, exactly that commit is installed, and
+// the update is refused with ErrUpdateMoved if the package's ref has moved
+// on from it.
+func (m *Manager) Update(name string, opts UpdateOptions) (*InstalledPackage, error) {
+	pkg, err := m.Get(name)
 	if err != nil {
 		return nil, err
 	}
 
-	repoConfig, err := m.repoStore.Get(pkg.Namespace)
-	if err != nil {
-		return nil, err
+	if pkg.Version.Pinned && pkg.Version.Type != VersionTypeTag {
+		return nil, ErrPackagePinned
 	}
 
-	// Fetch latest changes
-	if err := git.Fetch(repoLocalPath); err != nil {
-		return nil, err
+	if !opts.OverwriteLocal {
+		status, err := m.packageStatus(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if status.HasLocalEdits() {
+			return nil, fmt.Errorf("%w: %d modified, %d added file(s)", ErrLocalModifications, len(status.Modified), len(status.Extra))
+		}
 	}
 
-	// Get remote commit
-	latestSHA, err := git.GetRemoteCommit(repoLocalPath, repoConfig.DefaultBranch)
+	info, err := m.checkPackageUpdate(pkg)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("check update: %w", err)
+	}
+	if opts.SHA != "" && info.LatestSHA != opts.SHA {
+		return nil, fmt.Errorf("%w: %s is now at %s, not %s", ErrUpdateMoved, info.LatestRef, shortCommit(info.LatestSHA), shortCommit(opts.SHA))
 	}
 
-	info := &UpdateInfo{
-		Package:    pkg,
-		CurrentSHA: pkg.Version.SHA,
-		LatestSHA:  latestSHA,
-		HasUpdate:  pkg.Version.SHA != latestSHA,
+	repoConfig, err := m.repoStore.Get(pkg.Namespace)
+	if err != nil {
+		return nil, err
 	}
 
-	if info.HasUpdate {
-		// Get changed files
-		changedFiles, err := git.ListChangedFiles(repoLocalPath, pkg.Version.SHA, "origin/"+repoConfig.DefaultBranch)
-		if err == nil {
-			for _, f := range changedFiles {
-				if strings.HasPrefix(f, pkg.SourcePath) {
-					info.ChangedFiles = append(info.ChangedFiles, f)
-				}
-			}
+	// Packages tracking the default branch are installed from the working
+	// tree, so fast-forward it to the commit the check found. The check
+	// already fetched within the staleness window; this never goes to the
+	// network. Tags and other branches are read from git.
+	version := info.LatestRef
+	if pkg.Version.Type != VersionTypeTag && info.LatestRef == repoConfig.DefaultBranch {
+		repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	return info, nil
-}
+		if err := git.FastForward(repoLocalPath, info.LatestSHA); err != nil {
+			return nil, fmt.Errorf("fast-forward to %s: %w", shortCommit(info.LatestSHA), err)
+		}
+		// A working tree that was already ahead is left where it is
+		if head, err := git.GetCurrentCommit(repoLocalPath); err != nil || head != info.LatestSHA {
+			return nil, fmt.Errorf("%w: %s is checked out at %s, not %s", ErrUpdateMoved, repoLocalPath, shortCommit(head), shortCommit(info.LatestSHA))
+		}
+		version = ""
+	}
 
-// Update updates a package to the latest version.
-func (m *Manager) Update(name string) (*InstalledPackage, error) {
-	pkg, err := m.Get(name)
+	// Swap in the new version; the old one stays installed on failure.
+	// Dependencies added by the new version are installed first.
+	spec := &InstallSpec{Namespace: pkg.Namespace, Path: pkg.SourcePath, Version: version}
+	installed, err := m.load()
 	if err != nil {
 		return nil, err
 	}
-
-	// Pull latest changes in the repo first
-	repoLocalPath, err := m.repoStore.RepoLocalPath(pkg.Namespace)
+	plan, err := m.planInstall(spec, installed, pkg.Name, pkg.Name)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := git.Pull(repoLocalPath); err != nil {
-		return nil, fmt.Errorf("pull latest changes: %w", err)
-	}
-
-	// Uninstall old version
-	if err := m.Uninstall(name); err != nil {
-		return nil, fmt.Errorf("uninstall old version: %w", err)
+	if err := m.checkConflicts(plan, installed, pkg, opts.ForceConflicts); err != nil {
+		return nil, err
 	}
-
-	// Reinstall
-	spec := fmt.Sprintf("%s:%s", pkg.Namespace, pkg.SourcePath)
-	return m.Install(spec)
+	return m.installPlan(plan, pkg)
 }
 
 // RepoStore returns the repository store.
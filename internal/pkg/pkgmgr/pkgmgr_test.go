@@ -0,0 +1,411 @@
+package pkgmgr
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+// runGit runs a git command in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+// writeFile creates a file with the given content.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// commitAll commits all changes in dir and returns the new HEAD SHA.
+func commitAll(t *testing.T, dir, message string) string {
+	t.Helper()
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "--quiet", "-m", message)
+	return trimNewline(runGit(t, dir, "rev-parse", "HEAD"))
+}
+
+func trimNewline(s string) string {
+	for len(s) > 0 && (s[len(s)-1] == '\n' || s[len(s)-1] == '\r') {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// testEnv is a manager wired to an upstream repository and a local clone.
+type testEnv struct {
+	manager   *Manager
+	upstream  string
+	clone     string
+	claudeDir string
+}
+
+// setupTestEnv creates an upstream repository, clones it under the manager's
+// repos directory and registers it as namespace "test".
+func setupTestEnv(t *testing.T, populate func(upstream string)) *testEnv {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	baseDir := filepath.Join(root, "base")
+	claudeDir := filepath.Join(root, "claude")
+
+	if err := os.MkdirAll(upstream, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, upstream, "init", "--quiet", "-b", "main")
+	populate(upstream)
+
+	clone := filepath.Join(baseDir, "repos", "test")
+	runGit(t, root, "clone", "--quiet", "--depth", "1", "file://"+upstream, clone)
+
+	repos := repo.ReposFile{Version: 1, Repos: []repo.RepoConfig{{
+		Namespace:     "test",
+		URL:           "file://" + upstream,
+		DefaultBranch: "main",
+		AddedAt:       time.Now().UTC(),
+	}}}
+	data, err := json.Marshal(repos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(baseDir, "repos.json"), string(data))
+
+	m := NewManager(baseDir)
+	m.claudeDir = claudeDir
+	return &testEnv{manager: m, upstream: upstream, clone: clone, claudeDir: claudeDir}
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    InstallSpec
+		wantErr bool
+	}{
+		{spec: "affa-ever:skills/web-fetch", want: InstallSpec{Namespace: "affa-ever", Path: "skills/web-fetch"}},
+		{spec: "affa-ever:skills/web-fetch@v1.2.0", want: InstallSpec{Namespace: "affa-ever", Path: "skills/web-fetch", Version: "v1.2.0"}},
+		{spec: "affa-ever:commands/commit.md@3f2a9c1", want: InstallSpec{Namespace: "affa-ever", Path: "commands/commit.md", Version: "3f2a9c1"}},
+		{spec: "no-path", wantErr: true},
+		{spec: "UPPER:skills/x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseSpec(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSpec failed: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestSemverTag(t *testing.T) {
+	tags := map[string]string{
+		"v1.0.0": "a", "v1.2.0": "b", "v1.10.0": "c", "nightly": "d", "v2.0.0-rc1": "e",
+	}
+
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{current: "v1.0.0", want: "v1.10.0"},
+		{current: "v1.10.0", want: ""},
+		{current: "nightly", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			if got := latestSemverTag(tags, tt.current); got != tt.want {
+				t.Errorf("latestSemverTag(%s) = %q, want %q", tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallPinnedVersion(t *testing.T) {
+	var v1SHA, v2SHA string
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		v1SHA = commitAll(t, upstream, "v1")
+		runGit(t, upstream, "tag", "v1.0.0")
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v2")
+		v2SHA = commitAll(t, upstream, "v2")
+		runGit(t, upstream, "tag", "-a", "v1.1.0", "-m", "v1.1.0")
+	})
+
+	pkg, err := env.manager.Install("test:skills/demo@v1.0.0", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	if pkg.Version.Type != VersionTypeTag || pkg.Version.Ref != "v1.0.0" || pkg.Version.SHA != v1SHA || !pkg.Version.Pinned {
+		t.Errorf("unexpected version info: %+v", pkg.Version)
+	}
+
+	content, err := os.ReadFile(filepath.Join(env.claudeDir, "skills", "test--demo", "SKILL.md"))
+	if err != nil {
+		t.Fatalf("read installed file: %v", err)
+	}
+	if string(content) != "v1" {
+		t.Errorf("installed content = %q, want %q", content, "v1")
+	}
+
+	// The working tree must stay on the default branch
+	if head := trimNewline(runGit(t, env.clone, "rev-parse", "HEAD")); head != v2SHA {
+		t.Errorf("clone HEAD moved to %s", head)
+	}
+
+	updates, err := env.manager.CheckUpdates()
+	if err != nil {
+		t.Fatalf("CheckUpdates failed: %v", err)
+	}
+	if len(updates) != 1 || !updates[0].HasUpdate || updates[0].LatestRef != "v1.1.0" || updates[0].LatestSHA != v2SHA {
+		t.Fatalf("unexpected updates: %+v", updates)
+	}
+
+	updated, err := env.manager.Update(pkg.Name, UpdateOptions{})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Version.Ref != "v1.1.0" || !updated.Version.Pinned {
+		t.Errorf("unexpected updated version: %+v", updated.Version)
+	}
+}
+
+func TestInstallPinnedCommit(t *testing.T) {
+	var v1SHA string
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "v1")
+		v1SHA = commitAll(t, upstream, "v1")
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "v2")
+		commitAll(t, upstream, "v2")
+	})
+
+	pkg, err := env.manager.Install("test:commands/hello.md@"+v1SHA[:10], false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if pkg.Version.Type != VersionTypeCommit || pkg.Version.SHA != v1SHA || !pkg.Version.Pinned {
+		t.Errorf("unexpected version info: %+v", pkg.Version)
+	}
+
+	content, err := os.ReadFile(filepath.Join(env.claudeDir, "commands", "test--hello.md"))
+	if err != nil {
+		t.Fatalf("read installed file: %v", err)
+	}
+	if string(content) != "v1" {
+		t.Errorf("installed content = %q, want %q", content, "v1")
+	}
+
+	updates, err := env.manager.CheckUpdates()
+	if err != nil {
+		t.Fatalf("CheckUpdates failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0].HasUpdate {
+		t.Errorf("commit-pinned package should not have updates: %+v", updates)
+	}
+
+	if _, err := env.manager.Update(pkg.Name, UpdateOptions{}); err != ErrPackagePinned {
+		t.Errorf("Update error = %v, want %v", err, ErrPackagePinned)
+	}
+}
+
+func TestProjectManager(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "demo")
+		commitAll(t, upstream, "initial")
+	})
+
+	projectDir := t.TempDir()
+	m := NewProjectManager(env.manager.baseDir, projectDir)
+
+	if _, err := m.Install("test:skills/demo", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	target := filepath.Join(projectDir, ".claude", "skills", "test--demo", "SKILL.md")
+	if _, err := os.Stat(target); err != nil {
+		t.Fatalf("expected installed file at %s: %v", target, err)
+	}
+
+	// Manifest stores targets relative to .claude
+	data, err := os.ReadFile(filepath.Join(projectDir, ".claude", ProjectManifestName))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest InstalledFile2
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	if got := manifest.Packages[0].Files[0].Target; got != "skills/test--demo/SKILL.md" {
+		t.Errorf("manifest target = %q, want relative path", got)
+	}
+
+	// Loaded targets are absolute again
+	pkg, err := m.Get("test--demo")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if pkg.Files[0].Target != target {
+		t.Errorf("loaded target = %q, want %q", pkg.Files[0].Target, target)
+	}
+
+	// Global manager does not see project installs
+	if pkgs, _ := env.manager.List(); len(pkgs) != 0 {
+		t.Errorf("global manager lists %d packages, want 0", len(pkgs))
+	}
+
+	if err := m.Uninstall("test--demo"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(target)); !os.IsNotExist(err) {
+		t.Errorf("skill directory still exists after uninstall")
+	}
+}
+
+func TestCheckUpdatesFetchMaxAge(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "commands", "hello.md"), "v2")
+	v2SHA := commitAll(t, env.upstream, "v2")
+
+	store := repo.NewStore(env.manager.baseDir)
+	if err := store.MarkFetched("test", time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fetched recently: the local refs are used and v2 is not seen yet
+	env.manager.SetFetchMaxAge(time.Hour)
+	updates, err := env.manager.CheckUpdates()
+	if err != nil {
+		t.Fatalf("CheckUpdates failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0].HasUpdate {
+		t.Fatalf("expected no update within the staleness window: %+v", updates)
+	}
+
+	// Offline never fetches
+	env.manager.SetFetchMaxAge(NeverFetch)
+	if err := store.MarkFetched("test", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if updates, _ := env.manager.CheckUpdates(); len(updates) != 1 || updates[0].HasUpdate {
+		t.Fatalf("expected no update offline: %+v", updates)
+	}
+
+	// Stale: the repository is fetched and the fetch time recorded
+	env.manager.SetFetchMaxAge(time.Hour)
+	updates, err = env.manager.CheckUpdates()
+	if err != nil {
+		t.Fatalf("CheckUpdates failed: %v", err)
+	}
+	if len(updates) != 1 || !updates[0].HasUpdate || updates[0].LatestSHA != v2SHA {
+		t.Fatalf("expected update after fetching: %+v", updates)
+	}
+	if last, err := store.LastFetched("test"); err != nil || time.Since(last) > time.Minute {
+		t.Errorf("fetch time not recorded: %v, %v", last, err)
+	}
+}
+
+func TestUpdateOffline(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "commands", "hello.md"), "v2")
+	v2SHA := commitAll(t, env.upstream, "v2")
+	if _, err := env.manager.CheckUpdates(); err != nil {
+		t.Fatalf("CheckUpdates failed: %v", err)
+	}
+
+	// The upstream goes away; the fetched commit is still applied
+	if err := os.Rename(env.upstream, env.upstream+".gone"); err != nil {
+		t.Fatal(err)
+	}
+	env.manager.SetFetchMaxAge(NeverFetch)
+	updated, err := env.manager.Update("test--hello", UpdateOptions{})
+	if err != nil {
+		t.Fatalf("offline Update failed: %v", err)
+	}
+	if updated.Version.SHA != v2SHA {
+		t.Errorf("updated to %s, want %s", updated.Version.SHA, v2SHA)
+	}
+	content, err := os.ReadFile(filepath.Join(env.claudeDir, "commands", "test--hello.md"))
+	if err != nil || string(content) != "v2" {
+		t.Errorf("installed file = %q, %v; want v2", content, err)
+	}
+}
+
+func TestUpdateInstallsCheckedCommit(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:commands/hello.md", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "commands", "hello.md"), "v2")
+	v2SHA := commitAll(t, env.upstream, "v2")
+	updates, err := env.manager.CheckUpdates()
+	if err != nil || len(updates) != 1 || updates[0].LatestSHA != v2SHA {
+		t.Fatalf("CheckUpdates = %+v, %v", updates, err)
+	}
+
+	// A commit pushed after the check is not installed unreviewed
+	writeFile(t, filepath.Join(env.upstream, "commands", "hello.md"), "v3")
+	commitAll(t, env.upstream, "v3")
+	if _, err := env.manager.Update("test--hello", UpdateOptions{SHA: v2SHA}); !errors.Is(err, ErrUpdateMoved) {
+		t.Fatalf("Update error = %v, want %v", err, ErrUpdateMoved)
+	}
+	content, err := os.ReadFile(filepath.Join(env.claudeDir, "commands", "test--hello.md"))
+	if err != nil || string(content) != "v1" {
+		t.Errorf("installed file = %q, %v; want v1", content, err)
+	}
+}
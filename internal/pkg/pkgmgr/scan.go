@@ -0,0 +1,145 @@
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/internal/pkg/scan"
+)
+
+// ErrScanBlocked is returned in strict scan mode when a package has
+// high-severity security findings.
+var ErrScanBlocked = errors.New("package has high-severity security findings")
+
+// ScanError is returned when strict scan mode refuses a package.
+type ScanError struct {
+	Package  string
+	Findings []scan.Finding // high-severity findings
+}
+
+// Error implements error.
+func (e *ScanError) Error() string {
+	f := e.Findings[0]
+	msg := fmt.Sprintf("%v: %s: %s (%s)", ErrScanBlocked, e.Package, f.Message, f.File)
+	if n := len(e.Findings) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// Unwrap returns ErrScanBlocked.
+func (e *ScanError) Unwrap() error {
+	return ErrScanBlocked
+}
+
+// SetStrictScan makes installs and updates refuse packages with
+// high-severity security findings instead of installing them.
+func (m *Manager) SetStrictScan(strict bool) {
+	m.strictScan = strict
+}
+
+// scanSource scans the files of a package before they are copied, and the
+// hook commands it registers in settings.json.
+func scanSource(src packageSource, pkgType repo.PackageType, path string, hooks []InstalledHook) ([]scan.Finding, error) {
+	files, err := src.listFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := scan.Package(pkgType, files, src.readFile)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, scanHookCommands(repo.ManifestPath(pkgType, path), hooks)...)
+	scan.Sort(findings)
+	return findings, nil
+}
+
+// scanHookCommands scans the commands of settings.json rules like scripts.
+// Findings name file, the manifest the rules come from, and the event of
+// the rule.
+func scanHookCommands(file string, hooks []InstalledHook) []scan.Finding {
+	var findings []scan.Finding
+	for _, h := range hooks {
+		for _, f := range scan.Script(file, []byte(h.Command)) {
+			f.Line = 0
+			f.Message = fmt.Sprintf("%s hook command %s", h.Event, f.Message)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// scanSpec scans the files of the version of a package an install spec
+// resolves to, installed as name, and returns the settings.json rules it
+// registers with the findings.
+func (m *Manager) scanSpec(spec *InstallSpec, pkgType repo.PackageType, name string) ([]scan.Finding, []InstalledHook, error) {
+	repoConfig, err := m.repoStore.Get(spec.Namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("repository not found: %w", err)
+	}
+	repoLocalPath, err := m.repoStore.RepoLocalPath(spec.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, src, err := m.resolveVersion(repoLocalPath, repoConfig, spec.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hooks []InstalledHook
+	if pkgType == repo.TypeHook {
+		hooks, err = m.hookBindings(src, spec.Path, name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	findings, err := scanSource(src, pkgType, spec.Path, hooks)
+	if err != nil {
+		return nil, nil, err
+	}
+	return findings, hooks, nil
+}
+
+// checkScan scans a package and the hook rules it registers and, in strict
+// mode, refuses it if there are high-severity findings.
+func (m *Manager) checkScan(src packageSource, pkgType repo.PackageType, path, name string, hooks []InstalledHook) error {
+	if !m.strictScan {
+		return nil
+	}
+	findings, err := scanSource(src, pkgType, path, hooks)
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", name, err)
+	}
+	if high := scan.High(findings); len(high) > 0 {
+		return &ScanError{Package: name, Findings: high}
+	}
+	return nil
+}
+
+// ScanInstalled scans the installed files of a package and the hook rules
+// it registered. Files deleted since the install are skipped.
+func (m *Manager) ScanInstalled(name string) ([]scan.Finding, error) {
+	pkg, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range pkg.Files {
+		if _, err := os.Stat(f.Target); err == nil {
+			files = append(files, filepath.ToSlash(f.Target))
+		}
+	}
+	findings, err := scan.Package(pkg.Type, files, func(file string) ([]byte, error) {
+		return os.ReadFile(filepath.FromSlash(file))
+	})
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, scanHookCommands(repo.ManifestPath(pkg.Type, pkg.SourcePath), pkg.Hooks)...)
+	scan.Sort(findings)
+	return findings, nil
+}
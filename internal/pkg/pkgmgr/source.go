@@ -0,0 +1,70 @@
+package pkgmgr
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+)
+
+// packageSource reads package files from a local repository clone.
+// When commit is set, files come from that commit's tree instead of the
+// working tree, so pinned installs never disturb the checkout.
+type packageSource struct {
+	repoPath string
+	commit   string
+}
+
+// listFiles returns the repository-relative paths of all files under path.
+func (s packageSource) listFiles(path string) ([]string, error) {
+	if s.commit != "" {
+		return git.ListTreeFiles(s.repoPath, s.commit, path)
+	}
+
+	var files []string
+	err := filepath.Walk(filepath.Join(s.repoPath, path), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.repoPath, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// readFile returns the content of a repository-relative file.
+// Missing files yield an error matching os.ErrNotExist.
+func (s packageSource) readFile(path string) ([]byte, error) {
+	if s.commit == "" {
+		return os.ReadFile(filepath.Join(s.repoPath, filepath.FromSlash(path)))
+	}
+
+	files, err := git.ListTreeFiles(s.repoPath, s.commit, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) != 1 || files[0] != path {
+		return nil, &os.PathError{Op: "read", Path: path, Err: os.ErrNotExist}
+	}
+	return git.ReadFileAt(s.repoPath, s.commit, path)
+}
+
+// copyTo copies a repository-relative file to dest.
+func (s packageSource) copyTo(path, dest string) error {
+	if s.commit == "" {
+		return copyFile(filepath.Join(s.repoPath, path), dest)
+	}
+
+	data, err := git.ReadFileAt(s.repoPath, s.commit, path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0644)
+}
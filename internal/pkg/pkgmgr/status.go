@@ -0,0 +1,187 @@
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+// ErrLocalModifications is returned when updating a package whose installed files were changed locally.
+var ErrLocalModifications = errors.New("package has local modifications")
+
+const packageHistorySubDir = ".history/packages"
+
+// skillHistoryDir is where jd skills adapt and edit keep the versions of a
+// skill, inside the skill directory. It is the user's, not the package's.
+const skillHistoryDir = ".history"
+
+// PackageStatus describes how installed files differ from what was installed.
+type PackageStatus struct {
+	Package   *InstalledPackage `json:"package"`
+	Modified  []string          `json:"modified,omitempty"`  // content changed since install
+	Missing   []string          `json:"missing,omitempty"`   // installed file no longer exists
+	Extra     []string          `json:"extra,omitempty"`     // files added to a skill directory
+	Untracked bool              `json:"untracked,omitempty"` // installed without content hashes
+}
+
+// IsClean reports whether the package has no local changes.
+func (s *PackageStatus) IsClean() bool {
+	return len(s.Modified) == 0 && len(s.Missing) == 0 && len(s.Extra) == 0
+}
+
+// HasLocalEdits reports whether an update would overwrite user changes.
+func (s *PackageStatus) HasLocalEdits() bool {
+	return len(s.Modified) > 0 || len(s.Extra) > 0
+}
+
+// Status checks installed files of packages against their recorded hashes.
+// Without names, all installed packages are checked.
+func (m *Manager) Status(names ...string) ([]PackageStatus, error) {
+	installed, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []PackageStatus
+	if len(names) == 0 {
+		for i := range installed.Packages {
+			status, err := m.packageStatus(&installed.Packages[i])
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, *status)
+		}
+		return results, nil
+	}
+
+	for _, name := range names {
+		pkg := findPackage(installed, name)
+		if pkg == nil {
+			return nil, fmt.Errorf("%s: %w", name, ErrPackageNotFound)
+		}
+		status, err := m.packageStatus(pkg)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *status)
+	}
+	return results, nil
+}
+
+// findPackage returns the installed package with the given name, or nil.
+func findPackage(installed *InstalledFile2, name string) *InstalledPackage {
+	for i := range installed.Packages {
+		if installed.Packages[i].Name == name {
+			return &installed.Packages[i]
+		}
+	}
+	return nil
+}
+
+// packageStatus compares a package's installed files with their recorded hashes.
+func (m *Manager) packageStatus(pkg *InstalledPackage) (*PackageStatus, error) {
+	status := &PackageStatus{Package: pkg}
+	tracked := make(map[string]bool, len(pkg.Files))
+
+	for _, f := range pkg.Files {
+		tracked[f.Target] = true
+
+		if f.SHA == "" {
+			status.Untracked = true
+		}
+
+		sum, err := hashFile(f.Target)
+		if err != nil {
+			if os.IsNotExist(err) {
+				status.Missing = append(status.Missing, f.Target)
+				continue
+			}
+			return nil, fmt.Errorf("hash %s: %w", f.Target, err)
+		}
+		if f.SHA != "" && sum != f.SHA {
+			status.Modified = append(status.Modified, f.Target)
+		}
+	}
+
+	// Skills are directories, so users can add files next to the installed ones
+	if pkg.Type == repo.TypeSkill {
+		skillDir, err := m.skillDir(pkg.Name)
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.Walk(skillDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				if info.Name() == skillHistoryDir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !tracked[path] {
+				status.Extra = append(status.Extra, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", skillDir, err)
+		}
+	}
+
+	return status, nil
+}
+
+// skillDir returns the install directory of a skill package.
+func (m *Manager) skillDir(name string) (string, error) {
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(claudeDir, "skills", name), nil
+}
+
+// Backup copies modified and extra files of a package to
+// .history/packages/<name>/<timestamp>/ and returns that directory.
+// Returns an empty string if there is nothing to back up.
+func (m *Manager) Backup(name string) (string, error) {
+	statuses, err := m.Status(name)
+	if err != nil {
+		return "", err
+	}
+	status := statuses[0]
+
+	files := append(append([]string{}, status.Modified...), status.Extra...)
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	claudeDir, err := m.expandClaudeDir()
+	if err != nil {
+		return "", err
+	}
+
+	backupDir := filepath.Join(claudeDir, packageHistorySubDir, name, time.Now().Format("20060102-150405"))
+	for _, f := range files {
+		rel, err := filepath.Rel(claudeDir, f)
+		if err != nil {
+			return "", err
+		}
+		dest := filepath.Join(backupDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return "", fmt.Errorf("create backup directory: %w", err)
+		}
+		if err := copyFile(f, dest); err != nil {
+			return "", fmt.Errorf("backup %s: %w", f, err)
+		}
+	}
+
+	return backupDir, nil
+}
@@ -0,0 +1,134 @@
+package pkgmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itda-skills/jindo/internal/skill"
+)
+
+func TestStatusAndUpdateRefusal(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "notes.md"), "notes")
+		commitAll(t, upstream, "v1")
+	})
+
+	pkg, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	for _, f := range pkg.Files {
+		if len(f.SHA) != 64 {
+			t.Errorf("file %s has no SHA-256 recorded: %q", f.Source, f.SHA)
+		}
+	}
+
+	statuses, err := env.manager.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].IsClean() {
+		t.Fatalf("expected clean status, got %+v", statuses)
+	}
+
+	skillDir := filepath.Join(env.claudeDir, "skills", "test--demo")
+	writeFile(t, filepath.Join(skillDir, "SKILL.md"), "edited")
+	writeFile(t, filepath.Join(skillDir, "extra.md"), "mine")
+	if err := os.Remove(filepath.Join(skillDir, "notes.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err = env.manager.Status("test--demo")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	s := statuses[0]
+	if len(s.Modified) != 1 || len(s.Missing) != 1 || len(s.Extra) != 1 {
+		t.Fatalf("unexpected status: %+v", s)
+	}
+
+	if _, err := env.manager.Update("test--demo", UpdateOptions{}); !errors.Is(err, ErrLocalModifications) {
+		t.Fatalf("Update error = %v, want %v", err, ErrLocalModifications)
+	}
+
+	backupDir, err := env.manager.Backup("test--demo")
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(backupDir, "skills", "test--demo", "SKILL.md"))
+	if err != nil || string(content) != "edited" {
+		t.Errorf("backup content = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "skills", "test--demo", "extra.md")); err != nil {
+		t.Errorf("extra file not backed up: %v", err)
+	}
+
+	if _, err := env.manager.Update("test--demo", UpdateOptions{OverwriteLocal: true}); err != nil {
+		t.Fatalf("forced Update failed: %v", err)
+	}
+	statuses, err = env.manager.Status("test--demo")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !statuses[0].IsClean() {
+		t.Errorf("expected clean status after forced update, got %+v", statuses[0])
+	}
+}
+
+func TestUpdateKeepsSkillHistory(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	if _, err := env.manager.Install("test:skills/demo", false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// jd skills adapt saves the current version to .history before editing
+	skillDir := filepath.Join(env.claudeDir, "skills", "test--demo")
+	history := skill.NewHistoryManager(skillDir)
+	if _, err := history.SaveVersion("v1"); err != nil {
+		t.Fatalf("SaveVersion failed: %v", err)
+	}
+
+	statuses, err := env.manager.Status("test--demo")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !statuses[0].IsClean() {
+		t.Fatalf("history counted as a local change: %+v", statuses[0])
+	}
+
+	writeFile(t, filepath.Join(env.upstream, "skills", "demo", "SKILL.md"), "v2")
+	commitAll(t, env.upstream, "v2")
+	if _, err := env.manager.Update("test--demo", UpdateOptions{}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(skillDir, "SKILL.md")); string(content) != "v2" {
+		t.Errorf("SKILL.md = %q after update, want v2", content)
+	}
+	if versions, err := history.ListVersions(); err != nil || len(versions) != 1 {
+		t.Errorf("history after update = %v, %v; want the adapted version", versions, err)
+	}
+
+	// A forced update over an adapted skill replaces the edit but keeps its history
+	if _, err := history.SaveVersion("v2"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(skillDir, "SKILL.md"), "adapted")
+	writeFile(t, filepath.Join(env.upstream, "skills", "demo", "SKILL.md"), "v3")
+	commitAll(t, env.upstream, "v3")
+	if _, err := env.manager.Update("test--demo", UpdateOptions{}); !errors.Is(err, ErrLocalModifications) {
+		t.Fatalf("Update error = %v, want %v", err, ErrLocalModifications)
+	}
+	if _, err := env.manager.Update("test--demo", UpdateOptions{OverwriteLocal: true}); err != nil {
+		t.Fatalf("forced Update failed: %v", err)
+	}
+	if versions, err := history.ListVersions(); err != nil || len(versions) != 2 {
+		t.Errorf("history after forced update = %v, %v; want both versions", versions, err)
+	}
+}
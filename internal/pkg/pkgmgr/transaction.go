@@ -0,0 +1,230 @@
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stagingSubDir holds in-flight install transactions. It lives inside the
+// .claude directory so staged files can be renamed into place atomically.
+const stagingSubDir = ".staging/packages"
+
+// transaction swaps package files into the .claude directory.
+// New files are staged under stageDir, laid out like the .claude directory.
+// On commit, every entry about to be replaced is moved aside into backupDir
+// and only discarded once the manifest is saved; any failure moves the
+// staged entries out again and restores the previous ones.
+//
+// Entries are the unit of replacement: a skill directory ("skills/<name>")
+// or a single command, agent or hook file (see entryOf).
+type transaction struct {
+	claudeDir string
+	dir       string
+	stageDir  string
+	backupDir string
+	moved     []string // entries moved from claudeDir to backupDir
+	placed    []string // entries moved from stageDir to claudeDir
+}
+
+// newTransaction creates a transaction with an empty staging directory.
+func newTransaction(claudeDir string) (*transaction, error) {
+	root := filepath.Join(claudeDir, stagingSubDir)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(root, "tx-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+
+	return &transaction{
+		claudeDir: claudeDir,
+		dir:       dir,
+		stageDir:  filepath.Join(dir, "new"),
+		backupDir: filepath.Join(dir, "old"),
+	}, nil
+}
+
+// close removes the transaction's staging and backup files.
+func (tx *transaction) close() {
+	_ = os.RemoveAll(tx.dir)
+	// Remove .staging/packages and .staging if nothing else is in flight
+	root := filepath.Join(tx.claudeDir, stagingSubDir)
+	_ = os.Remove(root)
+	_ = os.Remove(filepath.Dir(root))
+}
+
+// finalFiles maps staged file targets to their location in the .claude directory.
+func (tx *transaction) finalFiles(staged []InstalledFile) []InstalledFile {
+	files := make([]InstalledFile, len(staged))
+	for i, f := range staged {
+		if rel, err := filepath.Rel(tx.stageDir, f.Target); err == nil {
+			f.Target = filepath.Join(tx.claudeDir, rel)
+		}
+		files[i] = f
+	}
+	return files
+}
+
+// commit replaces the entries of old with the staged entries of files,
+// then calls save. If any step fails, the previous entries are restored
+// and the manifest is left as it was.
+func (tx *transaction) commit(old, files []InstalledFile, save func() error) error {
+	var replaced, added []string
+	seen := make(map[string]bool)
+	for _, f := range old {
+		if e, ok := entryOf(tx.claudeDir, f.Target); ok && !seen[e] {
+			seen[e] = true
+			replaced = append(replaced, e)
+		}
+	}
+	for _, f := range files {
+		e, ok := entryOf(tx.claudeDir, f.Target)
+		if !ok || contains(added, e) {
+			continue
+		}
+		added = append(added, e)
+		if !seen[e] {
+			seen[e] = true
+			replaced = append(replaced, e)
+		}
+	}
+
+	for _, e := range replaced {
+		if err := tx.moveAside(e); err != nil {
+			return tx.rollback(err)
+		}
+	}
+
+	for _, e := range added {
+		dest := filepath.Join(tx.claudeDir, e)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return tx.rollback(fmt.Errorf("create directory: %w", err))
+		}
+		if err := os.Rename(filepath.Join(tx.stageDir, e), dest); err != nil {
+			return tx.rollback(fmt.Errorf("move %s into place: %w", e, err))
+		}
+		tx.placed = append(tx.placed, e)
+	}
+
+	if err := save(); err != nil {
+		return tx.rollback(err)
+	}
+
+	for _, e := range replaced {
+		if !contains(added, e) {
+			removeEmptyParents(tx.claudeDir, e)
+		}
+	}
+
+	return nil
+}
+
+// moveAside moves an existing entry from the .claude directory into backupDir.
+func (tx *transaction) moveAside(entry string) error {
+	src := filepath.Join(tx.claudeDir, entry)
+	if _, err := os.Lstat(src); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	dest := filepath.Join(tx.backupDir, entry)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return fmt.Errorf("move %s aside: %w", entry, err)
+	}
+
+	tx.moved = append(tx.moved, entry)
+	return nil
+}
+
+// rollback removes placed entries and restores moved ones, returning cause
+// annotated with any restore failures.
+func (tx *transaction) rollback(cause error) error {
+	var errs []error
+	for i := len(tx.placed) - 1; i >= 0; i-- {
+		if err := os.RemoveAll(filepath.Join(tx.claudeDir, tx.placed[i])); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := len(tx.moved) - 1; i >= 0; i-- {
+		dest := filepath.Join(tx.claudeDir, tx.moved[i])
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Rename(filepath.Join(tx.backupDir, tx.moved[i]), dest); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	tx.placed, tx.moved = nil, nil
+
+	if len(errs) > 0 {
+		// Keep the backups so the user can recover them by hand
+		tx.dir = ""
+		return fmt.Errorf("%w (rollback failed, previous files kept in %s: %v)", cause, tx.backupDir, errors.Join(errs...))
+	}
+	return cause
+}
+
+// entryOf returns the entry containing target, relative to base: the skill
+// directory ("skills/<name>") for skill files, otherwise the file itself.
+// Nested commands and agents share their subdirectory with other packages,
+// so only the file is replaced.
+func entryOf(base, target string) (string, bool) {
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	parts := strings.SplitN(rel, string(filepath.Separator), 3)
+	if parts[0] == "skills" && len(parts) == 3 {
+		return filepath.Join(parts[0], parts[1]), true
+	}
+	return rel, true
+}
+
+// removeEmptyParents removes directories left empty by a removed entry,
+// up to but not including the type directory (commands/, agents/, ...).
+func removeEmptyParents(base, entry string) {
+	dir := filepath.Dir(entry)
+	for strings.Contains(dir, string(filepath.Separator)) {
+		if err := os.Remove(filepath.Join(base, dir)); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package pkgmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateRollback(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		commitAll(t, upstream, "v1")
+	})
+
+	pkg, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// The skill disappears upstream, so the reinstall fails
+	if err := os.RemoveAll(filepath.Join(env.upstream, "skills")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(env.upstream, "README.md"), "moved")
+	commitAll(t, env.upstream, "remove skill")
+
+	if _, err := env.manager.Update(pkg.Name, UpdateOptions{}); err == nil {
+		t.Fatal("expected Update to fail")
+	}
+
+	got, err := env.manager.Get(pkg.Name)
+	if err != nil {
+		t.Fatalf("package dropped from manifest: %v", err)
+	}
+	if got.Version.SHA != pkg.Version.SHA {
+		t.Errorf("manifest version changed to %s", got.Version.SHA)
+	}
+
+	content, err := os.ReadFile(filepath.Join(env.claudeDir, "skills", pkg.Name, "SKILL.md"))
+	if err != nil || string(content) != "v1" {
+		t.Errorf("installed file = %q, %v; want %q", content, err, "v1")
+	}
+
+	if _, err := os.Stat(filepath.Join(env.claudeDir, ".staging")); !os.IsNotExist(err) {
+		t.Errorf("staging directory left behind")
+	}
+}
+
+func TestInstallSaveFailure(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "commands", "hello.md"), "upstream")
+		commitAll(t, upstream, "initial")
+	})
+
+	existing := filepath.Join(env.claudeDir, "commands", "test--hello.md")
+	writeFile(t, existing, "mine")
+
+	// A directory in place of the temporary manifest makes save fail
+	manifest, err := env.manager.installedFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(manifest+".tmp", "block"), "")
+
+	if _, err := env.manager.Install("test:commands/hello.md", false); err == nil {
+		t.Fatal("expected Install to fail")
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil || string(content) != "mine" {
+		t.Errorf("existing file = %q, %v; want it restored", content, err)
+	}
+
+	if pkgs, _ := env.manager.List(); len(pkgs) != 0 {
+		t.Errorf("manifest lists %d packages, want 0", len(pkgs))
+	}
+}
+
+func TestUpdateReplacesFiles(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "old.md"), "old")
+		commitAll(t, upstream, "v1")
+	})
+
+	pkg, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(env.upstream, "skills", "demo", "old.md")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(env.upstream, "skills", "demo", "SKILL.md"), "v2")
+	commitAll(t, env.upstream, "v2")
+
+	updated, err := env.manager.Update(pkg.Name, UpdateOptions{})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !updated.InstalledAt.Equal(pkg.InstalledAt) {
+		t.Errorf("InstalledAt changed on update")
+	}
+
+	skillDir := filepath.Join(env.claudeDir, "skills", pkg.Name)
+	if content, _ := os.ReadFile(filepath.Join(skillDir, "SKILL.md")); string(content) != "v2" {
+		t.Errorf("SKILL.md = %q, want %q", content, "v2")
+	}
+	if _, err := os.Stat(filepath.Join(skillDir, "old.md")); !os.IsNotExist(err) {
+		t.Errorf("file removed upstream is still installed")
+	}
+}
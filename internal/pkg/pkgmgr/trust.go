@@ -0,0 +1,192 @@
+package pkgmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/pkg/config"
+)
+
+// Trust policy config keys (ITDA_PKG_REQUIRE_SIGNED, ITDA_PKG_TRUSTED_NAMESPACES).
+const (
+	RequireSignedKey     = "pkg.require_signed"
+	TrustedNamespacesKey = "pkg.trusted_namespaces"
+)
+
+// ErrUntrusted is returned when the trust policy refuses a package from an
+// unverified commit.
+var ErrUntrusted = errors.New("package comes from an unverified commit")
+
+// TrustPolicy decides which commits hooks and agents may be installed from.
+// Hooks run as scripts and agents carry tool grants, so they are checked;
+// skills and commands are not.
+type TrustPolicy struct {
+	RequireSigned     bool     // refuse unverified packages instead of warning
+	TrustedNamespaces []string // repositories trusted without a signature check
+}
+
+// TrustStatus is the outcome of a provenance check.
+type TrustStatus string
+
+const (
+	// TrustVerified means the commit is signed by a trusted key of the repository.
+	TrustVerified TrustStatus = "verified"
+	// TrustNamespace means the repository is trusted by policy without a signature check.
+	TrustNamespace TrustStatus = "trusted-namespace"
+	// TrustUnverified means the commit is unsigned or not signed by a trusted key.
+	TrustUnverified TrustStatus = "unverified"
+)
+
+// Provenance records how the commit a package was installed from was checked.
+type Provenance struct {
+	Status TrustStatus `json:"status"`
+	Signer string      `json:"signer,omitempty"` // fingerprint of the signing key
+	Reason string      `json:"reason,omitempty"` // why the commit is unverified
+}
+
+// TrustError is returned when a package is refused by the trust policy.
+type TrustError struct {
+	Package string
+	SHA     string
+	Reason  string
+}
+
+// Error implements error.
+func (e *TrustError) Error() string {
+	return fmt.Sprintf("%v: %s at %s: %s", ErrUntrusted, e.Package, shortCommit(e.SHA), e.Reason)
+}
+
+// Unwrap returns ErrUntrusted.
+func (e *TrustError) Unwrap() error {
+	return ErrUntrusted
+}
+
+// LoadTrustPolicy reads the trust policy from the config file. A config
+// that cannot be read or parsed is an error rather than an empty policy,
+// so a typo cannot silently turn off require_signed.
+func LoadTrustPolicy() (TrustPolicy, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return TrustPolicy{}, fmt.Errorf("load config: %w", err)
+	}
+	return TrustPolicyFromConfig(cfg)
+}
+
+// TrustPolicyFromConfig reads the trust policy from cfg and the
+// environment. pkg.trusted_namespaces is a list or a comma-separated string.
+func TrustPolicyFromConfig(cfg *config.Config) (TrustPolicy, error) {
+	var policy TrustPolicy
+	if value, found := cfg.GetWithEnv(RequireSignedKey); found {
+		required, ok := value.(bool)
+		if !ok {
+			return policy, fmt.Errorf("invalid %s: %v (use true or false)", RequireSignedKey, value)
+		}
+		policy.RequireSigned = required
+	}
+
+	if value, found := cfg.GetWithEnv(TrustedNamespacesKey); found {
+		var names []string
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				names = append(names, fmt.Sprint(item))
+			}
+		default:
+			names = strings.Split(fmt.Sprint(v), ",")
+		}
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" {
+				policy.TrustedNamespaces = append(policy.TrustedNamespaces, name)
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// SetTrustPolicy sets the policy applied to hook and agent installs.
+func (m *Manager) SetTrustPolicy(policy TrustPolicy) {
+	m.trust = policy
+}
+
+// checkTrust checks the commit a package is installed from against the
+// trust policy. It returns nil provenance for package types that are not
+// checked, and a *TrustError if the policy refuses the package.
+func (m *Manager) checkTrust(repoConfig *repo.RepoConfig, repoPath string, pkgType repo.PackageType, name, sha string) (*Provenance, error) {
+	if pkgType != repo.TypeHook && pkgType != repo.TypeAgent {
+		return nil, nil
+	}
+	if contains(m.trust.TrustedNamespaces, repoConfig.Namespace) {
+		return &Provenance{Status: TrustNamespace}, nil
+	}
+
+	prov := verifyCommit(repoConfig, repoPath, sha)
+	if prov.Status != TrustVerified && m.trust.RequireSigned {
+		return nil, &TrustError{Package: name, SHA: sha, Reason: prov.Reason}
+	}
+	return prov, nil
+}
+
+// verifyCommit checks whether a commit is signed by one of the repository's
+// trusted keys.
+func verifyCommit(repoConfig *repo.RepoConfig, repoPath, sha string) *Provenance {
+	if len(repoConfig.TrustedKeys) == 0 {
+		return &Provenance{Status: TrustUnverified, Reason: "repository has no trusted signing keys"}
+	}
+
+	dir, err := os.MkdirTemp("", "jd-trust-")
+	if err != nil {
+		return &Provenance{Status: TrustUnverified, Reason: err.Error()}
+	}
+	defer os.RemoveAll(dir)
+
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	if err := repo.WriteAllowedSigners(allowedSigners, repoConfig.TrustedKeys); err != nil {
+		return &Provenance{Status: TrustUnverified, Reason: err.Error()}
+	}
+
+	sig, err := git.CommitSignature(repoPath, sha, allowedSigners)
+	if err != nil {
+		return &Provenance{Status: TrustUnverified, Reason: fmt.Sprintf("cannot read signature: %v", err)}
+	}
+
+	switch {
+	case sig.Status == "N" || sig.Status == "":
+		return &Provenance{Status: TrustUnverified, Reason: "commit is not signed"}
+	case !sig.Good():
+		return &Provenance{Status: TrustUnverified, Signer: sig.Key, Reason: fmt.Sprintf("signature cannot be verified (status %s)", sig.Status)}
+	}
+
+	for _, key := range repoConfig.TrustedKeys {
+		fp := repo.KeyFingerprint(key)
+		if fp != "" && (matchesFingerprint(sig.Key, fp) || matchesFingerprint(sig.PrimaryKey, fp)) {
+			return &Provenance{Status: TrustVerified, Signer: sig.Key}
+		}
+	}
+	return &Provenance{Status: TrustUnverified, Signer: sig.Key, Reason: fmt.Sprintf("signed by untrusted key %s", sig.Key)}
+}
+
+// matchesFingerprint reports whether a fingerprint reported by git matches
+// a trusted one. GPG long key IDs match the end of a full fingerprint.
+func matchesFingerprint(reported, trusted string) bool {
+	if reported == "" {
+		return false
+	}
+	if reported == trusted {
+		return true
+	}
+	return len(trusted) == 16 && len(reported) == 40 && reported[24:] == trusted
+}
+
+// shortCommit abbreviates a commit SHA for messages.
+func shortCommit(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,137 @@
+package pkgmgr
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// sshSigningKey generates an SSH key pair and returns the private key path
+// and the public key line.
+func sshSigningKey(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+	path := filepath.Join(t.TempDir(), "signing")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "signer", "-f", path).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, out)
+	}
+	pub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, strings.TrimSpace(string(pub))
+}
+
+func TestInstallTrustPolicy(t *testing.T) {
+	key, pub := sshSigningKey(t)
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "hooks", "guard.sh"), "#!/bin/sh\n")
+		writeFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "demo")
+		runGit(t, upstream, "config", "gpg.format", "ssh")
+		runGit(t, upstream, "config", "user.signingkey", key)
+		runGit(t, upstream, "add", "-A")
+		runGit(t, upstream, "commit", "--quiet", "-S", "-m", "signed")
+	})
+
+	// Without trusted keys hooks install with an unverified provenance
+	pkg, err := env.manager.Install("test:hooks/guard.sh", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if pkg.Provenance == nil || pkg.Provenance.Status != TrustUnverified {
+		t.Errorf("provenance = %+v, want unverified", pkg.Provenance)
+	}
+	if err := env.manager.Uninstall(pkg.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	// ... and are refused when signatures are required
+	env.manager.SetTrustPolicy(TrustPolicy{RequireSigned: true})
+	_, err = env.manager.Install("test:hooks/guard.sh", false)
+	var trustErr *TrustError
+	if !errors.Is(err, ErrUntrusted) || !errors.As(err, &trustErr) {
+		t.Fatalf("Install error = %v, want ErrUntrusted", err)
+	}
+
+	// Skills are not checked
+	skill, err := env.manager.Install("test:skills/demo", false)
+	if err != nil {
+		t.Fatalf("skill Install failed: %v", err)
+	}
+	if skill.Provenance != nil {
+		t.Errorf("skill provenance = %+v, want none", skill.Provenance)
+	}
+
+	if err := env.manager.RepoStore().AddTrustedKey("test", pub); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err = env.manager.Install("test:hooks/guard.sh", false)
+	if err != nil {
+		t.Fatalf("Install with trusted key failed: %v", err)
+	}
+	if pkg.Provenance == nil || pkg.Provenance.Status != TrustVerified || !strings.HasPrefix(pkg.Provenance.Signer, "SHA256:") {
+		t.Errorf("provenance = %+v, want verified", pkg.Provenance)
+	}
+}
+
+func TestInstallTrustedNamespace(t *testing.T) {
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "agents", "reviewer.md"), "reviewer")
+		commitAll(t, upstream, "unsigned")
+	})
+
+	_, otherKey := sshSigningKey(t)
+	if err := env.manager.RepoStore().AddTrustedKey("test", otherKey); err != nil {
+		t.Fatal(err)
+	}
+
+	env.manager.SetTrustPolicy(TrustPolicy{RequireSigned: true})
+	if _, err := env.manager.Install("test:agents/reviewer.md", false); !errors.Is(err, ErrUntrusted) || !strings.Contains(err.Error(), "not signed") {
+		t.Fatalf("Install error = %v, want unsigned commit refused", err)
+	}
+
+	env.manager.SetTrustPolicy(TrustPolicy{RequireSigned: true, TrustedNamespaces: []string{"test"}})
+	pkg, err := env.manager.Install("test:agents/reviewer.md", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if pkg.Provenance == nil || pkg.Provenance.Status != TrustNamespace {
+		t.Errorf("provenance = %+v, want trusted-namespace", pkg.Provenance)
+	}
+}
+
+func TestLoadTrustPolicyMalformedConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("config path comes from XDG_CONFIG_HOME")
+	}
+	env := setupTestEnv(t, func(upstream string) {
+		writeFile(t, filepath.Join(upstream, "hooks", "guard.sh"), "#!/bin/sh\n")
+		commitAll(t, upstream, "unsigned")
+	})
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configPath := filepath.Join(configHome, "itda-skills", "config.toml")
+	writeFile(t, configPath, "[pkg]\nrequire_signed = tru\n")
+
+	// A typo must not fall back to an empty policy that lets unsigned hooks in
+	if _, err := LoadTrustPolicy(); err == nil {
+		t.Fatal("LoadTrustPolicy succeeded with a malformed config")
+	}
+
+	writeFile(t, configPath, "[pkg]\nrequire_signed = true\n")
+	policy, err := LoadTrustPolicy()
+	if err != nil {
+		t.Fatalf("LoadTrustPolicy failed: %v", err)
+	}
+	env.manager.SetTrustPolicy(policy)
+	if _, err := env.manager.Install("test:hooks/guard.sh", false); !errors.Is(err, ErrUntrusted) {
+		t.Fatalf("Install error = %v, want ErrUntrusted", err)
+	}
+}
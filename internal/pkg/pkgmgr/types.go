@@ -3,6 +3,7 @@ package pkgmgr
 import (
 	"time"
 
+	"github.com/itda-skills/jindo/internal/pkg/git"
 	"github.com/itda-skills/jindo/internal/pkg/repo"
 )
 
@@ -13,24 +14,44 @@ type InstalledFile struct {
 	SHA    string `json:"sha"`    // Content SHA for change detection
 }
 
+// Version types recorded in VersionInfo.
+const (
+	VersionTypeCommit = "commit"
+	VersionTypeTag    = "tag"
+)
+
 // VersionInfo represents version information for an installed package.
 type VersionInfo struct {
-	Type string `json:"type"` // "commit" or "tag"
-	SHA  string `json:"sha"`
-	Ref  string `json:"ref"` // branch name or tag name
+	Type   string `json:"type"` // "commit" or "tag"
+	SHA    string `json:"sha"`
+	Ref    string `json:"ref"`              // branch name or tag name
+	Pinned bool   `json:"pinned,omitempty"` // installed at an explicit tag or commit
+}
+
+// InstalledHook represents a settings.json hook rule registered by a hook package.
+type InstalledHook struct {
+	Event   string `json:"event"`
+	Matcher string `json:"matcher,omitempty"`
+	Command string `json:"command"`          // rendered command
+	Script  string `json:"script,omitempty"` // installed script as the command refers to it; finds the package's rules
 }
 
 // InstalledPackage represents an installed package.
 type InstalledPackage struct {
-	Name         string          `json:"name"`          // Full name with namespace (e.g., affa-ever--web-fetch)
-	OriginalName string          `json:"original_name"` // Original name without namespace
-	Type         repo.PackageType `json:"type"`         // skill, command, agent
-	Namespace    string          `json:"namespace"`     // Repository namespace
-	SourcePath   string          `json:"source_path"`   // Path in source repository
-	Version      VersionInfo     `json:"version"`
-	Files        []InstalledFile `json:"files"`
-	InstalledAt  time.Time       `json:"installed_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	Name         string           `json:"name"`            // Install name: namespaced (affa-ever--web-fetch) unless aliased
+	OriginalName string           `json:"original_name"`   // Original name without namespace
+	Alias        bool             `json:"alias,omitempty"` // Name differs from the default install name (installed with --as)
+	Type         repo.PackageType `json:"type"`            // skill, command, agent
+	Namespace    string           `json:"namespace"`       // Repository namespace
+	SourcePath   string           `json:"source_path"`     // Path in source repository
+	Version      VersionInfo      `json:"version"`
+	Files        []InstalledFile  `json:"files"`
+	Hooks        []InstalledHook  `json:"hooks,omitempty"`      // settings.json rules registered for hook packages
+	Requires     []string         `json:"requires,omitempty"`   // Installed names of direct dependencies
+	Dependency   bool             `json:"dependency,omitempty"` // Installed only to satisfy another package
+	Provenance   *Provenance      `json:"provenance,omitempty"` // Signature check of the installed commit (hooks and agents)
+	InstalledAt  time.Time        `json:"installed_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
 }
 
 // InstalledFile represents the installed.json file structure.
@@ -48,9 +69,12 @@ type InstallSpec struct {
 
 // UpdateInfo represents update information for a package.
 type UpdateInfo struct {
-	Package     *InstalledPackage
-	CurrentSHA  string
-	LatestSHA   string
-	HasUpdate   bool
-	ChangedFiles []string
+	Package         *InstalledPackage `json:"package"`
+	CurrentSHA      string            `json:"current_sha"`
+	LatestSHA       string            `json:"latest_sha"`
+	LatestRef       string            `json:"latest_ref"` // newer tag for tag-pinned packages, otherwise the tracked branch
+	HasUpdate       bool              `json:"has_update"`
+	ChangedFiles    []string          `json:"changed_files,omitempty"`
+	Commits         []git.Commit      `json:"commits,omitempty"` // commits touching the package since CurrentSHA; filled by Outdated
+	LocallyModified bool              `json:"locally_modified"`  // installed files were edited since install; filled by Outdated
 }
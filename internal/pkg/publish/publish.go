@@ -0,0 +1,287 @@
+// Package publish copies local skills, commands, agents and hooks into the
+// repository layout that jd pkg repo add and browse understand.
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+var (
+	// ErrSourceNotFound is returned when the resource to publish does not exist.
+	ErrSourceNotFound = errors.New("resource not found")
+	// ErrAmbiguousSource is returned when a hook name matches several scripts.
+	ErrAmbiguousSource = errors.New("resource name is ambiguous")
+	// ErrNotGitRepo is returned when committing to a directory outside a git working tree.
+	ErrNotGitRepo = errors.New("not a git working tree")
+)
+
+// historyDirName is the backup directory written by adapt, never published.
+const historyDirName = ".history"
+
+// Options controls a publish.
+type Options struct {
+	Commit  bool   // commit the published files and README with git
+	Message string // commit message; a default is used if empty
+}
+
+// Result describes a published resource.
+type Result struct {
+	Type      repo.PackageType `json:"type"`
+	Name      string           `json:"name"`
+	Source    string           `json:"source"`
+	Target    string           `json:"target"` // repository path, e.g. skills/web-fetch
+	Files     []string         `json:"files"`  // repository paths written
+	Replaced  bool             `json:"replaced,omitempty"`
+	Readme    bool             `json:"readme,omitempty"` // README.md package index changed
+	Committed bool             `json:"committed,omitempty"`
+}
+
+// typeDirs maps package types to their directory in .claude and in a repository.
+var typeDirs = map[repo.PackageType]string{
+	repo.TypeSkill:   "skills",
+	repo.TypeCommand: "commands",
+	repo.TypeAgent:   "agents",
+	repo.TypeHook:    "hooks",
+}
+
+// SourcePath returns the path of a resource in a .claude directory. Nested
+// commands and agents are named dir:name, as they are invoked. Hooks may be
+// named with or without their script extension.
+func SourcePath(claudeDir string, pkgType repo.PackageType, name string) (string, error) {
+	claudeDir, err := expandPath(claudeDir)
+	if err != nil {
+		return "", err
+	}
+	typeDir, ok := typeDirs[pkgType]
+	if !ok {
+		return "", fmt.Errorf("unsupported package type: %s", pkgType)
+	}
+	dir := filepath.Join(claudeDir, typeDir)
+
+	var path string
+	switch pkgType {
+	case repo.TypeSkill:
+		path = filepath.Join(dir, name)
+	case repo.TypeCommand, repo.TypeAgent:
+		rel := filepath.FromSlash(strings.ReplaceAll(strings.TrimSuffix(name, ".md"), ":", "/"))
+		path = filepath.Join(dir, rel+".md")
+	case repo.TypeHook:
+		return hookSourcePath(dir, name)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w: %s %s", ErrSourceNotFound, pkgType, name)
+	}
+	return path, nil
+}
+
+// hookSourcePath finds a hook script by file name, or by name without extension.
+func hookSourcePath(dir, name string) (string, error) {
+	path := filepath.Join(dir, name)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path, nil
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, name+".*"))
+	var scripts []string
+	for _, m := range matches {
+		if !repo.IsManifestFile(filepath.Base(m)) {
+			scripts = append(scripts, m)
+		}
+	}
+	switch len(scripts) {
+	case 0:
+		return "", fmt.Errorf("%w: hook %s", ErrSourceNotFound, name)
+	case 1:
+		return scripts[0], nil
+	default:
+		return "", fmt.Errorf("%w: hook %s matches %d scripts", ErrAmbiguousSource, name, len(scripts))
+	}
+}
+
+// TargetPath returns the repository path a resource is published to.
+func TargetPath(pkgType repo.PackageType, name, source string) string {
+	typeDir := typeDirs[pkgType]
+	switch pkgType {
+	case repo.TypeCommand, repo.TypeAgent:
+		return typeDir + "/" + strings.ReplaceAll(strings.TrimSuffix(name, ".md"), ":", "/") + ".md"
+	default:
+		return typeDir + "/" + filepath.Base(source)
+	}
+}
+
+// Publish copies the resource at source into the repository at destDir,
+// replacing an earlier copy, and refreshes the README package index.
+// .history directories are left out. With opts.Commit, destDir must be a
+// git working tree and only the published files and README are committed.
+func Publish(pkgType repo.PackageType, name, source, destDir string, opts Options) (*Result, error) {
+	destDir, err := expandPath(destDir)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Commit && !git.IsWorkTree(destDir) {
+		return nil, fmt.Errorf("%w: %s", ErrNotGitRepo, destDir)
+	}
+
+	result := &Result{
+		Type:   pkgType,
+		Name:   name,
+		Source: source,
+		Target: TargetPath(pkgType, name, source),
+	}
+
+	target := filepath.Join(destDir, filepath.FromSlash(result.Target))
+	if _, err := os.Lstat(target); err == nil {
+		result.Replaced = true
+		if err := os.RemoveAll(target); err != nil {
+			return nil, fmt.Errorf("remove previous copy: %w", err)
+		}
+	}
+
+	if err := copyTree(source, target, func(rel string) {
+		result.Files = append(result.Files, joinSlash(result.Target, rel))
+	}); err != nil {
+		return nil, fmt.Errorf("copy %s: %w", source, err)
+	}
+
+	// Sidecar manifests of single-file packages sit next to the file
+	if pkgType != repo.TypeSkill {
+		manifest := repo.ManifestPath(pkgType, filepath.ToSlash(source))
+		if _, err := os.Stat(filepath.FromSlash(manifest)); err == nil {
+			manifestTarget := repo.ManifestPath(pkgType, result.Target)
+			if err := copyFile(filepath.FromSlash(manifest), filepath.Join(destDir, filepath.FromSlash(manifestTarget))); err != nil {
+				return nil, fmt.Errorf("copy manifest: %w", err)
+			}
+			result.Files = append(result.Files, manifestTarget)
+		}
+	}
+
+	result.Readme, err = UpdateReadme(destDir)
+	if err != nil {
+		return nil, fmt.Errorf("update README: %w", err)
+	}
+
+	if opts.Commit {
+		message := opts.Message
+		if message == "" {
+			message = fmt.Sprintf("Publish %s %s", pkgType, name)
+		}
+		paths := []string{result.Target, readmeName}
+		if pkgType != repo.TypeSkill {
+			paths = append(paths, repo.ManifestPath(pkgType, result.Target))
+		}
+		result.Committed, err = git.CommitPaths(destDir, message, existing(destDir, paths)...)
+		if err != nil {
+			return nil, fmt.Errorf("commit: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// existing returns the paths that exist under dir, so an absent optional
+// manifest does not fail git add.
+func existing(dir string, paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if _, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(p))); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// copyTree copies a file or directory to dst, skipping .history
+// directories, and calls written with each file path relative to src.
+func copyTree(src, dst string, written func(rel string)) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if err := copyFile(src, dst); err != nil {
+			return err
+		}
+		written("")
+		return nil
+	}
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == historyDirName {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err := copyFile(path, filepath.Join(dst, rel)); err != nil {
+			return err
+		}
+		written(filepath.ToSlash(rel))
+		return nil
+	})
+}
+
+// copyFile copies a regular file, keeping its permission bits.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// joinSlash joins a repository path and a relative path, either of which may be empty.
+func joinSlash(base, rel string) string {
+	if rel == "" {
+		return base
+	}
+	return base + "/" + rel
+}
+
+// expandPath expands a leading ~ to the home directory.
+func expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, path[1:]), nil
+	}
+	return path, nil
+}
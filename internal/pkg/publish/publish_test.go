@@ -0,0 +1,157 @@
+package publish
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestSourcePath(t *testing.T) {
+	claudeDir := t.TempDir()
+	writeFile(t, filepath.Join(claudeDir, "skills", "demo", "SKILL.md"), "demo")
+	writeFile(t, filepath.Join(claudeDir, "commands", "game", "init.md"), "init")
+	writeFile(t, filepath.Join(claudeDir, "hooks", "guard.sh"), "#!/bin/sh")
+	writeFile(t, filepath.Join(claudeDir, "hooks", "guard.jd-package.yaml"), "hooks: []")
+
+	tests := []struct {
+		pkgType repo.PackageType
+		name    string
+		want    string
+	}{
+		{repo.TypeSkill, "demo", "skills/demo"},
+		{repo.TypeCommand, "game:init", "commands/game/init.md"},
+		{repo.TypeHook, "guard", "hooks/guard.sh"},
+		{repo.TypeHook, "guard.sh", "hooks/guard.sh"},
+	}
+	for _, tt := range tests {
+		got, err := SourcePath(claudeDir, tt.pkgType, tt.name)
+		if err != nil {
+			t.Errorf("SourcePath(%s, %s) error: %v", tt.pkgType, tt.name, err)
+			continue
+		}
+		if want := filepath.Join(claudeDir, filepath.FromSlash(tt.want)); got != want {
+			t.Errorf("SourcePath(%s, %s) = %s, want %s", tt.pkgType, tt.name, got, want)
+		}
+		if target := TargetPath(tt.pkgType, tt.name, got); target != tt.want {
+			t.Errorf("TargetPath(%s, %s) = %s, want %s", tt.pkgType, tt.name, target, tt.want)
+		}
+	}
+
+	if _, err := SourcePath(claudeDir, repo.TypeAgent, "missing"); !errors.Is(err, ErrSourceNotFound) {
+		t.Errorf("missing agent error = %v, want ErrSourceNotFound", err)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	claudeDir := t.TempDir()
+	source := filepath.Join(claudeDir, "skills", "demo")
+	writeFile(t, filepath.Join(source, "SKILL.md"), "---\ndescription: Demo skill\n---\n")
+	writeFile(t, filepath.Join(source, ".history", "SKILL.md.1"), "old")
+
+	dest := filepath.Join(t.TempDir(), "my-skills")
+	result, err := Publish(repo.TypeSkill, "demo", source, dest, Options{})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Target != "skills/demo" || result.Replaced || !result.Readme || len(result.Files) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "skills", "demo", ".history")); !os.IsNotExist(err) {
+		t.Errorf(".history was published: %v", err)
+	}
+
+	readme, _ := os.ReadFile(filepath.Join(dest, "README.md"))
+	if !strings.Contains(string(readme), "- [demo](skills/demo) - Demo skill") {
+		t.Fatalf("README index missing skill:\n%s", readme)
+	}
+	items := repo.ScanDir(dest)
+	if len(items) != 1 || items[0].Path != "skills/demo" {
+		t.Errorf("published layout not browsable: %+v", items)
+	}
+
+	// Hand-written README text survives regeneration
+	writeFile(t, filepath.Join(dest, "README.md"), "# Mine\n\nIntro.\n\n"+string(readme)[strings.Index(string(readme), indexStart):]+"\nFooter.\n")
+	writeFile(t, filepath.Join(claudeDir, "commands", "hello.md"), "---\ndescription: Say hello\n---\n")
+	if _, err := Publish(repo.TypeCommand, "hello", filepath.Join(claudeDir, "commands", "hello.md"), dest, Options{}); err != nil {
+		t.Fatalf("Publish command failed: %v", err)
+	}
+	readme, _ = os.ReadFile(filepath.Join(dest, "README.md"))
+	for _, want := range []string{"Intro.", "Footer.", "- [demo](skills/demo)", "- [hello](commands/hello.md) - Say hello"} {
+		if !strings.Contains(string(readme), want) {
+			t.Errorf("README missing %q:\n%s", want, readme)
+		}
+	}
+
+	result, err = Publish(repo.TypeSkill, "demo", source, dest, Options{})
+	if err != nil {
+		t.Fatalf("republish failed: %v", err)
+	}
+	if !result.Replaced || result.Readme {
+		t.Errorf("unexpected republish result: %+v", result)
+	}
+}
+
+func TestPublishCommit(t *testing.T) {
+	claudeDir := t.TempDir()
+	source := filepath.Join(claudeDir, "agents", "reviewer.md")
+	writeFile(t, source, "---\nname: reviewer\n---\n")
+
+	dest := t.TempDir()
+	if _, err := Publish(repo.TypeAgent, "reviewer", source, dest, Options{Commit: true}); !errors.Is(err, ErrNotGitRepo) {
+		t.Fatalf("Publish error = %v, want ErrNotGitRepo", err)
+	}
+
+	runGit(t, dest, "init", "--quiet")
+	runGit(t, dest, "config", "user.name", "test")
+	runGit(t, dest, "config", "user.email", "test@example.com")
+	writeFile(t, filepath.Join(dest, "unrelated.txt"), "not committed")
+
+	result, err := Publish(repo.TypeAgent, "reviewer", source, dest, Options{Commit: true})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if !result.Committed {
+		t.Fatal("Publish did not commit")
+	}
+	files := runGit(t, dest, "show", "--name-only", "--format=%s", "HEAD")
+	if !strings.Contains(files, "Publish agent reviewer") || !strings.Contains(files, "agents/reviewer.md") || !strings.Contains(files, "README.md") || strings.Contains(files, "unrelated.txt") {
+		t.Errorf("unexpected commit:\n%s", files)
+	}
+
+	result, err = Publish(repo.TypeAgent, "reviewer", source, dest, Options{Commit: true})
+	if err != nil {
+		t.Fatalf("republish failed: %v", err)
+	}
+	if result.Committed {
+		t.Error("unchanged republish created a commit")
+	}
+}
@@ -0,0 +1,98 @@
+package publish
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+const (
+	readmeName = "README.md"
+	// The package index is kept between these markers; the rest of the
+	// README is left as written.
+	indexStart = "<!-- jd-packages:start -->"
+	indexEnd   = "<!-- jd-packages:end -->"
+)
+
+// readmeSections lists package types in README order with their headings.
+var readmeSections = []struct {
+	pkgType repo.PackageType
+	title   string
+}{
+	{repo.TypeSkill, "Skills"},
+	{repo.TypeCommand, "Commands"},
+	{repo.TypeAgent, "Agents"},
+	{repo.TypeHook, "Hooks"},
+}
+
+// UpdateReadme regenerates the package index in dir/README.md from the
+// packages found in dir. A README without an index gets one appended; a
+// missing README is created. It reports whether the file changed.
+func UpdateReadme(dir string) (bool, error) {
+	path := filepath.Join(dir, readmeName)
+	index := renderIndex(repo.ScanDir(dir))
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	old := string(data)
+
+	var updated string
+	switch {
+	case os.IsNotExist(err):
+		updated = fmt.Sprintf("# %s\n\nClaude Code packages for jd. Register this repository with\n`jd pkg repo add <url>` and browse it with `jd pkg browse`.\n\n%s", filepath.Base(dir), index)
+	default:
+		start := strings.Index(old, indexStart)
+		end := strings.Index(old, indexEnd)
+		if start >= 0 && end > start {
+			updated = old[:start] + strings.TrimSuffix(index, "\n") + old[end+len(indexEnd):]
+		} else {
+			updated = strings.TrimRight(old, "\n") + "\n\n" + index
+		}
+	}
+
+	if updated == old {
+		return false, nil
+	}
+	return true, os.WriteFile(path, []byte(updated), 0644)
+}
+
+// renderIndex renders the README package index, with its markers.
+func renderIndex(items []repo.BrowseItem) string {
+	var b strings.Builder
+	b.WriteString(indexStart + "\n")
+	b.WriteString("## Packages\n")
+
+	for _, section := range readmeSections {
+		var lines []string
+		for _, item := range items {
+			if item.Type != section.pkgType {
+				continue
+			}
+			line := fmt.Sprintf("- [%s](%s)", item.Name, item.Path)
+			if item.Description != "" {
+				line += " - " + firstLine(item.Description)
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", section.title, strings.Join(lines, "\n"))
+	}
+
+	b.WriteString(indexEnd + "\n")
+	return b.String()
+}
+
+// firstLine returns the first line of s, trimmed.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,452 @@
+// Package registry subscribes to registries: curated lists of package
+// repositories kept in a JSON or TOML file, either local or in a git
+// repository. Subscribed registries keep the registered repositories in
+// sync, adding listed repositories and removing dropped ones.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	registriesFileName = "registries.json"
+	registriesDirName  = "registries"
+)
+
+// FileNames are the registry file names looked up at the root of a git
+// registry, in order.
+var FileNames = []string{"jd-registry.toml", "jd-registry.json", "registry.toml", "registry.json"}
+
+var (
+	// ErrRegistryExists is returned when subscribing to a registry name twice.
+	ErrRegistryExists = errors.New("registry already subscribed")
+	// ErrRegistryNotFound is returned when a registry is not subscribed.
+	ErrRegistryNotFound = errors.New("registry not found")
+	// ErrInvalidRegistry is returned when a registry file cannot be used.
+	ErrInvalidRegistry = errors.New("invalid registry file")
+)
+
+// nameRegex matches registry names and repository namespaces.
+var nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// File is the content of a registry file.
+type File struct {
+	Name        string  `json:"name,omitempty" toml:"name,omitempty"`
+	Description string  `json:"description,omitempty" toml:"description,omitempty"`
+	Repos       []Entry `json:"repos" toml:"repos"`
+}
+
+// Entry is a repository listed in a registry.
+type Entry struct {
+	Namespace   string   `json:"namespace,omitempty" toml:"namespace,omitempty"` // derived from the URL if empty
+	URL         string   `json:"url" toml:"url"`                                 // any location accepted by repo.ParseSource
+	Description string   `json:"description,omitempty" toml:"description,omitempty"`
+	Recommended []string `json:"recommended,omitempty" toml:"recommended,omitempty"` // package paths, e.g. skills/web-fetch
+	Unprefixed  bool     `json:"unprefixed,omitempty" toml:"unprefixed,omitempty"`
+}
+
+// Parse parses a registry file. The format is chosen by the extension of
+// name: .toml or .json. Missing namespaces are derived from the URLs.
+func Parse(name string, data []byte) (*File, error) {
+	var f File
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &f)
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	default:
+		return nil, fmt.Errorf("%w: %s: use a .toml or .json file", ErrInvalidRegistry, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRegistry, name, err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range f.Repos {
+		e := &f.Repos[i]
+		src, err := repo.ParseSource(e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: repos[%d]: invalid url %q", ErrInvalidRegistry, name, i, e.URL)
+		}
+		if e.Namespace == "" {
+			e.Namespace = repo.GenerateNamespace(src.Owner, src.Repo)
+		}
+		if !nameRegex.MatchString(e.Namespace) {
+			return nil, fmt.Errorf("%w: %s: repos[%d]: invalid namespace %q", ErrInvalidRegistry, name, i, e.Namespace)
+		}
+		if seen[e.Namespace] {
+			return nil, fmt.Errorf("%w: %s: duplicate namespace %q", ErrInvalidRegistry, name, e.Namespace)
+		}
+		seen[e.Namespace] = true
+	}
+	return &f, nil
+}
+
+// Registry is a subscribed registry.
+type Registry struct {
+	Name         string    `json:"name"`
+	Source       string    `json:"source"`         // absolute path of a local file, or a repository location
+	Git          bool      `json:"git,omitempty"`  // Source is a git repository cloned under registries/
+	File         string    `json:"file,omitempty"` // registry file within a git source; empty looks up FileNames
+	Description  string    `json:"description,omitempty"`
+	Entries      []Entry   `json:"entries,omitempty"` // repositories listed at the last sync
+	SubscribedAt time.Time `json:"subscribed_at"`
+	SyncedAt     time.Time `json:"synced_at,omitempty"`
+}
+
+// registriesFile represents the registries.json file structure.
+type registriesFile struct {
+	Version    int        `json:"version"`
+	Registries []Registry `json:"registries"`
+}
+
+// Store manages registry subscriptions.
+type Store struct {
+	baseDir string
+	repos   *repo.Store
+}
+
+// NewStore creates a registry store that keeps repos in sync.
+func NewStore(baseDir string, repos *repo.Store) *Store {
+	return &Store{baseDir: baseDir, repos: repos}
+}
+
+// expandDir expands ~ to home directory.
+func (s *Store) expandDir() (string, error) {
+	dir := s.baseDir
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	return dir, nil
+}
+
+// cloneDir returns the local clone directory of a git registry.
+func (s *Store) cloneDir(name string) (string, error) {
+	base, err := s.expandDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, registriesDirName, name), nil
+}
+
+// load loads the registries file.
+func (s *Store) load() (*registriesFile, error) {
+	base, err := s.expandDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(base, registriesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &registriesFile{Version: 1, Registries: []Registry{}}, nil
+		}
+		return nil, err
+	}
+
+	var f registriesFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", registriesFileName, err)
+	}
+	return &f, nil
+}
+
+// save saves the registries file.
+func (s *Store) save(f *registriesFile) error {
+	base, err := s.expandDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", registriesFileName, err)
+	}
+	if err := os.WriteFile(filepath.Join(base, registriesFileName), data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", registriesFileName, err)
+	}
+	return nil
+}
+
+// List returns the subscribed registries.
+func (s *Store) List() ([]Registry, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return f.Registries, nil
+}
+
+// Get returns a subscribed registry by name.
+func (s *Store) Get(name string) (*Registry, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range f.Registries {
+		if r.Name == name {
+			return &r, nil
+		}
+	}
+	return nil, ErrRegistryNotFound
+}
+
+// put adds or replaces a registry and saves the registries file.
+func (s *Store) put(reg Registry) error {
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i := range f.Registries {
+		if f.Registries[i].Name == reg.Name {
+			f.Registries[i] = reg
+			return s.save(f)
+		}
+	}
+	f.Registries = append(f.Registries, reg)
+	return s.save(f)
+}
+
+// Subscribe subscribes to a registry and syncs it. source is a registry
+// file or a repository containing one (file names it within the repository;
+// empty looks up FileNames). name overrides the name given in the file.
+func (s *Store) Subscribe(source, name, file string) (*Registry, *SyncResult, error) {
+	reg := Registry{Source: source, File: file, SubscribedAt: time.Now().UTC()}
+
+	var regFile *File
+	if path, ok := localFile(source); ok {
+		reg.Source = path
+		f, err := readFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		regFile = f
+	} else {
+		f, err := s.cloneRegistry(&reg, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		regFile = f
+	}
+
+	if name == "" {
+		name = regFile.Name
+	}
+	if name == "" {
+		name = defaultName(source)
+	}
+	if !nameRegex.MatchString(name) {
+		s.discardClone(&reg)
+		return nil, nil, fmt.Errorf("invalid registry name %q: use lowercase letters, digits and -", name)
+	}
+	if _, err := s.Get(name); err == nil {
+		s.discardClone(&reg)
+		return nil, nil, fmt.Errorf("%w: %s", ErrRegistryExists, name)
+	}
+	reg.Name = name
+
+	if reg.Git {
+		// Move the clone to its final place now that the name is known
+		dest, err := s.cloneDir(name)
+		if err != nil {
+			s.discardClone(&reg)
+			return nil, nil, err
+		}
+		_ = os.RemoveAll(dest)
+		if err := os.Rename(reg.Source, dest); err != nil {
+			s.discardClone(&reg)
+			return nil, nil, fmt.Errorf("move registry clone: %w", err)
+		}
+		reg.Source = source
+	}
+
+	if err := s.put(reg); err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.sync(&reg, regFile, false)
+	if err != nil {
+		return &reg, nil, err
+	}
+	return &reg, result, nil
+}
+
+// cloneRegistry clones a git registry into a temporary directory under
+// registries/, recording it as reg.Source until the name is known, and
+// reads its registry file.
+func (s *Store) cloneRegistry(reg *Registry, name string) (*File, error) {
+	src, err := repo.ParseSource(reg.Source)
+	if err != nil {
+		return nil, fmt.Errorf("registry source %q is neither a file nor a repository: %w", reg.Source, err)
+	}
+	if err := git.EnsureInstalled(); err != nil {
+		return nil, err
+	}
+
+	parent, err := s.cloneDir("")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("create registries directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(parent, ".subscribe-")
+	if err != nil {
+		return nil, err
+	}
+	if err := git.CloneQuiet(src.CloneURL, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("clone registry: %w", err)
+	}
+	reg.Git = true
+	reg.Source = dir
+
+	f, err := readGitFile(dir, reg.File)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return f, nil
+}
+
+// discardClone removes the temporary clone of a failed git subscription.
+func (s *Store) discardClone(reg *Registry) {
+	if reg.Git {
+		_ = os.RemoveAll(reg.Source)
+	}
+}
+
+// Unsubscribe removes a registry subscription. Repositories it manages are
+// removed, or kept as hand-registered repositories when keepRepos is set.
+// It returns the namespaces of those repositories.
+func (s *Store) Unsubscribe(name string, keepRepos bool) ([]string, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := -1
+	for i, r := range f.Registries {
+		if r.Name == name {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil, ErrRegistryNotFound
+	}
+	reg := f.Registries[idx]
+
+	managed, err := s.managedRepos(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range managed {
+		if keepRepos {
+			err = s.repos.SetRegistry(ns, "")
+		} else {
+			err = s.repos.Remove(ns)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ns, err)
+		}
+	}
+
+	f.Registries = append(f.Registries[:idx], f.Registries[idx+1:]...)
+	if err := s.save(f); err != nil {
+		return nil, err
+	}
+	if reg.Git {
+		if dir, err := s.cloneDir(name); err == nil {
+			_ = os.RemoveAll(dir)
+		}
+	}
+	return managed, nil
+}
+
+// managedRepos returns the namespaces of repositories a registry manages.
+func (s *Store) managedRepos(name string) ([]string, error) {
+	repos, err := s.repos.List()
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, r := range repos {
+		if r.Registry == name {
+			out = append(out, r.Namespace)
+		}
+	}
+	return out, nil
+}
+
+// localFile reports whether source names an existing regular file and
+// returns its absolute path.
+func localFile(source string) (string, bool) {
+	path := source
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	return abs, true
+}
+
+// readFile reads and parses a registry file.
+func readFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read registry: %w", err)
+	}
+	return Parse(path, data)
+}
+
+// readGitFile reads the registry file of a git registry clone.
+func readGitFile(dir, file string) (*File, error) {
+	if file != "" {
+		return readFile(filepath.Join(dir, filepath.FromSlash(file)))
+	}
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return readFile(path)
+		}
+	}
+	return nil, fmt.Errorf("%w: no %s in repository", ErrInvalidRegistry, strings.Join(FileNames, ", "))
+}
+
+// defaultName derives a registry name from its source.
+func defaultName(source string) string {
+	name := filepath.Base(strings.TrimSuffix(strings.TrimRight(source, "/"), ".git"))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if src, err := repo.ParseSource(source); err == nil && src.Repo != "" {
+		name = src.Repo
+	}
+	name = regexp.MustCompile(`[^a-z0-9-]+`).ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(name, "-")
+}
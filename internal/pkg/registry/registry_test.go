@@ -0,0 +1,312 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+// newUpstream creates a git repository with one skill and returns its path.
+func newUpstream(t *testing.T, dir string) string {
+	t.Helper()
+	skill := filepath.Join(dir, "skills", "demo", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(skill), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skill, []byte("---\nname: demo\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "-b", "main"},
+		{"add", "-A"},
+		{"commit", "--quiet", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	tomlData := `
+name = "team"
+description = "Team packages"
+
+[[repos]]
+namespace = "plat"
+url = "gh:acme/platform-skills"
+recommended = ["skills/deploy"]
+
+[[repos]]
+url = "gh:acme/tools"
+unprefixed = true
+`
+	f, err := Parse("jd-registry.toml", []byte(tomlData))
+	if err != nil {
+		t.Fatalf("Parse TOML failed: %v", err)
+	}
+	if f.Name != "team" || len(f.Repos) != 2 {
+		t.Fatalf("unexpected file: %+v", f)
+	}
+	if f.Repos[0].Recommended[0] != "skills/deploy" {
+		t.Errorf("recommended = %v", f.Repos[0].Recommended)
+	}
+	if f.Repos[1].Namespace != repo.GenerateNamespace("acme", "tools") || !f.Repos[1].Unprefixed {
+		t.Errorf("second entry = %+v", f.Repos[1])
+	}
+
+	jsonData := `{"name": "team", "repos": [{"namespace": "plat", "url": "gh:acme/platform-skills"}]}`
+	if f, err := Parse("registry.json", []byte(jsonData)); err != nil || f.Repos[0].Namespace != "plat" {
+		t.Errorf("Parse JSON = %+v, %v", f, err)
+	}
+
+	invalid := map[string]string{
+		"dup.json":   `{"repos": [{"namespace": "a", "url": "gh:x/a"}, {"namespace": "a", "url": "gh:x/b"}]}`,
+		"bad.json":   `{"repos": [{"namespace": "Bad NS", "url": "gh:x/a"}]}`,
+		"nourl.json": `{"repos": [{"namespace": "a"}]}`,
+		"file.yaml":  `repos: []`,
+	}
+	for name, data := range invalid {
+		if _, err := Parse(name, []byte(data)); !errors.Is(err, ErrInvalidRegistry) {
+			t.Errorf("Parse(%s) error = %v, want ErrInvalidRegistry", name, err)
+		}
+	}
+}
+
+func TestSubscribeSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	alpha := newUpstream(t, filepath.Join(root, "up", "alpha"))
+	beta := newUpstream(t, filepath.Join(root, "up", "beta"))
+	manual := newUpstream(t, filepath.Join(root, "up", "manual"))
+
+	base := filepath.Join(root, "base")
+	repos := repo.NewStore(base)
+	store := NewStore(base, repos)
+
+	// A repository registered by hand under a listed namespace is left alone
+	if _, err := repos.Add(manual, "man"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	regPath := filepath.Join(root, "team.toml")
+	writeRegistry := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(regPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRegistry(`name = "team"
+[[repos]]
+namespace = "alpha"
+url = "` + alpha + `"
+description = "Alpha skills"
+recommended = ["skills/demo"]
+[[repos]]
+namespace = "beta"
+url = "` + beta + `"
+[[repos]]
+namespace = "man"
+url = "` + beta + `"
+`)
+
+	reg, result, err := store.Subscribe(regPath, "", "")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if reg.Name != "team" || len(reg.Entries) != 3 {
+		t.Errorf("unexpected registry: %+v", reg)
+	}
+	if strings.Join(result.Added, ",") != "alpha,beta" || len(result.Skipped) != 1 || len(result.Failed) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	cfg, err := repos.Get("alpha")
+	if err != nil || cfg.Registry != "team" || cfg.Description != "Alpha skills" {
+		t.Errorf("alpha = %+v, %v", cfg, err)
+	}
+
+	if _, _, err := store.Subscribe(regPath, "", ""); !errors.Is(err, ErrRegistryExists) {
+		t.Errorf("second Subscribe error = %v, want ErrRegistryExists", err)
+	}
+
+	// Dropping beta and changing alpha's settings syncs both
+	writeRegistry(`name = "team"
+[[repos]]
+namespace = "alpha"
+url = "` + alpha + `"
+unprefixed = true
+`)
+	result, err = store.Sync("team", false)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if strings.Join(result.Removed, ",") != "beta" || strings.Join(result.Updated, ",") != "alpha" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err := repos.Get("beta"); !errors.Is(err, repo.ErrRepoNotFound) {
+		t.Errorf("beta still registered: %v", err)
+	}
+	if cfg, _ := repos.Get("alpha"); !cfg.Unprefixed {
+		t.Error("alpha not unprefixed")
+	}
+
+	// A move to a location that cannot be cloned keeps the old clone
+	notRepo := filepath.Join(root, "up", "empty")
+	if err := os.MkdirAll(notRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := repos.Get("alpha")
+	clone := filepath.Join(base, "repos", "alpha", "skills", "demo", "SKILL.md")
+	writeRegistry(`name = "team"
+[[repos]]
+namespace = "alpha"
+url = "` + notRepo + `"
+unprefixed = true
+`)
+	result, err = store.Sync("team", false)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Failed) != 1 || len(result.Updated) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if cfg, _ := repos.Get("alpha"); cfg.CloneURL != before.CloneURL {
+		t.Errorf("alpha moved to %s after a failed clone", cfg.CloneURL)
+	}
+	if _, err := os.Stat(clone); err != nil {
+		t.Errorf("old clone removed: %v", err)
+	}
+
+	// A move that can be cloned replaces the clone and keeps the settings
+	writeRegistry(`name = "team"
+[[repos]]
+namespace = "alpha"
+url = "` + beta + `"
+unprefixed = true
+`)
+	result, err = store.Sync("team", false)
+	if err != nil || strings.Join(result.Updated, ",") != "alpha" {
+		t.Fatalf("Sync = %+v, %v", result, err)
+	}
+	if cfg, _ := repos.Get("alpha"); cfg.CloneURL == before.CloneURL || !cfg.Unprefixed || cfg.Registry != "team" {
+		t.Errorf("alpha after move = %+v", cfg)
+	}
+	if _, err := os.Stat(clone); err != nil {
+		t.Errorf("new clone missing: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(base, "repos")); len(entries) != 2 {
+		t.Errorf("repos dir has %d entries, want alpha and man", len(entries))
+	}
+
+	removed, err := store.Unsubscribe("team", false)
+	if err != nil || strings.Join(removed, ",") != "alpha" {
+		t.Fatalf("Unsubscribe = %v, %v", removed, err)
+	}
+	left, _ := repos.List()
+	if len(left) != 1 || left[0].Namespace != "man" || left[0].Registry != "" {
+		t.Errorf("remaining repos = %+v", left)
+	}
+	if _, err := store.Get("team"); !errors.Is(err, ErrRegistryNotFound) {
+		t.Errorf("Get after Unsubscribe error = %v", err)
+	}
+}
+
+func TestSyncKeepRepos(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	alpha := newUpstream(t, filepath.Join(root, "up", "alpha"))
+	beta := newUpstream(t, filepath.Join(root, "up", "beta"))
+
+	base := filepath.Join(root, "base")
+	repos := repo.NewStore(base)
+	store := NewStore(base, repos)
+
+	regPath := filepath.Join(root, "team.toml")
+	content := `name = "team"
+[[repos]]
+namespace = "alpha"
+url = "` + alpha + `"
+`
+	if err := os.WriteFile(regPath, []byte(content+`[[repos]]
+namespace = "beta"
+url = "`+beta+`"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Subscribe(regPath, "", ""); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// Dropping beta with keepRepos leaves it registered by hand
+	if err := os.WriteFile(regPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := store.Sync("team", true)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if strings.Join(result.Kept, ",") != "beta" || len(result.Removed) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if cfg, err := repos.Get("beta"); err != nil || cfg.Registry != "" {
+		t.Errorf("beta after drop = %+v, %v", cfg, err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "repos", "beta", "skills", "demo", "SKILL.md")); err != nil {
+		t.Errorf("beta clone removed: %v", err)
+	}
+}
+
+func TestSubscribeGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	alpha := newUpstream(t, filepath.Join(root, "up", "alpha"))
+
+	regRepo := filepath.Join(root, "up", "registry")
+	if err := os.MkdirAll(regRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"repos": [{"namespace": "alpha", "url": "` + alpha + `"}]}`
+	if err := os.WriteFile(filepath.Join(regRepo, "jd-registry.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newUpstream(t, regRepo)
+
+	base := filepath.Join(root, "base")
+	store := NewStore(base, repo.NewStore(base))
+	reg, result, err := store.Subscribe(regRepo, "", "")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if reg.Name != "registry" || !reg.Git || strings.Join(result.Added, ",") != "alpha" {
+		t.Errorf("Subscribe = %+v, %+v", reg, result)
+	}
+	if _, err := os.Stat(filepath.Join(base, "registries", "registry", "jd-registry.json")); err != nil {
+		t.Errorf("registry clone missing: %v", err)
+	}
+
+	if _, err := store.Sync("registry", false); err != nil {
+		t.Errorf("Sync failed: %v", err)
+	}
+	if _, err := store.Unsubscribe("registry", true); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "registries", "registry")); !os.IsNotExist(err) {
+		t.Errorf("registry clone not removed: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package registry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+)
+
+// SyncResult reports what a sync changed in the registered repositories.
+type SyncResult struct {
+	Registry  string
+	Added     []string // namespaces registered from the registry
+	Updated   []string // managed repositories whose URL or settings changed
+	Removed   []string // managed repositories no longer listed
+	Kept      []string // managed repositories no longer listed, kept as hand-registered
+	Unchanged []string
+	Skipped   []Skip    // listed namespaces taken by repositories the registry does not manage
+	Failed    []Failure // listed repositories that could not be registered or updated
+}
+
+// Skip is a listed repository left alone because its namespace is taken.
+type Skip struct {
+	Namespace string
+	Reason    string
+}
+
+// Failure is a listed repository that could not be synced.
+type Failure struct {
+	Namespace string
+	Err       error
+}
+
+// Sync re-reads a registry, pulling git registries first, and brings the
+// registered repositories in line with it. Repositories dropped from the
+// registry are removed, or kept as hand-registered repositories when
+// keepRepos is set.
+func (s *Store) Sync(name string, keepRepos bool) (*SyncResult, error) {
+	reg, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var f *File
+	if reg.Git {
+		dir, err := s.cloneDir(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := git.PullQuiet(dir); err != nil {
+			return nil, fmt.Errorf("pull registry %s: %w", name, err)
+		}
+		f, err = readGitFile(dir, reg.File)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		f, err = readFile(reg.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.sync(reg, f, keepRepos)
+}
+
+// sync applies a parsed registry file to the repository store and records
+// it as the registry's last synced state.
+func (s *Store) sync(reg *Registry, f *File, keepRepos bool) (*SyncResult, error) {
+	repos, err := s.repos.List()
+	if err != nil {
+		return nil, err
+	}
+	byNamespace := make(map[string]repo.RepoConfig, len(repos))
+	for _, r := range repos {
+		byNamespace[r.Namespace] = r
+	}
+
+	result := &SyncResult{Registry: reg.Name}
+	listed := make(map[string]bool, len(f.Repos))
+	for _, e := range f.Repos {
+		listed[e.Namespace] = true
+
+		existing, ok := byNamespace[e.Namespace]
+		switch {
+		case !ok:
+			if err := s.addEntry(reg.Name, e); err != nil {
+				result.Failed = append(result.Failed, Failure{Namespace: e.Namespace, Err: err})
+				continue
+			}
+			result.Added = append(result.Added, e.Namespace)
+
+		case existing.Registry == "":
+			result.Skipped = append(result.Skipped, Skip{Namespace: e.Namespace, Reason: "registered by hand"})
+
+		case existing.Registry != reg.Name:
+			result.Skipped = append(result.Skipped, Skip{Namespace: e.Namespace, Reason: "managed by registry " + existing.Registry})
+
+		case !sameSource(existing, e.URL):
+			// The repository moved: re-clone it from the new location,
+			// keeping the old clone if that fails
+			moved, err := s.repos.Relocate(e.Namespace, e.URL)
+			if err != nil {
+				result.Failed = append(result.Failed, Failure{Namespace: e.Namespace, Err: err})
+				continue
+			}
+			if _, err := s.updateEntry(*moved, e); err != nil {
+				result.Failed = append(result.Failed, Failure{Namespace: e.Namespace, Err: err})
+				continue
+			}
+			result.Updated = append(result.Updated, e.Namespace)
+
+		default:
+			changed, err := s.updateEntry(existing, e)
+			if err != nil {
+				result.Failed = append(result.Failed, Failure{Namespace: e.Namespace, Err: err})
+				continue
+			}
+			if changed {
+				result.Updated = append(result.Updated, e.Namespace)
+			} else {
+				result.Unchanged = append(result.Unchanged, e.Namespace)
+			}
+		}
+	}
+
+	// Installed packages stay; only the repository registrations go
+	for _, r := range repos {
+		if r.Registry != reg.Name || listed[r.Namespace] {
+			continue
+		}
+		if keepRepos {
+			if err := s.repos.SetRegistry(r.Namespace, ""); err != nil {
+				result.Failed = append(result.Failed, Failure{Namespace: r.Namespace, Err: err})
+				continue
+			}
+			result.Kept = append(result.Kept, r.Namespace)
+			continue
+		}
+		if err := s.repos.Remove(r.Namespace); err != nil {
+			result.Failed = append(result.Failed, Failure{Namespace: r.Namespace, Err: err})
+			continue
+		}
+		result.Removed = append(result.Removed, r.Namespace)
+	}
+
+	reg.Description = f.Description
+	reg.Entries = f.Repos
+	reg.SyncedAt = time.Now().UTC()
+	if err := s.put(*reg); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addEntry registers a listed repository as managed by a registry.
+func (s *Store) addEntry(registry string, e Entry) error {
+	if _, err := s.repos.Add(e.URL, e.Namespace); err != nil {
+		return err
+	}
+	if err := s.repos.SetRegistry(e.Namespace, registry); err != nil {
+		return err
+	}
+	if e.Description != "" {
+		if err := s.repos.SetDescription(e.Namespace, e.Description); err != nil {
+			return err
+		}
+	}
+	if e.Unprefixed {
+		return s.repos.SetUnprefixed(e.Namespace, true)
+	}
+	return nil
+}
+
+// updateEntry applies the listed description and unprefixed setting to a
+// managed repository, reporting whether anything changed. An empty
+// description keeps the one fetched from the hosting service.
+func (s *Store) updateEntry(existing repo.RepoConfig, e Entry) (bool, error) {
+	changed := false
+	if e.Description != "" && e.Description != existing.Description {
+		if err := s.repos.SetDescription(e.Namespace, e.Description); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if e.Unprefixed != existing.Unprefixed {
+		if err := s.repos.SetUnprefixed(e.Namespace, e.Unprefixed); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// sameSource reports whether a registered repository was cloned from url.
+func sameSource(r repo.RepoConfig, url string) bool {
+	src, err := repo.ParseSource(url)
+	if err != nil {
+		return false
+	}
+	if r.CloneURL != "" {
+		return r.CloneURL == src.CloneURL
+	}
+	return r.URL == src.WebURL
+}
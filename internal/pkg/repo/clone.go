@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+)
+
+// PackageDirs are the directories packages are discovered in. Sparse
+// clones check out these by default.
+var PackageDirs = []string{"skills", "commands", "agents", "hooks", ".claude"}
+
+// CloneOptions controls how much of a repository Add clones.
+type CloneOptions struct {
+	FullHistory bool     // clone all history instead of only the latest commit
+	SparsePaths []string // check out only these directories (and files at the root)
+}
+
+// gitOptions returns the git clone options.
+func (o CloneOptions) gitOptions() git.CloneOptions {
+	opts := git.CloneOptions{Depth: 1, Sparse: o.SparsePaths}
+	if o.FullHistory {
+		opts.Depth = 0
+	}
+	return opts
+}
+
+// DiskUsage returns the size in bytes of a repository's local clone.
+func (s *Store) DiskUsage(namespace string) (int64, error) {
+	localPath, err := s.RepoLocalPath(namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	err = filepath.WalkDir(localPath, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// GC prunes objects of a repository's local clone that are no longer
+// reachable. Commits in keep, such as those installed packages come from,
+// are pinned first so they survive; earlier pins are dropped.
+func (s *Store) GC(namespace string, keep []string) error {
+	localPath, err := s.RepoLocalPath(namespace)
+	if err != nil {
+		return err
+	}
+	if err := git.PinCommits(localPath, keep); err != nil {
+		return err
+	}
+	return git.GC(localPath)
+}
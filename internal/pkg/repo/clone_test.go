@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+)
+
+func TestStoreAddSparseShallow(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "acme", "monorepo")
+	createFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "v1")
+	createFile(t, filepath.Join(upstream, "services", "api", "main.go"), "package main")
+	runGit(t, upstream, "init", "--quiet", "-b", "main")
+	runGit(t, upstream, "add", "-A")
+	runGit(t, upstream, "commit", "--quiet", "-m", "v1")
+	oldSHA := runGit(t, upstream, "rev-parse", "HEAD")
+	for _, v := range []string{"v2", "v3"} {
+		createFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), v)
+		runGit(t, upstream, "commit", "--quiet", "-am", v)
+	}
+
+	store := NewStore(filepath.Join(root, "base"))
+	config, err := store.AddWithOptions(upstream, "mono", CloneOptions{SparsePaths: PackageDirs})
+	if err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+	if config.FullHistory || len(config.SparsePaths) != len(PackageDirs) {
+		t.Errorf("unexpected config: %+v", config)
+	}
+
+	localPath, _ := store.RepoLocalPath("mono")
+	if _, err := os.Stat(filepath.Join(localPath, "skills", "demo", "SKILL.md")); err != nil {
+		t.Errorf("package directory not checked out: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "services")); !os.IsNotExist(err) {
+		t.Errorf("directory outside the sparse paths was checked out: %v", err)
+	}
+	if !git.IsShallow(localPath) {
+		t.Error("expected a shallow clone")
+	}
+	if dirs := git.SparseCheckoutDirs(localPath); len(dirs) == 0 {
+		t.Error("expected sparse checkout directories")
+	}
+
+	// Old commits missing from a shallow clone are fetched on demand
+	if _, err := store.Add(upstream, "plain"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	localPath, _ = store.RepoLocalPath("plain")
+	if git.HasCommit(localPath, oldSHA) {
+		t.Fatal("old commit already present in shallow clone")
+	}
+	ref, err := git.ResolveRef(localPath, oldSHA[:10])
+	if err != nil || ref.SHA != oldSHA {
+		t.Fatalf("ResolveRef(%s) = %+v, %v", oldSHA[:10], ref, err)
+	}
+	data, err := git.ReadFileAt(localPath, oldSHA, "skills/demo/SKILL.md")
+	if err != nil || string(data) != "v1" {
+		t.Errorf("ReadFileAt = %q, %v", data, err)
+	}
+
+	size, err := store.DiskUsage("plain")
+	if err != nil || size == 0 {
+		t.Fatalf("DiskUsage = %d, %v", size, err)
+	}
+	if err := store.GC("plain", []string{oldSHA}); err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if !git.HasCommit(localPath, oldSHA) {
+		t.Error("pinned commit was pruned")
+	}
+	if pinned := runGit(t, localPath, "for-each-ref", "--format=%(refname)", "refs/jd/pinned/"); pinned != "refs/jd/pinned/"+oldSHA {
+		t.Errorf("pinned refs = %q", pinned)
+	}
+}
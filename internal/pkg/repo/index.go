@@ -0,0 +1,283 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+	"gopkg.in/yaml.v3"
+)
+
+const indexFileName = "index.json"
+
+// indexFilePath returns the path to index.json.
+func (s *Store) indexFilePath() (string, error) {
+	base, err := s.expandDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, indexFileName), nil
+}
+
+// loadIndex loads the package index.
+func (s *Store) loadIndex() (*IndexFile, error) {
+	path, err := s.indexFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IndexFile{Version: 1, Repos: map[string]*RepoIndex{}}, nil
+		}
+		return nil, err
+	}
+
+	var idx IndexFile
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse index.json: %w", err)
+	}
+	if idx.Repos == nil {
+		idx.Repos = map[string]*RepoIndex{}
+	}
+
+	return &idx, nil
+}
+
+// saveIndex saves the package index.
+func (s *Store) saveIndex(idx *IndexFile) error {
+	baseDir, err := s.expandDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+
+	path, err := s.indexFilePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal index.json: %w", err)
+	}
+
+	// Write through a temporary file so a concurrent reader never sees a partial index
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write index.json: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write index.json: %w", err)
+	}
+
+	return nil
+}
+
+// Index returns the cached index of a repository, or nil if it has not
+// been indexed yet.
+func (s *Store) Index(namespace string) (*RepoIndex, error) {
+	idx, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return idx.Repos[namespace], nil
+}
+
+// Reindex scans the local clone of a repository and stores its packages
+// in the index, keeping the recorded fetch time.
+func (s *Store) Reindex(namespace string) (*RepoIndex, error) {
+	localPath, err := s.RepoLocalPath(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(localPath); os.IsNotExist(err) {
+		return nil, ErrRepoNotFound
+	}
+
+	sha, err := git.GetCurrentCommit(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("read current commit: %w", err)
+	}
+
+	idx, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &RepoIndex{
+		SHA:       sha,
+		IndexedAt: time.Now().UTC(),
+		Packages:  s.scanPackages(localPath),
+	}
+	if prev, ok := idx.Repos[namespace]; ok {
+		entry.FetchedAt = prev.FetchedAt
+	}
+	idx.Repos[namespace] = entry
+
+	if err := s.saveIndex(idx); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// refreshIndex reindexes a repository that was just fetched from its remote.
+func (s *Store) refreshIndex(namespace string) error {
+	now := time.Now().UTC()
+	if _, err := s.Reindex(namespace); err != nil {
+		return err
+	}
+	return s.MarkFetched(namespace, now)
+}
+
+// MarkFetched records that a repository was fetched from its remote at t.
+func (s *Store) MarkFetched(namespace string, t time.Time) error {
+	idx, err := s.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	entry, ok := idx.Repos[namespace]
+	if !ok {
+		entry = &RepoIndex{}
+		idx.Repos[namespace] = entry
+	}
+	entry.FetchedAt = t
+
+	return s.saveIndex(idx)
+}
+
+// LastFetched returns when a repository was last fetched from its remote,
+// or the zero time if that was never recorded.
+func (s *Store) LastFetched(namespace string) (time.Time, error) {
+	entry, err := s.Index(namespace)
+	if err != nil || entry == nil {
+		return time.Time{}, err
+	}
+	return entry.FetchedAt, nil
+}
+
+// removeIndex drops a repository from the index.
+func (s *Store) removeIndex(namespace string) error {
+	idx, err := s.loadIndex()
+	if err != nil {
+		return err
+	}
+	if _, ok := idx.Repos[namespace]; !ok {
+		return nil
+	}
+	delete(idx.Repos, namespace)
+	return s.saveIndex(idx)
+}
+
+// packages returns the packages of a repository from the index, rescanning
+// the clone if its checked-out commit differs from the indexed one.
+func (s *Store) packages(namespace, localPath string) ([]BrowseItem, error) {
+	sha, err := git.GetCurrentCommit(localPath)
+	if err != nil {
+		// Not a usable git checkout; scan without caching
+		return s.scanPackages(localPath), nil
+	}
+
+	if entry, err := s.Index(namespace); err == nil && entry != nil && entry.SHA == sha {
+		return entry.Packages, nil
+	}
+
+	entry, err := s.Reindex(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Packages, nil
+}
+
+// scanPackages scans a repository checkout for packages of all types.
+func (s *Store) scanPackages(localPath string) []BrowseItem {
+	var items []BrowseItem
+
+	skillItems, _ := s.scanSkills(localPath)
+	items = append(items, skillItems...)
+	cmdItems, _ := s.scanCommands(localPath)
+	items = append(items, cmdItems...)
+	agentItems, _ := s.scanAgents(localPath)
+	items = append(items, agentItems...)
+	hookItems, _ := s.scanHooks(localPath)
+	items = append(items, hookItems...)
+
+	readFile := func(path string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(localPath, filepath.FromSlash(path)))
+	}
+	for i := range items {
+		items[i].Requires, _ = ReadRequires(items[i].Type, items[i].Path, readFile)
+		items[i].Description, items[i].Tags = readMetadata(items[i].Type, items[i].Path, readFile)
+	}
+
+	return items
+}
+
+// ScanDir returns the packages in a repository checkout or plain directory
+// laid out like one, without registering it.
+func ScanDir(localPath string) []BrowseItem {
+	return (&Store{}).scanPackages(localPath)
+}
+
+// metadataFrontmatter is the part of markdown frontmatter shown when browsing.
+type metadataFrontmatter struct {
+	Description string     `yaml:"description"`
+	Tags        StringList `yaml:"tags"`
+}
+
+// readMetadata returns the description and tags declared in the frontmatter
+// of a package's markdown file. Hooks have no markdown file and yield none.
+func readMetadata(pkgType PackageType, pkgPath string, readFile func(string) ([]byte, error)) (string, []string) {
+	var docs []string
+	switch pkgType {
+	case TypeSkill:
+		dir := strings.TrimSuffix(pkgPath, "/")
+		docs = []string{dir + "/SKILL.md", dir + "/skill.md"}
+	case TypeCommand, TypeAgent:
+		docs = []string{pkgPath}
+	}
+
+	for _, doc := range docs {
+		content, err := readFile(doc)
+		if err != nil {
+			continue
+		}
+
+		frontmatter, found := extractFrontmatter(string(content))
+		if !found || frontmatter == "" {
+			return "", nil
+		}
+
+		var fm metadataFrontmatter
+		if err := yaml.Unmarshal([]byte(frontmatter), &fm); err != nil {
+			// Fall back to a line-based read of the description
+			return simpleDescription(frontmatter), nil
+		}
+		return strings.TrimSpace(fm.Description), fm.Tags
+	}
+
+	return "", nil
+}
+
+// simpleDescription reads the description key from frontmatter that is not valid YAML.
+func simpleDescription(frontmatter string) string {
+	for _, line := range strings.Split(frontmatter, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "description" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadMetadata(t *testing.T) {
+	files := map[string]string{
+		"skills/web/SKILL.md": "---\ndescription: Fetch web pages\ntags: [web, http]\n---\n# Web",
+		"commands/commit.md":  "---\ndescription: Write commits\ntags: git, vcs\n---\n",
+		"agents/broken.md":    "---\ndescription: Review: code\n  bad: [\n---\n",
+		"agents/plain.md":     "# Plain",
+	}
+	readFile := func(path string) ([]byte, error) {
+		if content, ok := files[path]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	tests := []struct {
+		pkgType PackageType
+		path    string
+		desc    string
+		tags    []string
+	}{
+		{TypeSkill, "skills/web", "Fetch web pages", []string{"web", "http"}},
+		{TypeCommand, "commands/commit.md", "Write commits", []string{"git", "vcs"}},
+		{TypeAgent, "agents/broken.md", "Review: code", nil},
+		{TypeAgent, "agents/plain.md", "", nil},
+		{TypeHook, "hooks/lint.sh", "", nil},
+	}
+
+	for _, tt := range tests {
+		desc, tags := readMetadata(tt.pkgType, tt.path, readFile)
+		if desc != tt.desc || len(tags) != len(tt.tags) {
+			t.Errorf("readMetadata(%s) = %q, %v; want %q, %v", tt.path, desc, tags, tt.desc, tt.tags)
+			continue
+		}
+		for i := range tags {
+			if tags[i] != tt.tags[i] {
+				t.Errorf("readMetadata(%s) tags = %v, want %v", tt.path, tags, tt.tags)
+			}
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "acme", "pkgs")
+	createFile(t, filepath.Join(upstream, "skills", "demo", "SKILL.md"), "---\ndescription: Demo skill\ntags: [sample]\n---\n")
+	runGit(t, upstream, "init", "--quiet", "-b", "main")
+	runGit(t, upstream, "add", "-A")
+	runGit(t, upstream, "commit", "--quiet", "-m", "initial")
+
+	store := NewStore(filepath.Join(root, "base"))
+	config, err := store.Add(upstream, "acme")
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	entry, err := store.Index(config.Namespace)
+	if err != nil || entry == nil {
+		t.Fatalf("expected index entry after Add, got %v, %v", entry, err)
+	}
+	if entry.SHA == "" || entry.FetchedAt.IsZero() || len(entry.Packages) != 1 {
+		t.Fatalf("unexpected index entry: %+v", entry)
+	}
+	if pkg := entry.Packages[0]; pkg.Description != "Demo skill" || len(pkg.Tags) != 1 {
+		t.Errorf("unexpected indexed package: %+v", pkg)
+	}
+
+	// Search matches descriptions and tags
+	for _, query := range []string{"demo", "SKILL", "sample"} {
+		results, err := store.Search(query)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results["acme"]) != 1 {
+			t.Errorf("Search(%q) = %v, want one match", query, results)
+		}
+	}
+
+	// Update picks up new packages and moves the fetch time
+	createFile(t, filepath.Join(upstream, "commands", "hello.md"), "# Hello")
+	runGit(t, upstream, "add", "-A")
+	runGit(t, upstream, "commit", "--quiet", "-m", "add command")
+	fetchedAt := entry.FetchedAt
+	time.Sleep(10 * time.Millisecond)
+
+	if err := store.Update("acme"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	entry, err = store.Index("acme")
+	if err != nil || entry == nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if len(entry.Packages) != 2 || !entry.FetchedAt.After(fetchedAt) {
+		t.Errorf("index not refreshed: %+v", entry)
+	}
+
+	items, err := store.Browse("acme", TypeCommand)
+	if err != nil || len(items) != 1 || items[0].Name != "hello" {
+		t.Errorf("Browse = %+v, %v", items, err)
+	}
+
+	if err := store.Remove("acme"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if entry, _ := store.Index("acme"); entry != nil {
+		t.Errorf("index entry kept after Remove: %+v", entry)
+	}
+}
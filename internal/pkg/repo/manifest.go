@@ -0,0 +1,209 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestName is the optional sidecar manifest shipped with a package.
+// Skills keep it inside the skill directory; commands, agents and hooks
+// place it next to the package file as <name>.jd-package.yaml.
+const ManifestName = "jd-package.yaml"
+
+// PackageManifest is the content of a package's sidecar manifest.
+type PackageManifest struct {
+	Requires StringList    `yaml:"requires,omitempty"`
+	Hooks    []HookBinding `yaml:"hooks,omitempty"` // hook packages only
+}
+
+// HookBinding describes how an installed hook script is registered in
+// settings.json. Command is a template where {{script}} is replaced by the
+// installed script path; an empty command runs the script directly. A
+// command must include {{script}}, which is how uninstall finds the rule.
+//
+//	hooks:
+//	  - event: PreToolUse
+//	    matcher: Bash
+//	    command: "{{script}} --strict"
+type HookBinding struct {
+	Event   string `yaml:"event"`
+	Matcher string `yaml:"matcher,omitempty"`
+	Command string `yaml:"command,omitempty"`
+}
+
+// StringList is a list of strings that may be written in YAML as a sequence
+// or as a comma-separated string. It is used for requires and tags.
+//
+// Each requires entry is either a path in the same repository
+// (skills/web-fetch, agents/reviewer.md) or a full spec
+// (namespace:path[@version]).
+type StringList []string
+
+// UnmarshalYAML accepts a sequence of strings or a comma-separated string.
+func (r *StringList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*r = splitList(value.Value)
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		var out StringList
+		for _, item := range list {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		*r = out
+		return nil
+	default:
+		return fmt.Errorf("expected a list or a string")
+	}
+}
+
+// splitList splits a comma-separated list value.
+func splitList(s string) StringList {
+	var out StringList
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// IsManifestFile reports whether name is a sidecar manifest file name.
+func IsManifestFile(name string) bool {
+	return name == ManifestName || strings.HasSuffix(name, "."+ManifestName)
+}
+
+// ManifestPath returns the sidecar manifest path for a package path.
+// Example: skills/web-fetch -> skills/web-fetch/jd-package.yaml,
+// commands/commit.md -> commands/commit.jd-package.yaml.
+func ManifestPath(pkgType PackageType, pkgPath string) string {
+	pkgPath = strings.TrimSuffix(pkgPath, "/")
+	if pkgType == TypeSkill {
+		return pkgPath + "/" + ManifestName
+	}
+	return strings.TrimSuffix(pkgPath, path.Ext(pkgPath)) + "." + ManifestName
+}
+
+// ParseManifest parses a sidecar manifest.
+func ParseManifest(data []byte) (*PackageManifest, error) {
+	var m PackageManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestName, err)
+	}
+	return &m, nil
+}
+
+// ReadManifest reads the sidecar manifest of a package using readFile,
+// which must return an error matching os.ErrNotExist for missing files.
+// Returns nil if the package has no manifest.
+func ReadManifest(pkgType PackageType, pkgPath string, readFile func(string) ([]byte, error)) (*PackageManifest, error) {
+	data, err := readFile(ManifestPath(pkgType, pkgPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseManifest(data)
+}
+
+// requiresFrontmatter is the part of markdown frontmatter read for dependencies.
+type requiresFrontmatter struct {
+	Requires StringList `yaml:"requires"`
+}
+
+// FrontmatterRequires returns the requires list from markdown frontmatter.
+// Content without frontmatter or without requires yields nil.
+func FrontmatterRequires(content []byte) ([]string, error) {
+	frontmatter, found := extractFrontmatter(string(content))
+	if !found || frontmatter == "" {
+		return nil, nil
+	}
+
+	var fm requiresFrontmatter
+	if err := yaml.Unmarshal([]byte(frontmatter), &fm); err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	return fm.Requires, nil
+}
+
+// extractFrontmatter extracts YAML frontmatter from markdown content
+func extractFrontmatter(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", false
+	}
+
+	var frontmatterLines []string
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(frontmatterLines, "\n"), true
+		}
+		frontmatterLines = append(frontmatterLines, lines[i])
+	}
+
+	return "", false
+}
+
+// ReadRequires returns the dependencies declared by a package, merging its
+// sidecar manifest with the requires frontmatter of its markdown file.
+// readFile reads a repository-relative path and must return an error
+// matching os.ErrNotExist for missing files.
+func ReadRequires(pkgType PackageType, pkgPath string, readFile func(string) ([]byte, error)) ([]string, error) {
+	var requires []string
+	seen := make(map[string]bool)
+	add := func(list []string) {
+		for _, r := range list {
+			if !seen[r] {
+				seen[r] = true
+				requires = append(requires, r)
+			}
+		}
+	}
+
+	manifest, err := ReadManifest(pkgType, pkgPath, readFile)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		add(manifest.Requires)
+	}
+
+	var docs []string
+	switch pkgType {
+	case TypeSkill:
+		dir := strings.TrimSuffix(pkgPath, "/")
+		docs = []string{dir + "/SKILL.md", dir + "/skill.md"}
+	case TypeCommand, TypeAgent:
+		docs = []string{pkgPath}
+	}
+
+	for _, doc := range docs {
+		content, err := readFile(doc)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		list, err := FrontmatterRequires(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", doc, err)
+		}
+		add(list)
+		break
+	}
+
+	return requires, nil
+}
@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/itda-skills/jindo/internal/pkg/git"
+)
+
+const (
+	// DefaultFetchWorkers is the number of repositories fetched at once.
+	DefaultFetchWorkers = 4
+	// DefaultFetchTimeout bounds the network work done for one repository.
+	DefaultFetchTimeout = 2 * time.Minute
+)
+
+// FetchOptions controls parallel repository fetches. Zero values use the defaults.
+type FetchOptions struct {
+	Workers int           // maximum concurrent repositories
+	Timeout time.Duration // per-repository timeout
+}
+
+// withDefaults fills unset options with their defaults.
+func (o FetchOptions) withDefaults() FetchOptions {
+	if o.Workers <= 0 {
+		o.Workers = DefaultFetchWorkers
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultFetchTimeout
+	}
+	return o
+}
+
+// RepoResult is the outcome of the work done for one repository.
+type RepoResult struct {
+	Namespace string
+	Err       error
+	Duration  time.Duration
+}
+
+// FailedResults returns the results that carry an error.
+func FailedResults(results []RepoResult) []RepoResult {
+	var failed []RepoResult
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// ForEachRepo runs fn for each namespace on a bounded pool of workers.
+// Each call gets a context that is cancelled after the per-repository
+// timeout or when ctx is done; namespaces not started before ctx is done
+// fail with its error. Results are returned in the order of namespaces.
+func ForEachRepo(ctx context.Context, namespaces []string, opts FetchOptions, fn func(ctx context.Context, namespace string) error) []RepoResult {
+	opts = opts.withDefaults()
+	results := make([]RepoResult, len(namespaces))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers && w < len(namespaces); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runRepo(ctx, namespaces[i], opts.Timeout, fn)
+			}
+		}()
+	}
+
+	for i, ns := range namespaces {
+		if err := ctx.Err(); err != nil {
+			results[i] = RepoResult{Namespace: ns, Err: err}
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i] = RepoResult{Namespace: ns, Err: ctx.Err()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// runRepo runs fn for one namespace under its own timeout.
+func runRepo(ctx context.Context, namespace string, timeout time.Duration, fn func(ctx context.Context, namespace string) error) RepoResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx, namespace)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return RepoResult{Namespace: namespace, Err: err, Duration: time.Since(start)}
+}
+
+// UpdateAll pulls all registered repositories in parallel and rebuilds
+// their index entries. Per-repository failures are reported in the results.
+func (s *Store) UpdateAll(ctx context.Context, opts FetchOptions) ([]RepoResult, error) {
+	repos, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, len(repos))
+	for i, r := range repos {
+		namespaces[i] = r.Namespace
+	}
+	return s.UpdateRepos(ctx, namespaces, opts)
+}
+
+// UpdateRepos pulls the given repositories in parallel and rebuilds their
+// index entries. Per-repository failures are reported in the results.
+func (s *Store) UpdateRepos(ctx context.Context, namespaces []string, opts FetchOptions) ([]RepoResult, error) {
+	if err := git.EnsureInstalled(); err != nil {
+		return nil, err
+	}
+
+	started := time.Now().UTC()
+	results := ForEachRepo(ctx, namespaces, opts, func(ctx context.Context, namespace string) error {
+		if _, err := s.Get(namespace); err != nil {
+			return err
+		}
+		localPath, err := s.RepoLocalPath(namespace)
+		if err != nil {
+			return err
+		}
+		return git.PullQuietContext(ctx, localPath)
+	})
+
+	// Index and repos.json writes are not safe to run concurrently, so
+	// they happen once all pulls are done.
+	for i, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if _, err := s.Reindex(r.Namespace); err != nil {
+			results[i].Err = fmt.Errorf("index: %w", err)
+			continue
+		}
+		_ = s.MarkFetched(r.Namespace, started)
+		_ = s.refreshDescription(r.Namespace)
+	}
+
+	return results, nil
+}
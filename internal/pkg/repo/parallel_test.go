@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachRepo(t *testing.T) {
+	namespaces := []string{"a", "b", "c", "d", "e", "f"}
+	var running, peak int32
+
+	results := ForEachRepo(context.Background(), namespaces, FetchOptions{Workers: 2}, func(ctx context.Context, ns string) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if ns == "c" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if peak > 2 {
+		t.Errorf("ran %d repositories at once, want at most 2", peak)
+	}
+	if len(results) != len(namespaces) {
+		t.Fatalf("got %d results, want %d", len(results), len(namespaces))
+	}
+	for i, r := range results {
+		if r.Namespace != namespaces[i] {
+			t.Errorf("results[%d] = %s, want %s", i, r.Namespace, namespaces[i])
+		}
+	}
+	if failed := FailedResults(results); len(failed) != 1 || failed[0].Namespace != "c" {
+		t.Errorf("FailedResults = %+v, want only c", failed)
+	}
+}
+
+func TestForEachRepoTimeout(t *testing.T) {
+	results := ForEachRepo(context.Background(), []string{"slow"}, FetchOptions{Timeout: 10 * time.Millisecond}, func(ctx context.Context, ns string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", results[0].Err)
+	}
+}
+
+func TestForEachRepoCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	results := ForEachRepo(ctx, []string{"a", "b"}, FetchOptions{}, func(ctx context.Context, ns string) error {
+		called = true
+		return nil
+	})
+	if called {
+		t.Error("fn called after cancellation")
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: err = %v, want canceled", r.Namespace, r.Err)
+		}
+	}
+}
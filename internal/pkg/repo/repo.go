@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -141,62 +140,65 @@ func ParseURL(url string) (owner, repo string, err error) {
 	return matches[1], matches[2], nil
 }
 
+// namespaceInvalidChars matches characters not allowed in a namespace.
+var namespaceInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
 // GenerateNamespace generates a namespace from owner and repo.
-// Format: first 4 chars of owner + "-" + first 4 chars of repo
+// Format: first 4 chars of owner + "-" + first 4 chars of repo.
+// Owners with slashes (GitLab subgroups) use their last segment, and
+// characters other than a-z, 0-9 and "-" are replaced with "-".
 func GenerateNamespace(owner, repo string) string {
-	ownerPart := owner
-	if len(ownerPart) > 4 {
-		ownerPart = ownerPart[:4]
+	if idx := strings.LastIndex(owner, "/"); idx != -1 {
+		owner = owner[idx+1:]
 	}
 
-	repoPart := repo
-	if len(repoPart) > 4 {
-		repoPart = repoPart[:4]
+	ownerPart := namespacePart(owner)
+	repoPart := namespacePart(repo)
+
+	if ownerPart == "" {
+		return repoPart
 	}
+	return ownerPart + "-" + repoPart
+}
 
-	return strings.ToLower(ownerPart + "-" + repoPart)
+// namespacePart returns the sanitized first 4 characters of s.
+func namespacePart(s string) string {
+	s = namespaceInvalidChars.ReplaceAllString(strings.ToLower(s), "-")
+	if len(s) > 4 {
+		s = s[:4]
+	}
+	return strings.Trim(s, "-")
 }
 
 // fetchGitHubDescription fetches the repository description from GitHub API.
 func fetchGitHubDescription(owner, repo string) string {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return ""
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return ""
-	}
-
-	var result struct {
-		Description string `json:"description"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return ""
-	}
-
-	return result.Description
+	return fetchJSONDescription(fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo))
 }
 
 // Add adds a new repository by cloning it locally.
+// The URL may be any form accepted by ParseSource.
 func (s *Store) Add(url, namespace string) (*RepoConfig, error) {
+	return s.AddWithOptions(url, namespace, CloneOptions{})
+}
+
+// AddWithOptions adds a new repository, cloning as much of it as opts allow.
+func (s *Store) AddWithOptions(url, namespace string, opts CloneOptions) (*RepoConfig, error) {
 	// Ensure git is installed
 	if err := git.EnsureInstalled(); err != nil {
 		return nil, err
 	}
 
-	owner, repo, err := ParseURL(url)
+	src, err := ParseSource(url)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate namespace if not provided
 	if namespace == "" {
-		namespace = GenerateNamespace(owner, repo)
+		namespace = GenerateNamespace(src.Owner, src.Repo)
+		if namespace == "" {
+			return nil, fmt.Errorf("%w: cannot derive a namespace from %s", ErrInvalidURL, url)
+		}
 	}
 
 	// Load existing repos
@@ -223,10 +225,9 @@ func (s *Store) Add(url, namespace string) (*RepoConfig, error) {
 
 	// Clone repository
 	localPath := filepath.Join(reposDir, namespace)
-	gitURL := fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
 
-	fmt.Printf("Cloning %s...\n", gitURL)
-	if err := git.Clone(gitURL, localPath); err != nil {
+	fmt.Printf("Cloning %s...\n", src.CloneURL)
+	if err := git.CloneWithOptions(src.CloneURL, localPath, opts.gitOptions()); err != nil {
 		return nil, fmt.Errorf("clone repository: %w", err)
 	}
 
@@ -236,19 +237,22 @@ func (s *Store) Add(url, namespace string) (*RepoConfig, error) {
 		defaultBranch = "main" // fallback
 	}
 
-	// Fetch description from GitHub API
-	description := fetchGitHubDescription(owner, repo)
-
 	config := RepoConfig{
 		Namespace:     namespace,
-		URL:           fmt.Sprintf("https://github.com/%s/%s", owner, repo),
-		Owner:         owner,
-		Repo:          repo,
+		URL:           src.WebURL,
+		CloneURL:      src.CloneURL,
+		Host:          src.Host,
+		Owner:         src.Owner,
+		Repo:          src.Repo,
 		DefaultBranch: defaultBranch,
-		Description:   description,
+		FullHistory:   opts.FullHistory,
+		SparsePaths:   opts.SparsePaths,
 		AddedAt:       time.Now().UTC(),
 	}
 
+	// Fetch description from the hosting service
+	config.Description = fetchDescription(config, localPath)
+
 	repos.Repos = append(repos.Repos, config)
 
 	if err := s.save(repos); err != nil {
@@ -257,6 +261,9 @@ func (s *Store) Add(url, namespace string) (*RepoConfig, error) {
 		return nil, err
 	}
 
+	// The index is a cache; Browse rebuilds it if this fails
+	_ = s.refreshIndex(namespace)
+
 	return &config, nil
 }
 
@@ -313,7 +320,124 @@ func (s *Store) Remove(namespace string) error {
 	}
 
 	repos.Repos = newRepos
-	return s.save(repos)
+	if err := s.save(repos); err != nil {
+		return err
+	}
+	return s.removeIndex(namespace)
+}
+
+// Relocate points a repository at a new URL. The new location is cloned
+// beside the current clone, which is replaced only once the clone has
+// succeeded, so a failed clone leaves the repository as it was. Clone
+// options and settings are kept; the description is fetched again.
+func (s *Store) Relocate(namespace, url string) (*RepoConfig, error) {
+	if err := git.EnsureInstalled(); err != nil {
+		return nil, err
+	}
+
+	src, err := ParseSource(url)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.Get(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	reposDir, err := s.reposDir()
+	if err != nil {
+		return nil, err
+	}
+	localPath := filepath.Join(reposDir, namespace)
+	tmpDir, err := os.MkdirTemp(reposDir, "."+namespace+"-")
+	if err != nil {
+		return nil, fmt.Errorf("create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	newPath := filepath.Join(tmpDir, "clone")
+	opts := CloneOptions{FullHistory: existing.FullHistory, SparsePaths: existing.SparsePaths}
+	fmt.Printf("Cloning %s...\n", src.CloneURL)
+	if err := git.CloneWithOptions(src.CloneURL, newPath, opts.gitOptions()); err != nil {
+		return nil, fmt.Errorf("clone repository: %w", err)
+	}
+
+	defaultBranch, err := git.GetDefaultBranch(newPath)
+	if err != nil {
+		defaultBranch = "main" // fallback
+	}
+
+	config := *existing
+	config.URL = src.WebURL
+	config.CloneURL = src.CloneURL
+	config.Host = src.Host
+	config.Owner = src.Owner
+	config.Repo = src.Repo
+	config.DefaultBranch = defaultBranch
+	config.Description = fetchDescription(config, newPath)
+
+	// Swap the clones, keeping the old one until the new config is saved
+	oldPath := filepath.Join(tmpDir, "old")
+	if err := os.Rename(localPath, oldPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("replace clone: %w", err)
+	}
+	restore := func() {
+		_ = os.RemoveAll(localPath)
+		_ = os.Rename(oldPath, localPath)
+	}
+	if err := os.Rename(newPath, localPath); err != nil {
+		restore()
+		return nil, fmt.Errorf("replace clone: %w", err)
+	}
+	if err := s.updateRepo(namespace, func(r *RepoConfig) { *r = config }); err != nil {
+		restore()
+		return nil, err
+	}
+
+	// The index is a cache; Browse rebuilds it if this fails
+	_ = s.refreshIndex(namespace)
+
+	return &config, nil
+}
+
+// SetUnprefixed sets whether packages from a repository are installed
+// under their own names instead of namespace--name.
+func (s *Store) SetUnprefixed(namespace string, unprefixed bool) error {
+	return s.updateRepo(namespace, func(r *RepoConfig) {
+		r.Unprefixed = unprefixed
+	})
+}
+
+// SetRegistry marks a repository as managed by a subscribed registry, or
+// as registered by hand when registry is empty.
+func (s *Store) SetRegistry(namespace, registry string) error {
+	return s.updateRepo(namespace, func(r *RepoConfig) {
+		r.Registry = registry
+	})
+}
+
+// SetDescription sets the description of a repository.
+func (s *Store) SetDescription(namespace, description string) error {
+	return s.updateRepo(namespace, func(r *RepoConfig) {
+		r.Description = description
+	})
+}
+
+// updateRepo applies update to a registered repository and saves it.
+func (s *Store) updateRepo(namespace string, update func(*RepoConfig)) error {
+	repos, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for i := range repos.Repos {
+		if repos.Repos[i].Namespace == namespace {
+			update(&repos.Repos[i])
+			return s.save(repos)
+		}
+	}
+
+	return ErrRepoNotFound
 }
 
 // NamespaceExists checks if a namespace already exists.
@@ -342,7 +466,11 @@ func (s *Store) refreshDescription(namespace string) error {
 	for i, r := range repos.Repos {
 		if r.Namespace == namespace {
 			if r.Description == "" {
-				desc := fetchGitHubDescription(r.Owner, r.Repo)
+				localPath, err := s.RepoLocalPath(namespace)
+				if err != nil {
+					return err
+				}
+				desc := fetchDescription(r, localPath)
 				if desc != "" {
 					repos.Repos[i].Description = desc
 					return s.save(repos)
@@ -374,38 +502,16 @@ func (s *Store) Update(namespace string) error {
 		return err
 	}
 
-	// Update description if missing
-	return s.refreshDescription(namespace)
-}
-
-// UpdateAll pulls the latest changes for all repositories.
-func (s *Store) UpdateAll() error {
-	if err := git.EnsureInstalled(); err != nil {
+	if err := s.refreshIndex(namespace); err != nil {
 		return err
 	}
 
-	repos, err := s.List()
-	if err != nil {
-		return err
-	}
-
-	for _, r := range repos {
-		localPath, err := s.RepoLocalPath(r.Namespace)
-		if err != nil {
-			continue
-		}
-		fmt.Printf("Updating %s...\n", r.Namespace)
-		if err := git.PullQuiet(localPath); err != nil {
-			fmt.Printf("  Warning: failed to update %s: %v\n", r.Namespace, err)
-		}
-		// Refresh description if missing
-		_ = s.refreshDescription(r.Namespace)
-	}
-
-	return nil
+	// Update description if missing
+	return s.refreshDescription(namespace)
 }
 
-// Browse browses a repository for packages from local clone.
+// Browse lists the packages of a repository from the local index, which is
+// rebuilt from the local clone when its checked-out commit changes.
 func (s *Store) Browse(namespace string, typeFilter PackageType) ([]BrowseItem, error) {
 	localPath, err := s.RepoLocalPath(namespace)
 	if err != nil {
@@ -416,30 +522,16 @@ func (s *Store) Browse(namespace string, typeFilter PackageType) ([]BrowseItem,
 		return nil, ErrRepoNotFound
 	}
 
-	var items []BrowseItem
-
-	// Scan skills directory
-	if typeFilter == "" || typeFilter == TypeSkill {
-		skillItems, _ := s.scanSkills(localPath)
-		items = append(items, skillItems...)
-	}
-
-	// Scan commands directory
-	if typeFilter == "" || typeFilter == TypeCommand {
-		cmdItems, _ := s.scanCommands(localPath)
-		items = append(items, cmdItems...)
-	}
-
-	// Scan agents directory
-	if typeFilter == "" || typeFilter == TypeAgent {
-		agentItems, _ := s.scanAgents(localPath)
-		items = append(items, agentItems...)
+	packages, err := s.packages(namespace, localPath)
+	if err != nil {
+		return nil, err
 	}
 
-	// Scan hooks directory
-	if typeFilter == "" || typeFilter == TypeHook {
-		hookItems, _ := s.scanHooks(localPath)
-		items = append(items, hookItems...)
+	var items []BrowseItem
+	for _, item := range packages {
+		if typeFilter == "" || item.Type == typeFilter {
+			items = append(items, item)
+		}
 	}
 
 	return items, nil
@@ -658,7 +750,7 @@ func (s *Store) scanHooks(repoPath string) ([]BrowseItem, error) {
 
 		for _, entry := range entries {
 			// Hooks can be shell scripts or other executable files
-			if entry.IsDir() {
+			if entry.IsDir() || IsManifestFile(entry.Name()) {
 				continue
 			}
 			name := entry.Name()
@@ -673,7 +765,8 @@ func (s *Store) scanHooks(repoPath string) ([]BrowseItem, error) {
 	return items, nil
 }
 
-// Search searches for packages across all registered repositories.
+// Search searches for packages across all registered repositories by name,
+// description and tags. It reads the local index and never touches the network.
 func (s *Store) Search(query string) (map[string][]BrowseItem, error) {
 	repos, err := s.List()
 	if err != nil {
@@ -691,7 +784,7 @@ func (s *Store) Search(query string) (map[string][]BrowseItem, error) {
 
 		var matches []BrowseItem
 		for _, item := range items {
-			if strings.Contains(strings.ToLower(item.Name), query) {
+			if matchesQuery(item, query) {
 				matches = append(matches, item)
 			}
 		}
@@ -703,3 +796,18 @@ func (s *Store) Search(query string) (map[string][]BrowseItem, error) {
 
 	return results, nil
 }
+
+// matchesQuery reports whether a package name, description or tag contains
+// the lowercased query.
+func matchesQuery(item BrowseItem, query string) bool {
+	if strings.Contains(strings.ToLower(item.Name), query) ||
+		strings.Contains(strings.ToLower(item.Description), query) {
+		return true
+	}
+	for _, tag := range item.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
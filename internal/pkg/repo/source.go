@@ -0,0 +1,316 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Source kinds.
+const (
+	SourceGitHub = "github" // gh:owner/repo or https://github.com/owner/repo
+	SourceHTTPS  = "https"  // https:// or http:// on any host
+	SourceSSH    = "ssh"    // ssh:// or scp-like git@host:path
+	SourceFile   = "file"   // file:// or a local directory
+)
+
+// scpLikeRegex matches scp-like git URLs such as git@host:owner/repo.git.
+var scpLikeRegex = regexp.MustCompile(`^(?:([^@/]+)@)?([^:/]+):(.+)$`)
+
+// windowsDriveRegex matches Windows absolute paths such as C:\repos or C:/repos.
+var windowsDriveRegex = regexp.MustCompile(`^[a-zA-Z]:[\\/]`)
+
+// Source is a parsed repository location.
+type Source struct {
+	Kind     string // SourceGitHub, SourceHTTPS, SourceSSH or SourceFile
+	CloneURL string // URL passed to git clone
+	WebURL   string // URL recorded in RepoConfig.URL
+	Host     string // Host name; empty for local sources
+	Owner    string // Path before the repository name (may contain slashes)
+	Repo     string // Repository name without .git suffix
+}
+
+// ParseSource parses a repository location. Accepted forms:
+//
+//	gh:owner/repo
+//	https://host/owner/repo(.git)
+//	ssh://[user@]host[:port]/owner/repo(.git)
+//	[user@]host:owner/repo(.git)
+//	file:///path/to/repo
+//	/path/to/repo, ./repo, ~/repo (local directories)
+func ParseSource(raw string) (*Source, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, ErrInvalidURL
+	}
+
+	if strings.HasPrefix(raw, "gh:") {
+		owner, repo, err := ParseURL(raw)
+		if err != nil {
+			return nil, err
+		}
+		return githubSource(owner, repo), nil
+	}
+
+	if strings.Contains(raw, "://") {
+		return parseURLSource(raw)
+	}
+
+	if isLocalPath(raw) {
+		return localSource(raw)
+	}
+
+	if m := scpLikeRegex.FindStringSubmatch(raw); m != nil {
+		owner, repo := splitRepoPath(m[3])
+		if repo == "" {
+			return nil, ErrInvalidURL
+		}
+		return &Source{
+			Kind:     SourceSSH,
+			CloneURL: raw,
+			WebURL:   raw,
+			Host:     m[2],
+			Owner:    owner,
+			Repo:     repo,
+		}, nil
+	}
+
+	return nil, ErrInvalidURL
+}
+
+// githubSource returns the source for a GitHub repository.
+func githubSource(owner, repo string) *Source {
+	return &Source{
+		Kind:     SourceGitHub,
+		CloneURL: fmt.Sprintf("https://github.com/%s/%s.git", owner, repo),
+		WebURL:   fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+		Host:     "github.com",
+		Owner:    owner,
+		Repo:     repo,
+	}
+}
+
+// parseURLSource parses a URL with a scheme.
+func parseURLSource(raw string) (*Source, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+
+	switch u.Scheme {
+	case "file":
+		return localSource(u.Path)
+	case "https", "http", "ssh", "git":
+	default:
+		return nil, ErrInvalidURL
+	}
+
+	owner, repo := splitRepoPath(u.Path)
+	if u.Host == "" || repo == "" {
+		return nil, ErrInvalidURL
+	}
+
+	if u.Hostname() == "github.com" && u.Scheme == "https" && !strings.Contains(owner, "/") {
+		src := githubSource(owner, repo)
+		if u.User != nil {
+			src.CloneURL = raw
+		}
+		return src, nil
+	}
+
+	kind := SourceHTTPS
+	if u.Scheme == "ssh" || u.Scheme == "git" {
+		kind = SourceSSH
+	}
+
+	return &Source{
+		Kind:     kind,
+		CloneURL: raw,
+		WebURL:   strings.TrimSuffix(webURL(u), ".git"),
+		Host:     u.Hostname(),
+		Owner:    owner,
+		Repo:     repo,
+	}, nil
+}
+
+// webURL returns u without credentials, as it is recorded and shown. SSH
+// URLs keep their user name, which names the account rather than
+// authenticating it; HTTP URLs lose their user info, which is usually a
+// token. Credentials stay in the clone URL only.
+func webURL(u *url.URL) string {
+	clean := *u
+	switch {
+	case u.User == nil:
+	case u.Scheme == "ssh" || u.Scheme == "git":
+		clean.User = url.User(u.User.Username())
+	default:
+		clean.User = nil
+	}
+	return clean.String()
+}
+
+// localSource returns the source for a repository on the local filesystem.
+func localSource(path string) (*Source, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidURL, path)
+	}
+
+	// file:// URLs make git honor --depth for local clones
+	fileURL := "file://" + filepath.ToSlash(abs)
+	if !strings.HasPrefix(filepath.ToSlash(abs), "/") {
+		fileURL = "file:///" + filepath.ToSlash(abs)
+	}
+
+	owner, repo := splitRepoPath(filepath.ToSlash(abs))
+	return &Source{
+		Kind:     SourceFile,
+		CloneURL: fileURL,
+		WebURL:   fileURL,
+		Owner:    filepath.Base(owner),
+		Repo:     repo,
+	}, nil
+}
+
+// isLocalPath reports whether raw looks like a filesystem path rather than an scp-like URL.
+func isLocalPath(raw string) bool {
+	if strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, "./") || strings.HasPrefix(raw, "../") ||
+		strings.HasPrefix(raw, "~/") || raw == "." || raw == ".." || windowsDriveRegex.MatchString(raw) {
+		return true
+	}
+	// git treats "foo:bar" as scp-like only if no slash precedes the colon
+	colon := strings.Index(raw, ":")
+	if colon == -1 {
+		return true
+	}
+	slash := strings.Index(raw, "/")
+	return slash != -1 && slash < colon
+}
+
+// splitRepoPath splits "owner/sub/repo.git" into ("owner/sub", "repo").
+func splitRepoPath(path string) (owner, repo string) {
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	idx := strings.LastIndex(path, "/")
+	if idx == -1 {
+		return "", path
+	}
+	return path[:idx], path[idx+1:]
+}
+
+// descriptionClient is shared by the host-specific description fetchers.
+var descriptionClient = &http.Client{Timeout: 10 * time.Second}
+
+// gitLabHosts and giteaHosts are the public hosts whose APIs are asked for
+// descriptions. Other hosts are not probed, so registering a self-hosted
+// repository sends no requests to guessed API endpoints.
+var (
+	gitLabHosts = []string{"gitlab.com"}
+	giteaHosts  = []string{"codeberg.org", "gitea.com"}
+)
+
+// fetchDescription fetches a repository description from its host.
+// GitHub, GitLab.com, Codeberg and Gitea.com use their APIs, and local
+// repositories read the bare repository description file. Other hosts and
+// any failure yield an empty description.
+func fetchDescription(cfg RepoConfig, localPath string) string {
+	host := cfg.Host
+	if host == "" && strings.HasPrefix(cfg.URL, "https://github.com/") {
+		// Registered before hosts were recorded
+		host = "github.com"
+	}
+
+	switch {
+	case host == "github.com":
+		return fetchGitHubDescription(cfg.Owner, cfg.Repo)
+	case slices.Contains(gitLabHosts, host):
+		return fetchGitLabDescription(host, cfg.Owner, cfg.Repo)
+	case slices.Contains(giteaHosts, host):
+		return fetchGiteaDescription(host, cfg.Owner, cfg.Repo)
+	case host != "":
+		return ""
+	default:
+		return readLocalDescription(cfg.CloneURL, localPath)
+	}
+}
+
+// fetchJSONDescription fetches a JSON document and returns its "description" field.
+func fetchJSONDescription(apiURL string) string {
+	resp, err := descriptionClient.Get(apiURL)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	var result struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ""
+	}
+
+	return result.Description
+}
+
+// fetchGitLabDescription fetches the description from the GitLab projects API.
+func fetchGitLabDescription(host, owner, repo string) string {
+	project := url.PathEscape(owner + "/" + repo)
+	return fetchJSONDescription(fmt.Sprintf("https://%s/api/v4/projects/%s", host, project))
+}
+
+// fetchGiteaDescription fetches the description from the Gitea/Forgejo repos API.
+func fetchGiteaDescription(host, owner, repo string) string {
+	return fetchJSONDescription(fmt.Sprintf("https://%s/api/v1/repos/%s/%s", host, owner, repo))
+}
+
+// defaultGitDescription is the placeholder git writes into new repositories.
+const defaultGitDescription = "Unnamed repository; edit this file 'description' to name the repository."
+
+// readLocalDescription reads the description file of a local source repository,
+// falling back to the clone's own description file.
+func readLocalDescription(cloneURL, localPath string) string {
+	var candidates []string
+	if strings.HasPrefix(cloneURL, "file://") {
+		src := filepath.FromSlash(strings.TrimPrefix(cloneURL, "file://"))
+		candidates = append(candidates, filepath.Join(src, "description"), filepath.Join(src, ".git", "description"))
+	}
+	if localPath != "" {
+		candidates = append(candidates, filepath.Join(localPath, ".git", "description"))
+	}
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		desc := strings.TrimSpace(string(data))
+		if desc != "" && desc != defaultGitDescription {
+			return desc
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidKey is returned when a trusted key is neither an SSH public key
+// nor a GPG fingerprint or long key ID.
+var ErrInvalidKey = errors.New("invalid signing key")
+
+// gpgKeyRegex matches a GPG fingerprint (40 hex digits) or long key ID (16).
+var gpgKeyRegex = regexp.MustCompile(`^(?:[0-9A-F]{16}|[0-9A-F]{40})$`)
+
+// ParseTrustedKey normalizes a signing key: an SSH public key line
+// ("ssh-ed25519 AAAA... comment") or a GPG fingerprint or long key ID.
+func ParseTrustedKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if fields := strings.Fields(key); len(fields) >= 2 && isSSHKeyType(fields[0]) {
+		if _, err := base64.StdEncoding.DecodeString(fields[1]); err != nil {
+			return "", fmt.Errorf("%w: bad SSH key data", ErrInvalidKey)
+		}
+		return strings.Join(fields, " "), nil
+	}
+
+	gpg := strings.ToUpper(strings.ReplaceAll(strings.TrimPrefix(key, "0x"), " ", ""))
+	if gpgKeyRegex.MatchString(gpg) {
+		return gpg, nil
+	}
+	return "", fmt.Errorf("%w: %q (use an SSH public key or a GPG fingerprint)", ErrInvalidKey, key)
+}
+
+// isSSHKeyType reports whether t is an SSH public key algorithm name.
+func isSSHKeyType(t string) bool {
+	return strings.HasPrefix(t, "ssh-") || strings.HasPrefix(t, "ecdsa-") || strings.HasPrefix(t, "sk-")
+}
+
+// IsSSHKey reports whether a parsed trusted key is an SSH public key.
+func IsSSHKey(key string) bool {
+	fields := strings.Fields(key)
+	return len(fields) >= 2 && isSSHKeyType(fields[0])
+}
+
+// KeyFingerprint returns the fingerprint git reports for a trusted key:
+// SHA256:<base64> for SSH keys, the uppercase hex ID for GPG keys.
+func KeyFingerprint(key string) string {
+	if !IsSSHKey(key) {
+		return key
+	}
+	blob, err := base64.StdEncoding.DecodeString(strings.Fields(key)[1])
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// WriteAllowedSigners writes the SSH keys among keys to an ssh-keygen
+// allowed signers file at path, accepting them for any principal.
+func WriteAllowedSigners(path string, keys []string) error {
+	var b strings.Builder
+	for _, key := range keys {
+		if IsSSHKey(key) {
+			fields := strings.Fields(key)
+			fmt.Fprintf(&b, "* %s %s\n", fields[0], fields[1])
+		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// AddTrustedKey adds a signing key to a repository's trusted keys. Adding
+// a key that is already trusted is not an error.
+func (s *Store) AddTrustedKey(namespace, key string) error {
+	key, err := ParseTrustedKey(key)
+	if err != nil {
+		return err
+	}
+	return s.updateRepo(namespace, func(r *RepoConfig) {
+		for _, k := range r.TrustedKeys {
+			if KeyFingerprint(k) == KeyFingerprint(key) {
+				return
+			}
+		}
+		r.TrustedKeys = append(r.TrustedKeys, key)
+	})
+}
+
+// RemoveTrustedKey removes a signing key, given as the key or its
+// fingerprint, from a repository's trusted keys.
+func (s *Store) RemoveTrustedKey(namespace, key string) error {
+	fingerprint := strings.TrimSpace(key)
+	if parsed, err := ParseTrustedKey(key); err == nil {
+		fingerprint = KeyFingerprint(parsed)
+	}
+
+	found := false
+	err := s.updateRepo(namespace, func(r *RepoConfig) {
+		var kept []string
+		for _, k := range r.TrustedKeys {
+			if KeyFingerprint(k) == fingerprint {
+				found = true
+				continue
+			}
+			kept = append(kept, k)
+		}
+		r.TrustedKeys = kept
+	})
+	if err == nil && !found {
+		return fmt.Errorf("%w: %s is not trusted by %s", ErrInvalidKey, key, namespace)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTrustedKey(t *testing.T) {
+	// ssh-keygen -lf reports SHA256:iR2Qt/VXUX3/6elvrSpepF9NHV9Fu+MS/saI9GzgxWQ for this key
+	const sshKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIF7J7OQw2NITtwOcfeMj/D2r89yu/c3czM2W5PRk04I7  me "
+
+	tests := []struct {
+		key         string
+		want        string
+		fingerprint string
+		wantErr     bool
+	}{
+		{key: sshKey, want: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIF7J7OQw2NITtwOcfeMj/D2r89yu/c3czM2W5PRk04I7 me", fingerprint: "SHA256:iR2Qt/VXUX3/6elvrSpepF9NHV9Fu+MS/saI9GzgxWQ"},
+		{key: "0x3aa5c34371567bd2", want: "3AA5C34371567BD2", fingerprint: "3AA5C34371567BD2"},
+		{key: "3AA5 C343 7156 7BD2 3AA5 C343 7156 7BD2 3AA5 C343", want: "3AA5C34371567BD23AA5C34371567BD23AA5C343", fingerprint: "3AA5C34371567BD23AA5C34371567BD23AA5C343"},
+		{key: "ssh-ed25519 not-base64!", wantErr: true},
+		{key: "not a key", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTrustedKey(tt.key)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("ParseTrustedKey(%q) error = %v, want ErrInvalidKey", tt.key, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTrustedKey(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+			continue
+		}
+		if tt.fingerprint != "" && KeyFingerprint(got) != tt.fingerprint {
+			t.Errorf("KeyFingerprint(%q) = %q, want %q", got, KeyFingerprint(got), tt.fingerprint)
+		}
+	}
+}
@@ -6,10 +6,17 @@ import "time"
 type RepoConfig struct {
 	Namespace     string    `json:"namespace"`
 	URL           string    `json:"url"`
+	CloneURL      string    `json:"clone_url,omitempty"` // URL used for git clone; empty for GitHub repos added before it was recorded
+	Host          string    `json:"host,omitempty"`      // Hosting service; empty for local repositories
 	Owner         string    `json:"owner"`
 	Repo          string    `json:"repo"`
 	DefaultBranch string    `json:"default_branch"`
 	Description   string    `json:"description,omitempty"`
+	Unprefixed    bool      `json:"unprefixed,omitempty"`   // Install packages without the namespace prefix
+	TrustedKeys   []string  `json:"trusted_keys,omitempty"` // SSH public keys or GPG fingerprints allowed to sign commits
+	FullHistory   bool      `json:"full_history,omitempty"` // Cloned with full history instead of depth 1
+	SparsePaths   []string  `json:"sparse_paths,omitempty"` // Directories checked out by a sparse clone; empty for a full checkout
+	Registry      string    `json:"registry,omitempty"`     // Subscribed registry that manages this repository
 	AddedAt       time.Time `json:"added_at"`
 }
 
@@ -35,4 +42,20 @@ type BrowseItem struct {
 	Path        string      `json:"path"`
 	Type        PackageType `json:"type"`
 	Description string      `json:"description,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Requires    []string    `json:"requires,omitempty"` // Declared dependencies
+}
+
+// RepoIndex holds the cached package listing of a repository.
+type RepoIndex struct {
+	SHA       string       `json:"sha"`                  // Commit the packages were scanned at
+	IndexedAt time.Time    `json:"indexed_at"`           // When the packages were scanned
+	FetchedAt time.Time    `json:"fetched_at,omitempty"` // Last successful fetch from the remote
+	Packages  []BrowseItem `json:"packages"`
+}
+
+// IndexFile represents the index.json file structure.
+type IndexFile struct {
+	Version int                   `json:"version"`
+	Repos   map[string]*RepoIndex `json:"repos"` // Keyed by namespace
 }
@@ -0,0 +1,292 @@
+// Package scan analyses package files for risky behaviour before they are
+// installed: hook scripts that pipe downloads into a shell, delete broadly,
+// upload data or read secrets, and skills, commands and agents whose tool
+// grants allow unrestricted shell access.
+package scan
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity ranks a finding.
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// rank orders severities for sorting and comparison.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	}
+	return 0
+}
+
+// Finding is a risky pattern found in a package file.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	File     string   `json:"file"`           // path as given to the scanner
+	Line     int      `json:"line,omitempty"` // 1-based; 0 for frontmatter-wide findings
+	Message  string   `json:"message"`
+}
+
+// High returns the high-severity findings.
+func High(findings []Finding) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		if f.Severity == SeverityHigh {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Package scans the files of a package. files are the package's paths as
+// understood by readFile; pkgType selects the checks applied to markdown
+// definitions. Scripts are recognized by extension or shebang.
+func Package(pkgType repo.PackageType, files []string, readFile func(string) ([]byte, error)) ([]Finding, error) {
+	var findings []Finding
+	for _, file := range files {
+		if repo.IsManifestFile(path.Base(file)) {
+			continue
+		}
+		data, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case pkgType == repo.TypeHook || isScript(file, data):
+			findings = append(findings, Script(file, data)...)
+		case strings.EqualFold(path.Ext(file), ".md"):
+			findings = append(findings, Definition(pkgType, file, data)...)
+		}
+	}
+
+	Sort(findings)
+	return findings, nil
+}
+
+// Sort orders findings by severity, highest first, keeping the order of
+// findings of the same severity.
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity.rank() > findings[j].Severity.rank()
+	})
+}
+
+// Dir scans a package in a working tree: pkgPath is relative to root and
+// names a skill directory or a single file.
+func Dir(pkgType repo.PackageType, root, pkgPath string) ([]Finding, error) {
+	var files []string
+	err := filepath.Walk(filepath.Join(root, filepath.FromSlash(pkgPath)), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".history" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Package(pkgType, files, func(rel string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	})
+}
+
+// scriptExts are file extensions treated as scripts inside skills.
+var scriptExts = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".py": true, ".js": true, ".ts": true, ".rb": true, ".pl": true, ".ps1": true,
+}
+
+// isScript reports whether a file is a script by extension or shebang.
+func isScript(file string, data []byte) bool {
+	return scriptExts[strings.ToLower(path.Ext(file))] || strings.HasPrefix(string(data), "#!")
+}
+
+// scriptRule is a line pattern flagged in scripts.
+type scriptRule struct {
+	rule     string
+	severity Severity
+	pattern  *regexp.Regexp
+	message  string
+}
+
+var scriptRules = []scriptRule{
+	{"pipe-to-shell", SeverityHigh, regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`), "downloads and pipes into a shell"},
+	{"obfuscated-exec", SeverityHigh, regexp.MustCompile(`base64\s+(-d|--decode)[^|]*\|\s*(ba|z)?sh\b|\beval\s+"?\$\(\s*(echo|printf)[^)]*base64`), "executes base64-decoded code"},
+	{"destructive-rm", SeverityHigh, regexp.MustCompile(`\brm\s+(-[a-zA-Z]*r[a-zA-Z]*f|-[a-zA-Z]*f[a-zA-Z]*r|-r\s+-f|-f\s+-r)[a-zA-Z]*\s+(--\s+)?("?(/|~|\$HOME|\$\{HOME\})"?(/?\*?)?(\s|$|"))`), "recursively deletes the root or home directory"},
+	{"recursive-rm", SeverityMedium, regexp.MustCompile(`\brm\s+(-[a-zA-Z]*r[a-zA-Z]*f|-[a-zA-Z]*f[a-zA-Z]*r|-r\s+-f|-f\s+-r)`), "force-deletes recursively"},
+	{"network-upload", SeverityHigh, regexp.MustCompile(`\bcurl\b.*\s(-d|--data(-binary|-raw|-urlencode)?|-F|--form|-T|--upload-file)(\s|=|@)|\bwget\b.*--post-(data|file)|\b(nc|ncat|netcat)\s+\S+\s+\d+|/dev/(tcp|udp)/`), "sends data over the network"},
+	{"network", SeverityLow, regexp.MustCompile(`\b(curl|wget)\b|\brequests\.(get|post)\b|\burllib\b|\bfetch\(`), "accesses the network"},
+	{"secret-access", SeverityMedium, regexp.MustCompile(`~/\.ssh|\$HOME/\.ssh|\bid_(rsa|ed25519|ecdsa)\b|\.aws/credentials|\.netrc\b|\.git-credentials|\.npmrc\b|\$\{?[A-Z0-9_]*(SECRET|TOKEN|PASSWORD|API_KEY|ACCESS_KEY)[A-Z0-9_]*\}?|security\s+find-generic-password`), "references credentials or secrets"},
+}
+
+// implies maps a rule to the weaker rule it makes redundant on the same line.
+var implies = map[string]string{
+	"destructive-rm": "recursive-rm",
+	"network-upload": "network",
+}
+
+// Script scans a script line by line, reporting each rule once. Comment
+// lines are skipped. A script that both reads secrets and uses the network
+// is also flagged as exfiltration.
+func Script(file string, data []byte) []Finding {
+	var findings []Finding
+	reported := make(map[string]bool)
+	var secretLine, networkLine int
+
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		matched := make(map[string]bool)
+		for _, r := range scriptRules {
+			if r.pattern.MatchString(trimmed) {
+				matched[r.rule] = true
+			}
+		}
+		if matched["secret-access"] {
+			secretLine = i + 1
+		}
+		if matched["network"] || matched["network-upload"] {
+			networkLine = i + 1
+		}
+
+		for _, r := range scriptRules {
+			if !matched[r.rule] || reported[r.rule] {
+				continue
+			}
+			redundant := false
+			for stronger, weaker := range implies {
+				if weaker == r.rule && matched[stronger] {
+					redundant = true
+				}
+			}
+			if redundant {
+				continue
+			}
+			reported[r.rule] = true
+			findings = append(findings, Finding{Severity: r.severity, Rule: r.rule, File: file, Line: i + 1, Message: r.message})
+		}
+	}
+
+	if secretLine > 0 && networkLine > 0 {
+		findings = append(findings, Finding{Severity: SeverityHigh, Rule: "exfiltration", File: file, Line: networkLine,
+			Message: "reads secrets and uses the network"})
+	}
+	return findings
+}
+
+// definitionFrontmatter is the tool grant of a skill, command or agent.
+type definitionFrontmatter struct {
+	AllowedTools *repo.StringList `yaml:"allowed-tools"`
+	Tools        *repo.StringList `yaml:"tools"`
+}
+
+// broadTools are tools that write files or reach the network.
+var broadTools = []string{"Write", "Edit", "MultiEdit", "WebFetch", "WebSearch", "NotebookEdit"}
+
+// Definition checks the tool grants in the frontmatter of a skill, command
+// or agent. Skills and commands list allowed-tools; agents list tools and
+// inherit every tool when they list none.
+func Definition(pkgType repo.PackageType, file string, data []byte) []Finding {
+	if pkgType == repo.TypeHook {
+		return nil
+	}
+	// Only the main definition of a skill carries frontmatter grants
+	if pkgType == repo.TypeSkill && !strings.EqualFold(path.Base(file), "SKILL.md") {
+		return nil
+	}
+
+	var fm definitionFrontmatter
+	if raw, ok := frontmatter(string(data)); ok {
+		_ = yaml.Unmarshal([]byte(raw), &fm)
+	}
+
+	var tools []string
+	switch pkgType {
+	case repo.TypeAgent:
+		if fm.Tools == nil {
+			return []Finding{{Severity: SeverityMedium, Rule: "agent-all-tools", File: file,
+				Message: "agent does not list tools and inherits every tool, including Bash"}}
+		}
+		tools = *fm.Tools
+	default:
+		if fm.AllowedTools == nil {
+			return nil
+		}
+		tools = *fm.AllowedTools
+	}
+
+	var findings []Finding
+	var broad []string
+	for _, tool := range tools {
+		tool = strings.TrimSpace(tool)
+		switch {
+		case tool == "Bash" || tool == "Bash(*)" || tool == "Bash(:*)" || tool == "*":
+			findings = append(findings, Finding{Severity: SeverityHigh, Rule: "unrestricted-bash", File: file,
+				Message: "grants Bash without a command restriction"})
+		case contains(broadTools, tool):
+			broad = append(broad, tool)
+		}
+	}
+	if pkgType == repo.TypeAgent && len(broad) >= 3 {
+		findings = append(findings, Finding{Severity: SeverityMedium, Rule: "agent-broad-tools", File: file,
+			Message: "agent requests broad tool access: " + strings.Join(broad, ", ")})
+	}
+	return findings
+}
+
+// frontmatter returns the YAML frontmatter of a markdown document.
+func frontmatter(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[1:i], "\n"), true
+		}
+	}
+	return "", false
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
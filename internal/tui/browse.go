@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/itda-skills/jindo/internal/pkg/pkgmgr"
 	"github.com/itda-skills/jindo/internal/pkg/repo"
+	"github.com/itda-skills/jindo/internal/pkg/scan"
 )
 
 // Tab represents a tab in the TUI
@@ -55,14 +57,18 @@ func (t Tab) PackageType() repo.PackageType {
 
 // PackageItem represents a package in the list
 type PackageItem struct {
-	Namespace   string
-	Name        string
-	Path        string
-	LocalPath   string // Full local path for preview
-	Type        repo.PackageType
-	IsInstalled bool
-	HasUpdate   bool
-	Selected    bool
+	Namespace     string
+	Name          string
+	Path          string
+	LocalPath     string // Full local path for preview
+	RepoPath      string // Local clone of the repository, for scanning
+	Type          repo.PackageType
+	Requires      []string // Declared dependencies, installed along with the package
+	IsInstalled   bool
+	InstalledName string // Install name when installed; may be an alias
+	HasUpdate     bool
+	Update        *pkgmgr.UpdateInfo // Pending update when HasUpdate, for the diff preview
+	Selected      bool
 }
 
 // installDoneMsg is sent when installation completes
@@ -140,6 +146,13 @@ var (
 	previewContentStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("252"))
 
+	updateStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214"))
+
+	findingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203")).
+			Bold(true)
+
 	listPaneStyle = lipgloss.NewStyle().
 			Padding(0, 1)
 )
@@ -224,9 +237,22 @@ func (m *Model) LoadPackages() error {
 	if err != nil {
 		return err
 	}
-	installedMap := make(map[string]bool)
+	// Map namespace:path to install name, so aliased packages are found
+	installedMap := make(map[string]string)
 	for _, pkg := range installed {
-		installedMap[pkg.Name] = true
+		installedMap[pkg.Namespace+":"+pkg.SourcePath] = pkg.Name
+	}
+
+	// Check installed packages for updates against the refs in the local clones
+	updates := make(map[string]*pkgmgr.UpdateInfo)
+	if len(installed) > 0 {
+		if report, err := m.manager.CheckUpdatesContext(context.Background()); err == nil {
+			for i, u := range report.Updates {
+				if u.HasUpdate {
+					updates[u.Package.Name] = &report.Updates[i]
+				}
+			}
+		}
 	}
 
 	// Initialize items map
@@ -267,7 +293,7 @@ func (m *Model) LoadPackages() error {
 				continue
 			}
 
-			namespacedName := pkgmgr.MakeNamespacedName(r.Namespace, item.Name)
+			installedName := installedMap[r.Namespace+":"+item.Path]
 
 			// Determine the file to preview
 			localPath := filepath.Join(repoLocalPath, item.Path)
@@ -283,12 +309,17 @@ func (m *Model) LoadPackages() error {
 			}
 
 			pkgItem := PackageItem{
-				Namespace:   r.Namespace,
-				Name:        item.Name,
-				Path:        item.Path,
-				LocalPath:   localPath,
-				Type:        item.Type,
-				IsInstalled: installedMap[namespacedName],
+				Namespace:     r.Namespace,
+				Name:          item.Name,
+				Path:          item.Path,
+				LocalPath:     localPath,
+				RepoPath:      repoLocalPath,
+				Type:          item.Type,
+				Requires:      item.Requires,
+				IsInstalled:   installedName != "",
+				InstalledName: installedName,
+				HasUpdate:     updates[installedName] != nil,
+				Update:        updates[installedName],
 			}
 			m.items[tab] = append(m.items[tab], pkgItem)
 		}
@@ -327,7 +358,67 @@ func (m *Model) updatePreview() {
 	}
 
 	item := items[m.cursor]
-	m.preview = loadPreview(item.LocalPath, 50)
+	if item.HasUpdate {
+		m.preview = m.loadUpdatePreview(item.Update, 200)
+	} else {
+		m.preview = loadPreview(item.LocalPath, 50)
+	}
+	if findings, err := scan.Dir(item.Type, item.RepoPath, item.Path); err == nil && len(findings) > 0 {
+		m.preview = formatFindings(findings) + "\n" + m.preview
+	}
+}
+
+// loadUpdatePreview renders the upstream and local diff of a pending update
+func (m *Model) loadUpdatePreview(update *pkgmgr.UpdateInfo, maxLines int) string {
+	diff, err := m.manager.DiffUpdate(*update)
+	if err != nil {
+		return fmt.Sprintf("Unable to load diff:\n%v", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(updateStyle.Render(fmt.Sprintf("Update available: %s -> %s (%d files changed)",
+		shortSHA(update.CurrentSHA), shortSHA(update.LatestSHA), len(update.ChangedFiles))))
+	b.WriteString("\n\n")
+	b.WriteString(diff.Upstream)
+	if diff.Local != "" {
+		b.WriteString("\n")
+		b.WriteString(findingStyle.Render("Local changes (overwritten by the update):"))
+		b.WriteString("\n")
+		b.WriteString(diff.Local)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines], "\n... (truncated, see jd pkg diff "+update.Package.Name+")")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortSHA abbreviates a commit SHA
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// formatFindings renders security scan findings for the preview pane
+func formatFindings(findings []scan.Finding) string {
+	var b strings.Builder
+	b.WriteString(findingStyle.Render(fmt.Sprintf("Security findings (%d):", len(findings))))
+	b.WriteString("\n")
+	for _, f := range findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		line := fmt.Sprintf("  [%s] %s (%s)", f.Severity, f.Message, location)
+		if f.Severity == scan.SeverityHigh {
+			line = findingStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
 }
 
 // listVisibleHeight returns the number of visible lines in the list panel
@@ -416,9 +507,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			for tab := range m.items {
 				for i := range m.items[tab] {
 					item := &m.items[tab][i]
-					namespacedName := pkgmgr.MakeNamespacedName(item.Namespace, item.Name)
-					if namespacedName == msg.name {
+					if item.IsInstalled && item.InstalledName == msg.name {
 						item.IsInstalled = false
+						item.InstalledName = ""
+						item.HasUpdate = false
+						item.Update = nil
 						item.Selected = false
 						break
 					}
@@ -550,8 +643,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Show confirmation prompt
 				m.confirmingUninstall = true
 				m.confirmingItem = item
-				namespacedName := pkgmgr.MakeNamespacedName(item.Namespace, item.Name)
-				m.message = fmt.Sprintf("Uninstall '%s'? [y/N]", namespacedName)
+				m.message = fmt.Sprintf("Uninstall '%s'? [y/N]", item.InstalledName)
 				return m, nil
 			}
 			return m, nil
@@ -571,9 +663,10 @@ func (m *Model) installSelected() tea.Cmd {
 				item := &m.items[tab][i]
 				if item.Selected && !item.IsInstalled {
 					spec := fmt.Sprintf("%s:%s", item.Namespace, item.Path)
-					_, err := m.manager.Install(spec)
+					pkg, err := m.manager.Install(spec, false)
 					if err == nil {
 						item.IsInstalled = true
+						item.InstalledName = pkg.Name
 						item.Selected = false
 						installedCount++
 					} else {
@@ -589,19 +682,18 @@ func (m *Model) installSelected() tea.Cmd {
 // uninstallPackage uninstalls a single package
 func (m *Model) uninstallPackage(item *PackageItem) tea.Cmd {
 	return func() tea.Msg {
-		namespacedName := pkgmgr.MakeNamespacedName(item.Namespace, item.Name)
-		err := m.manager.Uninstall(namespacedName)
+		err := m.manager.Uninstall(item.InstalledName)
 		if err != nil {
 			return uninstallDoneMsg{
 				success: false,
-				name:    namespacedName,
+				name:    item.InstalledName,
 				err:     err,
 			}
 		}
 
 		return uninstallDoneMsg{
 			success: true,
-			name:    namespacedName,
+			name:    item.InstalledName,
 			err:     nil,
 		}
 	}
@@ -665,6 +757,9 @@ func (m Model) renderList(width, height int) string {
 				if item.IsInstalled {
 					line += " " + installedStyle.Render("✓")
 				}
+				if item.HasUpdate {
+					line += " " + updateStyle.Render("↑")
+				}
 
 				lines = append(lines, line)
 				globalIdx++
@@ -725,7 +820,12 @@ func (m Model) renderPreview(width, height int) string {
 	// Path info
 	pathInfo := helpStyle.Render(fmt.Sprintf("Path: %s", item.Path))
 	b.WriteString(pathInfo)
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if len(item.Requires) > 0 {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("Requires: %s", strings.Join(item.Requires, ", "))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// Content
 	content := m.preview
@@ -769,6 +869,11 @@ func (m Model) View() string {
 
 	// Title
 	b.WriteString(titleStyle.Render("jd pkg browse"))
+	if m.manager.IsProject() {
+		b.WriteString(helpStyle.Render("  installing into local (.claude)"))
+	} else {
+		b.WriteString(helpStyle.Render("  installing into global (~/.claude)"))
+	}
 	b.WriteString("\n\n")
 
 	// Tabs